@@ -1,11 +1,18 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"ai-service/internal/model"
+	"ai-service/internal/util/authentication"
+
 	"github.com/joho/godotenv"
 )
 
@@ -28,6 +35,24 @@ type Config struct {
 
 	// Rate limiting configuration
 	RateLimit RateLimitConfig `json:"rate_limit"`
+
+	// Cross-provider failover configuration
+	Failover FailoverConfig `json:"failover"`
+
+	// Provider call retry configuration
+	Retry RetryConfig `json:"retry"`
+
+	// Per-provider circuit breaker configuration
+	CircuitBreaker CircuitBreakerConfig `json:"circuit_breaker"`
+
+	// Token and request quota configuration
+	Quota QuotaConfig `json:"quota"`
+
+	// Context window preflight configuration
+	Preflight PreflightConfig `json:"preflight"`
+
+	// Model discovery from provider list-models endpoints
+	ModelDiscovery ModelDiscoveryConfig `json:"model_discovery"`
 }
 
 // ServerConfig represents server configuration
@@ -38,6 +63,9 @@ type ServerConfig struct {
 	WriteTimeout time.Duration `json:"write_timeout"`
 	IdleTimeout  time.Duration `json:"idle_timeout"`
 	Environment  string        `json:"environment"`
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For
+	// header is believed; with none the client IP is the peer address
+	TrustedProxies []string `json:"trusted_proxies"`
 }
 
 // DatabaseConfig represents database configuration
@@ -56,14 +84,52 @@ type AIProvidersConfig struct {
 	OpenAI    OpenAIConfig    `json:"openai"`
 	Gemini    GeminiConfig    `json:"gemini"`
 	Anthropic AnthropicConfig `json:"anthropic"`
+	Ollama    OllamaConfig    `json:"ollama"`
+	// OpenAICompatible lists named OpenAI-compatible backends, each
+	// registered as its own provider
+	OpenAICompatible []OpenAICompatibleConfig `json:"openai_compatible"`
+	// ModelRegistryFile is a YAML model registry replacing the built-in one
+	// and the models stored in providers.config
+	ModelRegistryFile string `json:"model_registry_file"`
+	// ProviderConfigFile is a YAML file of settings by provider ID that
+	// overrides the environment and is re-read on reload
+	ProviderConfigFile string `json:"provider_config_file"`
+	// ReloadInterval is how often provider configuration is reloaded from
+	// ProviderConfigFile and the providers table; 0 disables polling
+	ReloadInterval time.Duration `json:"reload_interval"`
 }
 
+// OpenAI API types
+const (
+	OpenAIAPITypeOpenAI = "openai"
+	OpenAIAPITypeAzure  = "azure"
+)
+
 // OpenAIConfig represents OpenAI configuration
 type OpenAIConfig struct {
 	APIKey       string `json:"api_key"`
 	BaseURL      string `json:"base_url"`
 	DefaultModel string `json:"default_model"`
 	MaxTokens    int    `json:"max_tokens"`
+	// APIType is OpenAIAPITypeOpenAI or OpenAIAPITypeAzure; Azure uses the
+	// model name as the deployment and needs APIVersion
+	APIType    string `json:"api_type"`
+	APIVersion string `json:"api_version"`
+}
+
+// OpenAICompatibleConfig represents a named backend speaking the OpenAI chat
+// completions API, such as vLLM, LocalAI or Ollama
+type OpenAICompatibleConfig struct {
+	// Name is the provider ID requests use to select the backend
+	Name         string `json:"name"`
+	DisplayName  string `json:"display_name"`
+	BaseURL      string `json:"base_url"`
+	APIKey       string `json:"api_key"`
+	APIType      string `json:"api_type"`
+	APIVersion   string `json:"api_version"`
+	DefaultModel string `json:"default_model"`
+	// Models seeds the model registry for the backend when it has no entries
+	Models []model.ModelInfo `json:"models"`
 }
 
 // GeminiConfig represents Google Gemini configuration
@@ -76,15 +142,41 @@ type GeminiConfig struct {
 // AnthropicConfig represents Anthropic configuration
 type AnthropicConfig struct {
 	APIKey       string `json:"api_key"`
+	BaseURL      string `json:"base_url"`
 	DefaultModel string `json:"default_model"`
 	MaxTokens    int    `json:"max_tokens"`
 }
 
+// OllamaConfig represents local Ollama daemon configuration; the provider is
+// enabled when BaseURL is set
+type OllamaConfig struct {
+	BaseURL      string `json:"base_url"`
+	DefaultModel string `json:"default_model"`
+}
+
 // SecurityConfig represents security configuration
 type SecurityConfig struct {
 	JWTSecret     string        `json:"jwt_secret"`
 	JWTExpiration time.Duration `json:"jwt_expiration"`
 	CORSOrigins   []string      `json:"cors_origins"`
+	// AuthEnabled requires a valid token on protected /api routes
+	AuthEnabled bool         `json:"auth_enabled"`
+	AuthClients []AuthClient `json:"-"`
+	// ProviderMasterKey encrypts provider API keys stored in the providers
+	// table, as base64 of 32 bytes; read from ProviderMasterKeyFile when
+	// empty. Stored keys are ignored when neither is set.
+	ProviderMasterKey     string `json:"-"`
+	ProviderMasterKeyFile string `json:"provider_master_key_file"`
+	// PreviousProviderMasterKeys still decrypt stored keys while they are
+	// rotated to ProviderMasterKey
+	PreviousProviderMasterKeys []string `json:"-"`
+}
+
+// AuthClient is a client allowed to exchange its credentials for a JWT
+type AuthClient struct {
+	ID     string `json:"id"`
+	Secret string `json:"-"`
+	Role   string `json:"role"`
 }
 
 // ObservabilityConfig represents observability configuration
@@ -103,6 +195,62 @@ type RateLimitConfig struct {
 	WindowSize        time.Duration `json:"window_size"`
 }
 
+// FailoverConfig represents cross-provider failover configuration
+type FailoverConfig struct {
+	Enabled bool     `json:"enabled"`
+	Chain   []string `json:"chain"`
+	// ModelEquivalents groups interchangeable models, keyed by provider
+	ModelEquivalents []map[string]string `json:"model_equivalents"`
+}
+
+// RetryConfig represents provider call retry configuration
+type RetryConfig struct {
+	MaxAttempts    int           `json:"max_attempts"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+}
+
+// CircuitBreakerConfig represents per-provider circuit breaker configuration
+type CircuitBreakerConfig struct {
+	FailureThreshold    int           `json:"failure_threshold"`
+	OpenTimeout         time.Duration `json:"open_timeout"`
+	HalfOpenMaxRequests int           `json:"half_open_max_requests"`
+}
+
+// QuotaConfig represents token and request quota configuration
+type QuotaConfig struct {
+	Enabled bool `json:"enabled"`
+	// Default limits for each user and API key; keys may override them
+	Caller model.QuotaLimits `json:"caller"`
+	// Limits shared by all callers of a provider, keyed by provider
+	Providers map[string]model.QuotaLimits `json:"providers"`
+	// SoftLimitPercent is the share of a limit at which warnings start
+	SoftLimitPercent int `json:"soft_limit_percent"`
+}
+
+// Preflight modes for requests that exceed the model's context window
+const (
+	PreflightReject   = "reject"
+	PreflightTruncate = "truncate"
+	PreflightOff      = "off"
+)
+
+// PreflightConfig represents the context window check run before a provider
+// is called
+type PreflightConfig struct {
+	// Mode is one of PreflightReject, PreflightTruncate or PreflightOff
+	Mode string `json:"mode"`
+}
+
+// ModelDiscoveryConfig represents discovery of the models each provider key
+// can access
+type ModelDiscoveryConfig struct {
+	// Enabled refreshes discovered models automatically once they are older
+	// than TTL; a manual refresh works either way
+	Enabled bool          `json:"enabled"`
+	TTL     time.Duration `json:"ttl"`
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists
@@ -110,6 +258,21 @@ func Load() (*Config, error) {
 		// It's okay if .env doesn't exist
 	}
 
+	// JSON settings are parsed first so a malformed value fails the load
+	// instead of silently dropping what it configures
+	openAIBackends, err := getOpenAICompatibleEnv("OPENAI_COMPATIBLE_BACKENDS")
+	if err != nil {
+		return nil, err
+	}
+	modelEquivalents, err := getModelGroupsEnv("FAILOVER_MODEL_MAP", nil)
+	if err != nil {
+		return nil, err
+	}
+	providerQuotas, err := getQuotaLimitsEnv("QUOTA_PROVIDER_LIMITS")
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Server: ServerConfig{
 			Port:         getEnv("PORT", "8080"),
@@ -118,6 +281,8 @@ func Load() (*Config, error) {
 			WriteTimeout: getDurationEnv("WRITE_TIMEOUT", 30*time.Second),
 			IdleTimeout:  getDurationEnv("IDLE_TIMEOUT", 60*time.Second),
 			Environment:  getEnv("ENVIRONMENT", "development"),
+
+			TrustedProxies: getStringSliceEnv("TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
 			Driver:   getEnv("DB_DRIVER", "postgres"),
@@ -134,6 +299,8 @@ func Load() (*Config, error) {
 				BaseURL:      getEnv("OPENAI_BASE_URL", "https://api.openai.com/v1"),
 				DefaultModel: getEnv("OPENAI_DEFAULT_MODEL", "gpt-3.5-turbo"),
 				MaxTokens:    getIntEnv("OPENAI_MAX_TOKENS", 4096),
+				APIType:      strings.ToLower(getEnv("OPENAI_API_TYPE", OpenAIAPITypeOpenAI)),
+				APIVersion:   getEnv("OPENAI_API_VERSION", "2024-06-01"),
 			},
 			Gemini: GeminiConfig{
 				APIKey:       getEnv("GEMINI_API_KEY", ""),
@@ -142,14 +309,29 @@ func Load() (*Config, error) {
 			},
 			Anthropic: AnthropicConfig{
 				APIKey:       getEnv("ANTHROPIC_API_KEY", ""),
+				BaseURL:      getEnv("ANTHROPIC_BASE_URL", "https://api.anthropic.com/v1"),
 				DefaultModel: getEnv("ANTHROPIC_DEFAULT_MODEL", "claude-3-sonnet-20240229"),
 				MaxTokens:    getIntEnv("ANTHROPIC_MAX_TOKENS", 8192),
 			},
+			Ollama: OllamaConfig{
+				BaseURL:      getEnv("OLLAMA_BASE_URL", ""),
+				DefaultModel: getEnv("OLLAMA_DEFAULT_MODEL", "llama3"),
+			},
+			OpenAICompatible:   openAIBackends,
+			ModelRegistryFile:  getEnv("MODEL_REGISTRY_FILE", ""),
+			ProviderConfigFile: getEnv("PROVIDER_CONFIG_FILE", ""),
+			ReloadInterval:     getDurationEnv("PROVIDER_RELOAD_INTERVAL", 30*time.Second),
 		},
 		Security: SecurityConfig{
-			JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
+			JWTSecret:     getEnv("JWT_SECRET", ""),
 			JWTExpiration: getDurationEnv("JWT_EXPIRATION", 24*time.Hour),
 			CORSOrigins:   getStringSliceEnv("CORS_ORIGINS", []string{"*"}),
+			AuthEnabled:   getBoolEnv("AUTH_ENABLED", false),
+			AuthClients:   getAuthClientsEnv("AUTH_CLIENTS"),
+
+			ProviderMasterKey:          getEnv("PROVIDER_MASTER_KEY", ""),
+			ProviderMasterKeyFile:      getEnv("PROVIDER_MASTER_KEY_FILE", ""),
+			PreviousProviderMasterKeys: getStringSliceEnv("PROVIDER_PREVIOUS_MASTER_KEYS", nil),
 		},
 		Observability: ObservabilityConfig{
 			LogLevel:    getEnv("LOG_LEVEL", "info"),
@@ -163,6 +345,39 @@ func Load() (*Config, error) {
 			BurstSize:         getIntEnv("RATE_LIMIT_BURST_SIZE", 10),
 			WindowSize:        getDurationEnv("RATE_LIMIT_WINDOW_SIZE", time.Minute),
 		},
+		Failover: FailoverConfig{
+			Enabled:          getBoolEnv("FAILOVER_ENABLED", false),
+			Chain:            getStringSliceEnv("FAILOVER_CHAIN", []string{"openai", "gemini", "anthropic"}),
+			ModelEquivalents: modelEquivalents,
+		},
+		Retry: RetryConfig{
+			MaxAttempts:    getIntEnv("PROVIDER_RETRY_MAX_ATTEMPTS", 3),
+			InitialBackoff: getDurationEnv("PROVIDER_RETRY_INITIAL_BACKOFF", 500*time.Millisecond),
+			MaxBackoff:     getDurationEnv("PROVIDER_RETRY_MAX_BACKOFF", 10*time.Second),
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureThreshold:    getIntEnv("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 5),
+			OpenTimeout:         getDurationEnv("CIRCUIT_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+			HalfOpenMaxRequests: getIntEnv("CIRCUIT_BREAKER_HALF_OPEN_MAX_REQUESTS", 1),
+		},
+		Quota: QuotaConfig{
+			Enabled: getBoolEnv("QUOTA_ENABLED", false),
+			Caller: model.QuotaLimits{
+				DailyTokens:     getIntEnv("QUOTA_DAILY_TOKENS", 0),
+				MonthlyTokens:   getIntEnv("QUOTA_MONTHLY_TOKENS", 0),
+				DailyRequests:   getIntEnv("QUOTA_DAILY_REQUESTS", 0),
+				MonthlyRequests: getIntEnv("QUOTA_MONTHLY_REQUESTS", 0),
+			},
+			Providers:        providerQuotas,
+			SoftLimitPercent: getIntEnv("QUOTA_SOFT_LIMIT_PERCENT", 80),
+		},
+		Preflight: PreflightConfig{
+			Mode: strings.ToLower(getEnv("PREFLIGHT_MODE", PreflightReject)),
+		},
+		ModelDiscovery: ModelDiscoveryConfig{
+			Enabled: getBoolEnv("MODEL_DISCOVERY_ENABLED", false),
+			TTL:     getDurationEnv("MODEL_DISCOVERY_TTL", time.Hour),
+		},
 	}
 
 	// Validate configuration
@@ -183,6 +398,28 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("database driver is required")
 	}
 
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("TRUSTED_PROXIES entry %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	switch c.Preflight.Mode {
+	case PreflightReject, PreflightTruncate, PreflightOff:
+	default:
+		return fmt.Errorf("PREFLIGHT_MODE must be one of %s, %s or %s", PreflightReject, PreflightTruncate, PreflightOff)
+	}
+
+	if err := c.AIProviders.validateOpenAIBackends(); err != nil {
+		return err
+	}
+
+	if c.Security.AuthEnabled && !authentication.SecretUsable(c.Security.JWTSecret) {
+		return fmt.Errorf("JWT_SECRET must be set to a private value when authentication is enabled")
+	}
+
 	// Validate at least one AI provider is configured (only in production)
 	if c.IsProduction() {
 		if c.AIProviders.OpenAI.APIKey == "" &&
@@ -195,6 +432,51 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateOpenAIBackends checks the OpenAI API type and that every compatible
+// backend has a unique provider ID and a base URL
+func (c *AIProvidersConfig) validateOpenAIBackends() error {
+	if !isOpenAIAPIType(c.OpenAI.APIType) {
+		return fmt.Errorf("OPENAI_API_TYPE must be %s or %s", OpenAIAPITypeOpenAI, OpenAIAPITypeAzure)
+	}
+
+	seen := map[string]bool{
+		string(model.OpenAI):    true,
+		string(model.Gemini):    true,
+		string(model.Anthropic): true,
+		string(model.Ollama):    true,
+	}
+	for _, backend := range c.OpenAICompatible {
+		if !providerIDPattern.MatchString(backend.Name) {
+			return fmt.Errorf("OpenAI-compatible backend name %q must be lowercase letters, digits, '-' or '_'", backend.Name)
+		}
+		if seen[backend.Name] {
+			return fmt.Errorf("OpenAI-compatible backend name %q is already used by another provider", backend.Name)
+		}
+		seen[backend.Name] = true
+
+		if backend.BaseURL == "" {
+			return fmt.Errorf("OpenAI-compatible backend %q needs a base_url", backend.Name)
+		}
+		if !isOpenAIAPIType(backend.APIType) {
+			return fmt.Errorf("OpenAI-compatible backend %q has api_type %q, want %s or %s", backend.Name, backend.APIType, OpenAIAPITypeOpenAI, OpenAIAPITypeAzure)
+		}
+		for _, info := range backend.Models {
+			if info.ID == "" {
+				return fmt.Errorf("OpenAI-compatible backend %q lists a model without an id", backend.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// providerIDPattern matches the IDs providers can be registered under
+var providerIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// isOpenAIAPIType reports whether apiType is a known API type; empty means openai
+func isOpenAIAPIType(apiType string) bool {
+	return apiType == "" || apiType == OpenAIAPITypeOpenAI || apiType == OpenAIAPITypeAzure
+}
+
 // IsDevelopment returns true if the environment is development
 func (c *Config) IsDevelopment() bool {
 	return c.Server.Environment == "development"
@@ -231,11 +513,83 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getStringSliceEnv(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		// Simple comma-separated values
-		// In production, you might want more sophisticated parsing
-		return []string{value}
+		var values []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		return values
 	}
 	return defaultValue
 }
+
+// getAuthClientsEnv parses comma-separated "id:secret:role" entries
+func getAuthClientsEnv(key string) []AuthClient {
+	var clients []AuthClient
+	for _, entry := range getStringSliceEnv(key, nil) {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+		clients = append(clients, AuthClient{ID: parts[0], Secret: parts[1], Role: parts[2]})
+	}
+	return clients
+}
+
+// getModelGroupsEnv parses a JSON array of provider-to-model objects, e.g.
+// [{"openai":"gpt-4","gemini":"gemini-1.5-pro","anthropic":"claude-3-opus"}]
+func getModelGroupsEnv(key string, defaultValue []map[string]string) ([]map[string]string, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	var groups []map[string]string
+	if err := json.Unmarshal([]byte(value), &groups); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON array of provider-to-model objects: %w", key, err)
+	}
+	return groups, nil
+}
+
+// getOpenAICompatibleEnv parses a JSON array of OpenAI-compatible backends, e.g.
+// [{"name":"vllm","base_url":"http://localhost:8000/v1","default_model":"llama-3-8b"}]
+func getOpenAICompatibleEnv(key string) ([]OpenAICompatibleConfig, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var backends []OpenAICompatibleConfig
+	if err := json.Unmarshal([]byte(value), &backends); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON array of backends: %w", key, err)
+	}
+	return backends, nil
+}
+
+// getQuotaLimitsEnv parses a JSON object of per-provider limits, e.g.
+// {"openai":{"monthly_tokens":1000000},"anthropic":{"daily_requests":500}}
+func getQuotaLimitsEnv(key string) (map[string]model.QuotaLimits, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var limits map[string]model.QuotaLimits
+	if err := json.Unmarshal([]byte(value), &limits); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object of provider limits: %w", key, err)
+	}
+	return limits, nil
+}
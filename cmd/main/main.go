@@ -7,9 +7,12 @@ import (
 	"ai-service/internal/outbound"
 	"ai-service/internal/repository"
 	"ai-service/internal/routes"
+	"ai-service/internal/service"
 	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/encryption"
 	"ai-service/internal/util/logger"
 	"ai-service/internal/util/template"
+	"ai-service/scripts/migrations"
 	"context"
 	"fmt"
 	"log"
@@ -18,6 +21,7 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
+	"text/tabwriter"
 	"time"
 )
 
@@ -34,10 +38,14 @@ func main() {
 		env                         = os.Getenv("ENV")
 		port                        = os.Getenv("PORT")
 		sentryUrl                   = os.Getenv("SENTRY_DSN")
-		jwtSecret                   = os.Getenv("JWT_SECRET")
 		signalChan chan (os.Signal) = make(chan os.Signal, 1)
 	)
 
+	// `ai-service migrate ...` manages the schema instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
 	// Set default port if not provided
 	if port == "" {
 		port = "8080"
@@ -45,7 +53,6 @@ func main() {
 
 	// Initialize core components
 	logger.Init(sentryUrl)
-	authentication.Init(jwtSecret)
 	template.Init()
 
 	// Load configuration
@@ -54,17 +61,77 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	authentication.Init(cfg.Security.JWTSecret)
+	if !authentication.SecretUsable(cfg.Security.JWTSecret) {
+		log.Printf("JWT_SECRET is not set: tokens are rejected, so admin routes only accept admin API keys")
+	}
+	authentication.SetExpiration(cfg.Security.JWTExpiration)
+
 	// Initialize database
 	db := database.NewDB()
 
+	// Refuse to serve against a schema this build's migrations do not match
+	migrator, err := database.NewMigrator(db.DB, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Verify(context.Background()); err != nil {
+		log.Fatalf("Refusing to start: %v. Run `ai-service migrate status` for details and `ai-service migrate up` to apply pending migrations", err)
+	}
+
 	// Initialize repositories
 	generationRepo := repository.NewGenerationRepository(db.DB)
+	conversationRepo := repository.NewConversationRepository(db.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(db.DB)
+	pricingRepo := repository.NewPricingRepository(db.DB)
+	providerRepo := repository.NewAIProviderRepository(db.DB)
 
 	// Initialize AI manager
 	aiManager := outbound.NewManager(cfg)
 
+	// Provider API keys stored in the providers table are decrypted with the
+	// master key; without one they are ignored
+	providerKeyService, err := newProviderKeyService(cfg, providerRepo)
+	if err != nil {
+		log.Fatalf("Failed to load provider master key: %v", err)
+	}
+
+	// Apply the provider config file, the providers table and the model
+	// registry, keeping the environment and built-in models if that fails
+	aiManager.SetProviderStore(providerRepo)
+	if providerKeyService != nil {
+		aiManager.SetKeySource(providerKeyService)
+	}
+	if _, err := aiManager.Reload(context.Background()); err != nil {
+		if cfg.AIProviders.ModelRegistryFile != "" || cfg.AIProviders.ProviderConfigFile != "" {
+			log.Fatalf("Failed to load provider configuration: %v", err)
+		}
+		log.Printf("Failed to load provider configuration, using the environment and built-in models: %v", err)
+	}
+
+	// Reload provider configuration on SIGHUP and, when enabled, periodically
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	go func() {
+		for range reloadChan {
+			reload, err := aiManager.Reload(context.Background())
+			if err != nil {
+				log.Printf("Failed to reload provider configuration: %v", err)
+				continue
+			}
+			log.Printf("Provider configuration reloaded on SIGHUP")
+			outbound.LogProviderReload(reload)
+		}
+	}()
+	if cfg.AIProviders.ReloadInterval > 0 {
+		go aiManager.WatchConfig(context.Background(), cfg.AIProviders.ReloadInterval)
+	}
+
+	// List models for providers that have none registered, such as Ollama
+	aiManager.ModelCatalog(context.Background())
+
 	startBootTime := time.Now()
-	router := routes.NewRouters(aiManager, generationRepo)
+	router := routes.NewRouters(cfg, aiManager, generationRepo, conversationRepo, apiKeyRepo, pricingRepo, providerRepo, providerKeyService)
 
 	if env == "prod" {
 		fmt.Println("running production mode")
@@ -115,3 +182,132 @@ func main() {
 		}
 	}
 }
+
+// newProviderKeyService returns the service storing encrypted provider API
+// keys, or nil when no master key is configured
+func newProviderKeyService(cfg *config.Config, providerRepo repository.AIProviderRepository) (service.ProviderKeyService, error) {
+	masterKey, err := encryption.LoadMasterKey(cfg.Security.ProviderMasterKey, cfg.Security.ProviderMasterKeyFile)
+	if err != nil || masterKey == nil {
+		return nil, err
+	}
+
+	previousKeys := make([][]byte, 0, len(cfg.Security.PreviousProviderMasterKeys))
+	for _, key := range cfg.Security.PreviousProviderMasterKeys {
+		decoded, err := encryption.DecodeKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous master key: %w", err)
+		}
+		previousKeys = append(previousKeys, decoded)
+	}
+
+	cipher, err := encryption.NewCipher(masterKey, previousKeys...)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewProviderKeyService(providerRepo, cipher), nil
+}
+
+const migrateUsage = `Usage: ai-service migrate <command>
+
+Commands:
+  up                 apply every pending migration
+  down               revert the latest applied migration
+  status             list migrations and whether they are applied
+  to <version>       apply or revert migrations until version is the latest applied
+  baseline <version> record migrations up to version as applied without running
+                     them, for databases set up before schema_migrations existed
+`
+
+// runMigrate runs the migrate subcommand and returns its exit code
+func runMigrate(args []string) int {
+	var version int
+	switch {
+	case len(args) == 1 && (args[0] == "up" || args[0] == "down" || args[0] == "status"):
+	case len(args) == 2 && (args[0] == "to" || args[0] == "baseline"):
+		parsed, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid version %q\n", args[1])
+			return 2
+		}
+		version = parsed
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	// Load .env like the server does, for the DB_* settings
+	if _, err := config.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	db := database.NewDB()
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db.DB, migrations.FS)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		ran, err := migrator.Up(ctx)
+		return report("Applied", ran, err)
+	case "down":
+		var ran []database.Migration
+		reverted, err := migrator.Down(ctx)
+		if reverted != nil {
+			ran = append(ran, *reverted)
+		}
+		return report("Reverted", ran, err)
+	case "to":
+		ran, err := migrator.To(ctx, version)
+		return report("Migrated", ran, err)
+	case "baseline":
+		recorded, err := migrator.Baseline(ctx, version)
+		return report("Recorded", recorded, err)
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return report("", nil, err)
+		}
+		printStatus(statuses)
+		return 0
+	}
+}
+
+// report prints the migrations that ran and err, returning the exit code
+func report(action string, ran []database.Migration, err error) int {
+	for _, migration := range ran {
+		fmt.Printf("%s %03d_%s\n", action, migration.Version, migration.Name)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(ran) == 0 {
+		fmt.Println("Nothing to migrate")
+	}
+	return 0
+}
+
+func printStatus(statuses []database.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		switch {
+		case status.Unknown:
+			state = "unknown to this build"
+		case status.Changed:
+			state = "changed since applied"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
@@ -0,0 +1,436 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrSchemaMismatch is returned by Verify when the database schema does not
+// match the migrations built into the binary
+var ErrSchemaMismatch = errors.New("database schema does not match the migrations")
+
+// migrationLockID is the advisory lock held while migrating, so instances
+// migrating together do not apply the same migration twice
+const migrationLockID = 7216403712
+
+const createSchemaMigrations = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	)
+`
+
+// migrationFile matches NNN_name.sql and NNN_name.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+?)(\.down)?\.sql$`)
+
+// Migration is one schema version, applied by its up script and reverted by
+// its down script
+type Migration struct {
+	Version int
+	Name    string
+	// Checksum is the SHA-256 of the up script, recorded when it is applied
+	Checksum string
+
+	up   string
+	down string
+}
+
+// Reversible reports whether the migration has a down script
+func (m Migration) Reversible() bool {
+	return m.down != ""
+}
+
+// MigrationStatus reports whether a migration is applied
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	// Changed is set when the up script differs from the one applied
+	Changed bool
+	// Unknown is set for applied versions this build has no migration for
+	Unknown bool
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	version   int
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator applies the migrations of a directory, recording each applied
+// version in schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator reads the migrations at the root of fsys, ordered by version
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	downs := map[int]string{}
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		if match[3] != "" {
+			downs[version] = string(script)
+			continue
+		}
+		if _, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d", version)
+		}
+		sum := sha256.Sum256(script)
+		byVersion[version] = &Migration{
+			Version:  version,
+			Name:     match[2],
+			Checksum: hex.EncodeToString(sum[:]),
+			up:       string(script),
+		}
+	}
+
+	m := &Migrator{db: db}
+	for version, down := range downs {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("down migration %d has no up migration", version)
+		}
+		migration.down = down
+	}
+	for _, migration := range byVersion {
+		m.migrations = append(m.migrations, *migration)
+	}
+	sort.Slice(m.migrations, func(i, j int) bool { return m.migrations[i].Version < m.migrations[j].Version })
+	return m, nil
+}
+
+// Migrations returns every migration ordered by version
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Latest returns the highest migration version, or 0 when there are none
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status reports every migration and whether it is applied, followed by any
+// applied versions this build does not know
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = row.appliedAt
+			status.Changed = row.checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range sortedApplied(applied) {
+		statuses = append(statuses, MigrationStatus{
+			Migration: Migration{Version: row.version, Name: row.name, Checksum: row.checksum},
+			Applied:   true,
+			AppliedAt: row.appliedAt,
+			Unknown:   true,
+		})
+	}
+	return statuses, nil
+}
+
+// Verify returns ErrSchemaMismatch unless every migration is applied
+// unchanged and no unknown version is
+func (m *Migrator) Verify(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			return fmt.Errorf("%w: migration %d is applied but unknown to this build", ErrSchemaMismatch, status.Version)
+		case status.Changed:
+			return fmt.Errorf("%w: migration %03d_%s changed after it was applied", ErrSchemaMismatch, status.Version, status.Name)
+		case !status.Applied:
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d migrations pending", ErrSchemaMismatch, pending)
+	}
+	return nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the most recently applied migration, returning nil when none
+// is applied
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.checkApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok {
+				reverted = &migration
+				return m.revert(ctx, conn, migration)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reverted, nil
+}
+
+// To applies or reverts migrations until version is the latest one applied,
+// returning them in the order they ran
+func (m *Migrator) To(ctx context.Context, version int) ([]Migration, error) {
+	if version < 0 || version > m.Latest() {
+		return nil, fmt.Errorf("unknown migration version %d, latest is %d", version, m.Latest())
+	}
+
+	var ran []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.checkApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+			if err := m.run(ctx, conn, migration, migration.up, true); err != nil {
+				return err
+			}
+			ran = append(ran, migration)
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			ran = append(ran, migration)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+// Baseline records migrations up to version as applied without running them,
+// for databases whose schema was created before schema_migrations existed
+func (m *Migrator) Baseline(ctx context.Context, version int) ([]Migration, error) {
+	if version < 1 || version > m.Latest() {
+		return nil, fmt.Errorf("unknown migration version %d, latest is %d", version, m.Latest())
+	}
+
+	var recorded []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return errors.New("migrations are already recorded; baseline only applies to an unmanaged database")
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+				migration.Version, migration.Name, migration.Checksum); err != nil {
+				return fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+			}
+			recorded = append(recorded, migration)
+		}
+		return nil
+	})
+	return recorded, err
+}
+
+// locked runs fn on one connection holding the migration lock, with
+// schema_migrations created
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if _, err := conn.ExecContext(ctx, createSchemaMigrations); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+// checkApplied returns the applied migrations, refusing to migrate a
+// database whose history does not match this build
+func (m *Migrator) checkApplied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(applied) == 0 {
+		var unmanaged bool
+		if err := conn.QueryRowContext(ctx, `SELECT to_regclass('generations') IS NOT NULL`).Scan(&unmanaged); err != nil {
+			return nil, err
+		}
+		if unmanaged {
+			return nil, errors.New("database was set up without schema_migrations; record the migrations it has with `migrate baseline <version>`")
+		}
+	}
+
+	known := map[int]Migration{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+	for _, row := range sortedApplied(applied) {
+		migration, ok := known[row.version]
+		if !ok {
+			return nil, fmt.Errorf("%w: migration %d is applied but unknown to this build", ErrSchemaMismatch, row.version)
+		}
+		if row.checksum != migration.Checksum {
+			return nil, fmt.Errorf("%w: migration %03d_%s changed after it was applied", ErrSchemaMismatch, row.version, migration.Name)
+		}
+	}
+	return applied, nil
+}
+
+// revert runs the down script of an applied migration
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.down == "" {
+		return fmt.Errorf("migration %03d_%s has no down migration", migration.Version, migration.Name)
+	}
+	return m.run(ctx, conn, migration, migration.down, false)
+}
+
+// run executes one up or down script and records it in the same transaction
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, script string, up bool) error {
+	direction := "apply"
+	if !up {
+		direction = "revert"
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("failed to %s migration %03d_%s: %w", direction, migration.Version, migration.Name, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+			migration.Version, migration.Name, migration.Checksum)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Commit()
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Conn
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// applied reads schema_migrations, which is empty until the first migration
+func (m *Migrator) applied(ctx context.Context, db queryer) (map[int]appliedMigration, error) {
+	applied := map[int]appliedMigration{}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row appliedMigration
+		if err := rows.Scan(&row.version, &row.name, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[row.version] = row
+	}
+	return applied, rows.Err()
+}
+
+func sortedApplied(applied map[int]appliedMigration) []appliedMigration {
+	rows := make([]appliedMigration, 0, len(applied))
+	for _, row := range applied {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].version < rows[j].version })
+	return rows
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"ai-service/internal/model"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"ai-service/internal/util/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyClaims is the gin context key holding the authenticated *JWTClaim
+const ContextKeyClaims = "claims"
+
+// apiKeyUserPrefix marks claims issued for an API key rather than a user
+const apiKeyUserPrefix = "api_key:"
+
+// APIKeyStore looks up and meters client API keys
+type APIKeyStore interface {
+	GetActiveByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	RecordUsage(ctx context.Context, id string) error
+}
+
+// Authenticate accepts either an X-API-Key header, checked against keys, or a
+// Bearer token, and stores the caller's claims in both the gin context and the
+// request context. API keys may also be sent as the Bearer token, as OpenAI
+// clients do. API keys are always checked when presented; when enabled is
+// false requests without one pass through unauthenticated.
+func Authenticate(enabled bool, keys APIKeyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey := requestAPIKey(c); apiKey != "" && keys != nil {
+			authenticateAPIKey(c, keys, apiKey)
+			return
+		}
+
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		claims, err := authentication.ExtractClaim(c.GetHeader("Authorization"))
+		if err != nil {
+			code := exceptioncode.CodeTokenInvalid
+			if errors.Is(err, exceptioncode.ErrTokenExpired) {
+				code = exceptioncode.CodeTokenExpired
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"code":    code,
+				"details": "A valid Bearer token or X-API-Key is required",
+			})
+			return
+		}
+
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// requestAPIKey returns the API key from the X-API-Key header, or from the
+// Bearer token when it holds an API key instead of a JWT
+func requestAPIKey(c *gin.Context) string {
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	if token, err := authentication.ExtractToken(c.GetHeader("Authorization")); err == nil && authentication.IsAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
+// authenticateAPIKey validates an API key, counts its use and continues the
+// chain as the key's pseudo-user
+func authenticateAPIKey(c *gin.Context, keys APIKeyStore, apiKey string) {
+	ctx := c.Request.Context()
+
+	key, err := keys.GetActiveByHash(ctx, authentication.HashAPIKey(apiKey))
+	if errors.Is(err, exceptioncode.ErrEmptyResult) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"code":    exceptioncode.CodeInvalidCredential,
+			"details": "The API key is invalid or has been revoked",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf(ctx, "failed to look up API key: %v", err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Failed to validate API key",
+			"code":    exceptioncode.CodeServiceUnavailable,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := keys.RecordUsage(ctx, key.ID); err != nil {
+		// Log the error but don't fail the request
+		logger.Errorf(ctx, "failed to record API key usage: %v", err)
+	}
+
+	setClaims(c, &authentication.JWTClaim{
+		UserID:   apiKeyUserPrefix + key.ID,
+		Username: key.Name,
+		Role:     key.Role,
+	})
+	c.Request = c.Request.WithContext(authentication.WithAPIKey(c.Request.Context(), key))
+	c.Next()
+}
+
+// setClaims stores claims in the gin context and the request context
+func setClaims(c *gin.Context, claims *authentication.JWTClaim) {
+	c.Set(ContextKeyClaims, claims)
+	c.Request = c.Request.WithContext(authentication.WithClaims(c.Request.Context(), claims))
+}
+
+// RequireRole rejects authenticated requests whose role is not one of roles.
+// It must run after Authenticate and passes unauthenticated requests through
+// when enabled is false.
+func RequireRole(enabled bool, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := authentication.ClaimsFromContext(c.Request.Context())
+		if !ok && !enabled {
+			c.Next()
+			return
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"code":    exceptioncode.CodeUnauthorized,
+				"details": "Authentication is required",
+			})
+			return
+		}
+
+		for _, role := range roles {
+			if claims.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":   "Forbidden",
+			"code":    exceptioncode.CodeForbidden,
+			"details": "Your role does not have access to this resource",
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"ai-service/internal/util/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit describes a token bucket: Burst tokens at most, refilled at
+// Requests tokens every Window
+type RateLimit struct {
+	Requests int
+	Burst    int
+	Window   time.Duration
+}
+
+// refillRate returns the number of tokens added per second
+func (l RateLimit) refillRate() float64 {
+	return float64(l.Requests) / l.Window.Seconds()
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the next token is available
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// RateLimitStore keeps token buckets. The in-memory store suits a single
+// instance; deployments running several instances should plug in a shared
+// store, such as one backed by Redis, so limits apply across all of them.
+type RateLimitStore interface {
+	// Take removes one token from the bucket for key if one is available
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+	// Peek reports whether the bucket for key has a token without taking it
+	Peek(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// memoryBucket is a token bucket held by MemoryRateLimitStore
+type memoryBucket struct {
+	tokens   float64
+	updated  time.Time
+	lastSeen time.Time
+}
+
+// MemoryRateLimitStore keeps token buckets in process memory
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*memoryBucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	return s.use(key, limit, true), nil
+}
+
+func (s *MemoryRateLimitStore) Peek(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	return s.use(key, limit, false), nil
+}
+
+// use refills the bucket for key and, when take is set, removes a token
+func (s *MemoryRateLimitStore) use(key string, limit RateLimit, take bool) RateLimitResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	rate := limit.refillRate()
+	burst := float64(limit.Burst)
+
+	bucket, exists := s.buckets[key]
+	if !exists {
+		if !take {
+			return RateLimitResult{Allowed: true, Remaining: limit.Burst}
+		}
+		bucket = &memoryBucket{tokens: burst, updated: now}
+		s.buckets[key] = bucket
+	}
+
+	// Refill for the time elapsed since the last request
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+	bucket.lastSeen = now
+
+	result := RateLimitResult{}
+	if bucket.tokens >= 1 {
+		if take {
+			bucket.tokens--
+		}
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / rate)
+	}
+	result.Remaining = int(bucket.tokens)
+	result.ResetAfter = secondsToDuration((burst - bucket.tokens) / rate)
+
+	s.sweep(now, limit.Window)
+
+	return result
+}
+
+// sweep drops buckets that have been idle long enough to be full again
+func (s *MemoryRateLimitStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(s.lastSweep) < window {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.lastSeen) > window {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// RateLimiter limits requests per API key, user or client IP using token
+// buckets held in a RateLimitStore. Mount it after Authenticate so only
+// validated callers get their own bucket; anonymous callers share their IP's bucket
+func RateLimiter(cfg config.RateLimitConfig, store RateLimitStore) gin.HandlerFunc {
+	limit := rateLimitFromConfig(cfg)
+
+	// A non-positive limit disables rate limiting
+	if limit.Requests <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	policy := fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, int(limit.Window.Seconds()), limit.Burst)
+
+	return func(c *gin.Context) {
+		result, err := store.Take(c.Request.Context(), rateLimitKey(c), limit)
+		if err != nil {
+			// Fail open so a store outage does not take the API down
+			logger.Errorf(c.Request.Context(), "rate limit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Too many requests",
+				"code":    exceptioncode.CodeRequestTooFast,
+				"details": fmt.Sprintf("Rate limit exceeded, retry in %d seconds", retryAfter),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// FailedAuthLimiter throttles clients whose credentials keep being rejected,
+// which RateLimiter never sees as it runs after Authenticate. Each 401 takes a
+// token from the client IP's failure bucket; once it is empty the IP's
+// requests are refused before their credentials are checked.
+func FailedAuthLimiter(cfg config.RateLimitConfig, store RateLimitStore) gin.HandlerFunc {
+	limit := rateLimitFromConfig(cfg)
+	if limit.Requests <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		key := "auth-failures:" + c.ClientIP()
+		result, err := store.Peek(c.Request.Context(), key, limit)
+		if err != nil {
+			logger.Errorf(c.Request.Context(), "rate limit store error: %v", err)
+		} else if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Too many failed authentication attempts",
+				"code":    exceptioncode.CodeRequestTooFast,
+				"details": fmt.Sprintf("Too many rejected credentials, retry in %d seconds", retryAfter),
+			})
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusUnauthorized {
+			if _, err := store.Take(c.Request.Context(), key, limit); err != nil {
+				logger.Errorf(c.Request.Context(), "rate limit store error: %v", err)
+			}
+		}
+	}
+}
+
+// rateLimitFromConfig builds the bucket size and refill rate from config,
+// defaulting the window to a minute and the burst to the request rate
+func rateLimitFromConfig(cfg config.RateLimitConfig) RateLimit {
+	limit := RateLimit{
+		Requests: cfg.RequestsPerMinute,
+		Burst:    cfg.BurstSize,
+		Window:   cfg.WindowSize,
+	}
+	if limit.Window <= 0 {
+		limit.Window = time.Minute
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Requests
+	}
+	return limit
+}
+
+// rateLimitKey identifies the caller by the API key or user Authenticate
+// validated, then client IP. Credentials are never read from the request
+// here, so unvalidated keys cannot claim a fresh bucket.
+func rateLimitKey(c *gin.Context) string {
+	if claims, ok := authentication.ClaimsFromContext(c.Request.Context()); ok {
+		if keyID, isKey := strings.CutPrefix(claims.UserID, apiKeyUserPrefix); isKey {
+			return "key:" + keyID
+		}
+		if claims.UserID != "" {
+			return "user:" + claims.UserID
+		}
+		if claims.Subject != "" {
+			return "user:" + claims.Subject
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
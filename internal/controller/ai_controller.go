@@ -4,18 +4,28 @@ import (
 	"ai-service/internal/model"
 	"ai-service/internal/outbound"
 	"ai-service/internal/repository"
+	"ai-service/internal/service"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AIController interface {
 	GenerateContent(c *gin.Context)
+	GenerateContentStream(c *gin.Context)
 	CompareProviders(c *gin.Context)
+	GetComparison(c *gin.Context)
 	GetProviders(c *gin.Context)
+	RefreshModels(c *gin.Context)
+	ReloadProviders(c *gin.Context)
 	GetHistory(c *gin.Context)
 	GetStats(c *gin.Context)
 }
@@ -23,16 +33,267 @@ type AIController interface {
 type aiController struct {
 	aiManager      *outbound.Manager
 	generationRepo repository.GenerationRepository
+	quotaService   service.QuotaService
+	pricingService service.PricingService
 }
 
-func NewAIController(aiManager *outbound.Manager, generationRepo repository.GenerationRepository) AIController {
+func NewAIController(aiManager *outbound.Manager, generationRepo repository.GenerationRepository, quotaService service.QuotaService, pricingService service.PricingService) AIController {
 	return &aiController{
 		aiManager:      aiManager,
 		generationRepo: generationRepo,
+		quotaService:   quotaService,
+		pricingService: pricingService,
 	}
 }
 
 func (c *aiController) GenerateContent(ctx *gin.Context) {
+	genReq, stream, ok := c.bindGenerationRequest(ctx)
+	if !ok {
+		return
+	}
+	if !enforceQuota(ctx, c.quotaService, []model.AIProvider{genReq.Provider}, requestTokens(c.aiManager, genReq)) {
+		return
+	}
+
+	if stream {
+		c.streamContent(ctx, genReq)
+		return
+	}
+
+	// Generate content using AI manager
+	startTime := time.Now()
+	response, err := c.aiManager.Generate(ctx, genReq)
+	if err != nil {
+		// Record the failure, including every provider the failover tried
+		generationRecord := &model.GenerationHistory{
+			Provider:          string(genReq.Provider),
+			Model:             genReq.Model,
+			Prompt:            genReq.Prompt,
+			UserID:            currentUserID(ctx),
+			Duration:          int64(time.Since(startTime).Milliseconds()),
+			Status:            "error",
+			ErrorMessage:      err.Error(),
+			RequestedProvider: string(genReq.Provider),
+			FailedAttempts:    failedAttempts(err),
+		}
+		if saveErr := c.generationRepo.Create(ctx, generationRecord); saveErr != nil {
+			// Log the error but don't fail the request
+			log.Printf("Failed to save generation record: %v", saveErr)
+		}
+
+		ctx.JSON(generationErrorStatus(err), gin.H{
+			"error":           "Failed to generate content",
+			"details":         err.Error(),
+			"failed_attempts": failedAttempts(err),
+		})
+		return
+	}
+	duration := time.Since(startTime)
+
+	// Save generation record to database
+	generationRecord := &model.GenerationHistory{
+		Provider:          string(response.Provider),
+		Model:             response.Model,
+		Prompt:            genReq.Prompt,
+		UserID:            currentUserID(ctx),
+		Response:          response.Content,
+		TokensUsed:        response.TokensUsed,
+		Duration:          int64(duration.Milliseconds()),
+		Status:            "success",
+		ErrorMessage:      "",
+		RequestedProvider: string(response.RequestedProvider),
+		FailedAttempts:    response.FailedAttempts,
+		TokenUsage:        response.TokenUsage,
+	}
+
+	applyCost(ctx, c.pricingService, generationRecord)
+	err = c.generationRepo.Create(ctx, generationRecord)
+	if err != nil {
+		// Log the error but don't fail the request
+		log.Printf("Failed to save generation record: %v", err)
+	}
+
+	// Return the response
+	ctx.JSON(200, gin.H{
+		"content":            response.Content,
+		"provider":           string(response.Provider),
+		"model":              response.Model,
+		"tokens_used":        response.TokensUsed,
+		"prompt_tokens":      response.PromptTokens,
+		"completion_tokens":  response.CompletionTokens,
+		"total_tokens":       response.TotalTokens,
+		"tokens_estimated":   response.Estimated,
+		"truncated":          response.Truncated,
+		"duration":           duration.String(),
+		"status":             "success",
+		"retries":            response.Retries,
+		"requested_provider": string(response.RequestedProvider),
+		"failed_attempts":    response.FailedAttempts,
+		"cost_usd":           generationRecord.CostUSD,
+	})
+}
+
+// GenerateContentStream streams generated content as server-sent events
+func (c *aiController) GenerateContentStream(ctx *gin.Context) {
+	genReq, _, ok := c.bindGenerationRequest(ctx)
+	if !ok {
+		return
+	}
+	if !enforceQuota(ctx, c.quotaService, []model.AIProvider{genReq.Provider}, requestTokens(c.aiManager, genReq)) {
+		return
+	}
+
+	c.streamContent(ctx, genReq)
+}
+
+// streamContent sends "chunk" events as tokens arrive, then a final "done" or
+// "error" event, and saves the assembled response to the history table.
+// Failures before the first chunk are returned as a regular JSON error.
+func (c *aiController) streamContent(ctx *gin.Context, genReq *model.GenerationRequest) {
+	started := false
+	startStream := func() {
+		if started {
+			return
+		}
+		started = true
+		ctx.Header("Content-Type", "text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		ctx.Header("X-Accel-Buffering", "no")
+		ctx.Status(200)
+	}
+
+	var content strings.Builder
+	startTime := time.Now()
+	response, err := c.aiManager.GenerateStream(ctx.Request.Context(), genReq, func(chunk string) error {
+		startStream()
+		content.WriteString(chunk)
+		ctx.SSEvent("chunk", gin.H{"content": chunk})
+		ctx.Writer.Flush()
+		return ctx.Request.Context().Err()
+	})
+	duration := time.Since(startTime)
+
+	// Save generation record even if the client has gone away
+	generationRecord := &model.GenerationHistory{
+		Provider: string(genReq.Provider),
+		Model:    genReq.Model,
+		Prompt:   genReq.Prompt,
+		UserID:   currentUserID(ctx),
+		Response: content.String(),
+		Duration: int64(duration.Milliseconds()),
+		Status:   "success",
+	}
+	if err != nil {
+		generationRecord.Status = "error"
+		generationRecord.ErrorMessage = err.Error()
+		generationRecord.RequestedProvider = string(genReq.Provider)
+		generationRecord.FailedAttempts = failedAttempts(err)
+	} else {
+		generationRecord.Provider = string(response.Provider)
+		generationRecord.Model = response.Model
+		generationRecord.Response = response.Content
+		generationRecord.TokensUsed = response.TokensUsed
+		generationRecord.TokenUsage = response.TokenUsage
+		generationRecord.RequestedProvider = string(response.RequestedProvider)
+		generationRecord.FailedAttempts = response.FailedAttempts
+	}
+
+	saveCtx := context.WithoutCancel(ctx.Request.Context())
+	applyCost(saveCtx, c.pricingService, generationRecord)
+	if saveErr := c.generationRepo.Create(saveCtx, generationRecord); saveErr != nil {
+		// Log the error but don't fail the request
+		log.Printf("Failed to save generation record: %v", saveErr)
+	}
+
+	if err != nil {
+		if !started {
+			ctx.JSON(generationErrorStatus(err), gin.H{
+				"error":           "Failed to generate content",
+				"details":         err.Error(),
+				"failed_attempts": failedAttempts(err),
+			})
+			return
+		}
+		ctx.SSEvent("error", gin.H{
+			"error":   "Failed to generate content",
+			"details": err.Error(),
+		})
+		ctx.Writer.Flush()
+		return
+	}
+
+	startStream()
+	ctx.SSEvent("done", gin.H{
+		"id":                 generationRecord.ID,
+		"provider":           string(response.Provider),
+		"model":              response.Model,
+		"tokens_used":        response.TokensUsed,
+		"prompt_tokens":      response.PromptTokens,
+		"completion_tokens":  response.CompletionTokens,
+		"total_tokens":       response.TotalTokens,
+		"tokens_estimated":   response.Estimated,
+		"truncated":          response.Truncated,
+		"duration":           duration.String(),
+		"status":             "success",
+		"retries":            response.Retries,
+		"requested_provider": string(response.RequestedProvider),
+		"failed_attempts":    response.FailedAttempts,
+		"cost_usd":           generationRecord.CostUSD,
+	})
+	ctx.Writer.Flush()
+}
+
+// currentUserID returns the authenticated caller's user ID, or "" when
+// authentication is disabled
+func currentUserID(ctx *gin.Context) string {
+	if claims, ok := authentication.ClaimsFromContext(ctx.Request.Context()); ok {
+		return claims.UserID
+	}
+	return ""
+}
+
+// checkAPIKeyScope rejects callers authenticated with an API key that is not
+// allowed to use the provider and model, writing a 403 response and returning
+// false
+func checkAPIKeyScope(ctx *gin.Context, provider model.AIProvider, modelName string) bool {
+	key, ok := authentication.APIKeyFromContext(ctx.Request.Context())
+	if !ok || key.Allows(string(provider), modelName) {
+		return true
+	}
+
+	ctx.JSON(403, gin.H{
+		"error":    "API key not allowed",
+		"code":     exceptioncode.CodeForbidden,
+		"details":  fmt.Sprintf("This API key may not use model '%s' on provider '%s'", modelName, provider),
+		"provider": provider,
+		"model":    modelName,
+	})
+	return false
+}
+
+// failedAttempts returns the providers tried before a failover error, if any
+func failedAttempts(err error) []model.ProviderAttempt {
+	var failoverErr *outbound.FailoverError
+	if errors.As(err, &failoverErr) {
+		return failoverErr.Attempts
+	}
+	return nil
+}
+
+// generationErrorStatus maps a generation error to an HTTP status: requests
+// that can never fit the model are the caller's fault, anything else is ours
+func generationErrorStatus(err error) int {
+	var windowErr *outbound.ContextWindowError
+	if errors.As(err, &windowErr) {
+		return 400
+	}
+	return 500
+}
+
+// bindGenerationRequest parses and validates a generation request body, writing
+// a 400 response and returning ok=false when it is invalid
+func (c *aiController) bindGenerationRequest(ctx *gin.Context) (genReq *model.GenerationRequest, stream bool, ok bool) {
 	var request struct {
 		Provider    string  `json:"provider" binding:"required"`
 		Model       string  `json:"model" binding:"required"`
@@ -40,6 +301,7 @@ func (c *aiController) GenerateContent(ctx *gin.Context) {
 		SystemMsg   string  `json:"systemMsg"`
 		Temperature float64 `json:"temperature"`
 		MaxTokens   int     `json:"maxTokens"`
+		Stream      bool    `json:"stream"`
 	}
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
@@ -56,131 +318,288 @@ func (c *aiController) GenerateContent(ctx *gin.Context) {
 			errorMsg = "Invalid request data"
 		}
 		ctx.JSON(400, gin.H{"error": errorMsg, "details": err.Error()})
-		return
+		return nil, false, false
+	}
+
+	provider, ok := validateProviderModel(ctx, c.aiManager.Registry(), request.Provider, request.Model, request.MaxTokens)
+	if !ok {
+		return nil, false, false
+	}
+
+	// Create generation request
+	genReq = &model.GenerationRequest{
+		Provider:    provider,
+		Model:       request.Model,
+		Prompt:      request.Prompt,
+		SystemMsg:   request.SystemMsg,
+		Temperature: float32(request.Temperature),
+		MaxTokens:   request.MaxTokens,
 	}
 
-	// Validate provider
-	var provider model.AIProvider
-	switch request.Provider {
-	case "openai":
-		provider = model.OpenAI
-	case "gemini":
-		provider = model.Gemini
-	case "anthropic":
-		provider = model.Anthropic
-	default:
+	return genReq, request.Stream, true
+}
+
+// checkProviderRegistered checks that a provider is registered, configured or
+// not, writing a 400 response and returning false otherwise
+func checkProviderRegistered(ctx *gin.Context, aiManager *outbound.Manager, providerName string) bool {
+	if _, ok := aiManager.ProviderDefinition(model.AIProvider(providerName)); ok {
+		return true
+	}
+
+	ctx.JSON(400, gin.H{
+		"error":    "Unsupported provider",
+		"details":  fmt.Sprintf("Provider '%s' is not supported. Supported providers: %s", providerName, registeredProviderIDs(aiManager)),
+		"provider": providerName,
+	})
+	return false
+}
+
+// registeredProviderIDs returns the registered provider IDs as a comma
+// separated list
+func registeredProviderIDs(aiManager *outbound.Manager) string {
+	definitions := aiManager.ProviderDefinitions()
+	ids := make([]string, len(definitions))
+	for i, definition := range definitions {
+		ids[i] = string(definition.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// validateProviderModel checks that the provider is supported and the registry
+// accepts the model and maxTokens for it, writing a 400 response and returning
+// ok=false otherwise
+func validateProviderModel(ctx *gin.Context, registry *outbound.Registry, providerName, modelName string, maxTokens int) (model.AIProvider, bool) {
+	// Validate provider; the registry knows every provider with models,
+	// including OpenAI-compatible backends
+	provider := model.AIProvider(providerName)
+	if len(registry.Models(provider)) == 0 {
 		ctx.JSON(400, gin.H{
 			"error":    "Unsupported provider",
-			"details":  fmt.Sprintf("Provider '%s' is not supported. Supported providers: openai, gemini, anthropic", request.Provider),
-			"provider": request.Provider,
+			"details":  fmt.Sprintf("Provider '%s' is not supported. Supported providers: %v", providerName, registry.Providers()),
+			"provider": providerName,
 		})
-		return
+		return "", false
 	}
 
 	// Validate model for the selected provider
-	validModels := map[string][]string{
-		"openai":    {"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo"},
-		"gemini":    {"gemini-1.5-flash", "gemini-1.5-pro", "gemini-2.0-flash"},
-		"anthropic": {"claude-3-sonnet", "claude-3-opus", "claude-3-haiku"},
-	}
-
-	if models, exists := validModels[request.Provider]; exists {
-		modelValid := false
-		for _, validModel := range models {
-			if validModel == request.Model {
-				modelValid = true
-				break
-			}
+	if err := registry.Validate(provider, modelName, maxTokens, time.Now()); err != nil {
+		ctx.JSON(400, gin.H{
+			"error":    "Invalid model for selected provider",
+			"details":  err.Error(),
+			"provider": providerName,
+			"model":    modelName,
+		})
+		return "", false
+	}
+
+	if !checkAPIKeyScope(ctx, provider, modelName) {
+		return "", false
+	}
+
+	return provider, true
+}
+
+func (c *aiController) CompareProviders(ctx *gin.Context) {
+	var request model.ComparisonRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		var errorMsg string
+		errStr := err.Error()
+		if strings.Contains(errStr, "Key: 'ComparisonRequest.Prompt'") {
+			errorMsg = "Prompt is required"
+		} else if strings.Contains(errStr, "Key: 'ComparisonRequest.Providers'") {
+			errorMsg = "At least one provider is required"
+		} else {
+			errorMsg = "Invalid request data"
+		}
+		ctx.JSON(400, gin.H{"error": errorMsg, "details": err.Error()})
+		return
+	}
+
+	// Validate providers
+	registry := c.aiManager.Registry()
+	seen := make(map[model.AIProvider]bool)
+	for _, provider := range request.Providers {
+		if len(registry.Models(provider)) == 0 {
+			ctx.JSON(400, gin.H{
+				"error":    "Unsupported provider",
+				"details":  fmt.Sprintf("Provider '%s' is not supported. Supported providers: %v", provider, registry.Providers()),
+				"provider": provider,
+			})
+			return
 		}
-		if !modelValid {
+		if seen[provider] {
+			ctx.JSON(400, gin.H{
+				"error":    "Duplicate provider",
+				"details":  fmt.Sprintf("Provider '%s' is listed more than once", provider),
+				"provider": provider,
+			})
+			return
+		}
+		seen[provider] = true
+
+		modelName := request.Models[provider]
+		if modelName == "" {
+			modelName = c.aiManager.DefaultModel(provider)
+		} else if err := registry.Validate(provider, modelName, request.MaxTokens, time.Now()); err != nil {
 			ctx.JSON(400, gin.H{
 				"error":    "Invalid model for selected provider",
-				"details":  fmt.Sprintf("Model '%s' is not valid for provider '%s'. Valid models: %v", request.Model, request.Provider, models),
-				"provider": request.Provider,
-				"model":    request.Model,
+				"details":  err.Error(),
+				"provider": provider,
+				"model":    modelName,
 			})
 			return
 		}
+		if !checkAPIKeyScope(ctx, provider, modelName) {
+			return
+		}
 	}
 
-	// Create generation request
-	genReq := &model.GenerationRequest{
-		Provider:    provider,
-		Model:       request.Model,
-		Prompt:      request.Prompt,
-		SystemMsg:   request.SystemMsg,
-		Temperature: float32(request.Temperature),
-		MaxTokens:   request.MaxTokens,
+	// Every leg is estimated with the first provider's tokenizer
+	tokens := requestTokens(c.aiManager, &model.GenerationRequest{
+		Provider:  request.Providers[0],
+		Model:     request.Models[request.Providers[0]],
+		Prompt:    request.Prompt,
+		SystemMsg: request.SystemMsg,
+		MaxTokens: request.MaxTokens,
+	})
+	if !enforceQuota(ctx, c.quotaService, request.Providers, tokens) {
+		return
 	}
 
-	// Generate content using AI manager
-	startTime := time.Now()
-	response, err := c.aiManager.Generate(ctx, genReq)
+	comparison, err := c.aiManager.Compare(ctx, &request)
 	if err != nil {
 		ctx.JSON(500, gin.H{
-			"error":   "Failed to generate content",
+			"error":   "Failed to compare providers",
 			"details": err.Error(),
 		})
 		return
 	}
-	duration := time.Since(startTime)
 
-	// Save generation record to database
-	generationRecord := &model.GenerationHistory{
-		Provider:     string(response.Provider),
-		Model:        response.Model,
-		Prompt:       genReq.Prompt,
-		Response:     response.Content,
-		TokensUsed:   response.TokensUsed,
-		Duration:     int64(duration.Milliseconds()),
-		Status:       "success",
-		ErrorMessage: "",
-	}
+	// Save each leg to the database under the shared comparison ID
+	var successCount, errorCount int
+	for i := range comparison.Results {
+		result := &comparison.Results[i]
+		if result.Status == "success" {
+			successCount++
+		} else {
+			errorCount++
+		}
 
-	err = c.generationRepo.Create(ctx, generationRecord)
-	if err != nil {
-		// Log the error but don't fail the request
-		log.Printf("Failed to save generation record: %v", err)
+		generationRecord := &model.GenerationHistory{
+			Provider:     string(result.Provider),
+			Model:        result.Model,
+			Prompt:       request.Prompt,
+			UserID:       currentUserID(ctx),
+			Response:     result.Content,
+			TokensUsed:   result.TokensUsed,
+			Duration:     result.LatencyMs,
+			Status:       result.Status,
+			ErrorMessage: result.Error,
+			ComparisonID: comparison.ID,
+			TokenUsage:   result.TokenUsage,
+		}
+
+		applyCost(ctx, c.pricingService, generationRecord)
+		result.CostUSD = generationRecord.CostUSD
+
+		if err := c.generationRepo.Create(ctx, generationRecord); err != nil {
+			// Log the error but don't fail the request
+			log.Printf("Failed to save comparison record: %v", err)
+			continue
+		}
+		result.GenerationID = generationRecord.ID
 	}
 
-	// Return the response
 	ctx.JSON(200, gin.H{
-		"content":     response.Content,
-		"provider":    string(response.Provider),
-		"model":       response.Model,
-		"tokens_used": response.TokensUsed,
-		"duration":    duration.String(),
-		"status":      "success",
+		"id":         comparison.ID,
+		"prompt":     comparison.Prompt,
+		"results":    comparison.Results,
+		"created_at": comparison.CreatedAt.Format(time.RFC3339),
+		"summary": gin.H{
+			"total":         len(comparison.Results),
+			"success_count": successCount,
+			"error_count":   errorCount,
+		},
 	})
 }
 
-func (c *aiController) CompareProviders(ctx *gin.Context) {
-	ctx.JSON(200, gin.H{"message": "Compare providers endpoint"})
+func (c *aiController) GetComparison(ctx *gin.Context) {
+	comparisonID := ctx.Param("id")
+	if _, err := uuid.Parse(comparisonID); err != nil {
+		ctx.JSON(400, gin.H{
+			"error":   "Invalid comparison ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	generations, err := c.generationRepo.GetByComparisonID(ctx, comparisonID)
+	if err != nil {
+		log.Printf("Failed to load comparison: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load comparison",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(generations) == 0 {
+		ctx.JSON(404, gin.H{
+			"error":   "Comparison not found",
+			"details": fmt.Sprintf("No generations found for comparison '%s'", comparisonID),
+		})
+		return
+	}
+
+	results := make([]model.ComparisonResult, len(generations))
+	for i, gen := range generations {
+		results[i] = model.ComparisonResult{
+			Provider:     model.AIProvider(gen.Provider),
+			Model:        gen.Model,
+			Content:      gen.Response,
+			TokensUsed:   gen.TokensUsed,
+			LatencyMs:    gen.Duration,
+			Status:       gen.Status,
+			Error:        gen.ErrorMessage,
+			GenerationID: gen.ID,
+			CostUSD:      gen.CostUSD,
+			TokenUsage:   gen.TokenUsage,
+		}
+	}
+
+	ctx.JSON(200, gin.H{
+		"id":         comparisonID,
+		"prompt":     generations[0].Prompt,
+		"results":    results,
+		"created_at": generations[0].CreatedAt.Format(time.RFC3339),
+	})
 }
 
+// GetProviders lists the registered providers that have models, with their
+// configuration schema
 func (c *aiController) GetProviders(ctx *gin.Context) {
-	providers := []gin.H{
-		{
-			"id":          "openai",
-			"name":        "OpenAI",
-			"description": "Advanced language models for text generation",
-			"models":      []string{"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo"},
-			"available":   true,
-		},
-		{
-			"id":          "gemini",
-			"name":        "Google Gemini",
-			"description": "Google's multimodal AI model",
-			"models":      []string{"gemini-1.5-flash", "gemini-1.5-pro", "gemini-2.0-flash"},
-			"available":   true,
-		},
-		{
-			"id":          "anthropic",
-			"name":        "Anthropic Claude",
-			"description": "Constitutional AI for safe and helpful responses",
-			"models":      []string{"claude-3-sonnet", "claude-3-opus", "claude-3-haiku"},
-			"available":   false,
-		},
+	registry := c.aiManager.ModelCatalog(ctx)
+	now := time.Now()
+
+	definitions := c.aiManager.ProviderDefinitions()
+	providers := make([]gin.H, 0, len(definitions))
+	for _, definition := range definitions {
+		// Providers whose models come only from discovery, like Ollama, are
+		// listed once they report some
+		if len(registry.Models(definition.ID)) == 0 {
+			continue
+		}
+		_, err := c.aiManager.GetProvider(definition.ID)
+		providers = append(providers, gin.H{
+			"id":            string(definition.ID),
+			"name":          definition.Name,
+			"description":   definition.Description,
+			"models":        registry.ModelIDs(definition.ID, now),
+			"model_details": registry.Models(definition.ID),
+			"config_schema": definition.ConfigSchema,
+			"configured":    err == nil,
+			"available":     true,
+		})
 	}
 
 	ctx.JSON(200, gin.H{
@@ -189,6 +608,36 @@ func (c *aiController) GetProviders(ctx *gin.Context) {
 	})
 }
 
+// RefreshModels lists every provider's models again and reports the changes
+func (c *aiController) RefreshModels(ctx *gin.Context) {
+	refreshes := c.aiManager.RefreshModels(ctx)
+
+	ctx.JSON(200, gin.H{
+		"providers": refreshes,
+		"total":     len(refreshes),
+	})
+}
+
+// ReloadProviders reloads provider configuration and reports which providers
+// were added, removed or updated
+func (c *aiController) ReloadProviders(ctx *gin.Context) {
+	caller := requestCaller(ctx)
+
+	reload, err := c.aiManager.Reload(ctx)
+	if err != nil {
+		log.Printf("Failed to reload provider configuration for %s: %v", caller, err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to reload provider configuration",
+			"details": err.Error(),
+		})
+		return
+	}
+	log.Printf("Provider configuration reloaded by %s", caller)
+	outbound.LogProviderReload(reload)
+
+	ctx.JSON(200, reload)
+}
+
 func (c *aiController) GetHistory(ctx *gin.Context) {
 	generations, err := c.generationRepo.GetRecent(ctx, 50, 0)
 	if err != nil {
@@ -204,15 +653,20 @@ func (c *aiController) GetHistory(ctx *gin.Context) {
 	history := make([]gin.H, len(generations))
 	for i, gen := range generations {
 		history[i] = gin.H{
-			"id":          gen.ID,
-			"provider":    gen.Provider,
-			"model":       gen.Model,
-			"prompt":      gen.Prompt,
-			"response":    gen.Response,
-			"tokens_used": gen.TokensUsed,
-			"duration":    fmt.Sprintf("%dms", gen.Duration),
-			"created_at":  gen.CreatedAt.Format(time.RFC3339),
-			"status":      gen.Status,
+			"id":                gen.ID,
+			"provider":          gen.Provider,
+			"model":             gen.Model,
+			"prompt":            gen.Prompt,
+			"response":          gen.Response,
+			"tokens_used":       gen.TokensUsed,
+			"prompt_tokens":     gen.PromptTokens,
+			"completion_tokens": gen.CompletionTokens,
+			"tokens_estimated":  gen.Estimated,
+			"duration":          fmt.Sprintf("%dms", gen.Duration),
+			"created_at":        gen.CreatedAt.Format(time.RFC3339),
+			"status":            gen.Status,
+			"comparison_id":     gen.ComparisonID,
+			"user_id":           gen.UserID,
 		}
 	}
 
@@ -237,15 +691,26 @@ func (c *aiController) GetStats(ctx *gin.Context) {
 		return
 	}
 
+	spend, err := c.generationRepo.GetSpend(ctx, startDate, endDate)
+	if err != nil {
+		log.Printf("Failed to load spend: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load spend",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	// Convert to API response format
 	stats := make(map[string]gin.H)
-	var totalGenerations, totalTokens, totalErrors int
+	var totalGenerations, totalTokens, totalErrors, totalFailovers int
 	var totalDuration int64
 
 	for _, stat := range providerStats {
 		totalGenerations += stat.TotalGenerations
 		totalTokens += stat.TotalTokens
 		totalErrors += stat.ErrorCount
+		totalFailovers += stat.FailoverCount
 		totalDuration += int64(stat.AvgDuration * float64(stat.TotalGenerations))
 
 		successRate := 100.0
@@ -259,6 +724,8 @@ func (c *aiController) GetStats(ctx *gin.Context) {
 			"total_tokens":      stat.TotalTokens,
 			"avg_duration":      stat.AvgDuration,
 			"error_count":       stat.ErrorCount,
+			"failover_count":    stat.FailoverCount,
+			"total_cost_usd":    stat.TotalCostUSD,
 			"success_rate":      successRate,
 		}
 	}
@@ -275,11 +742,14 @@ func (c *aiController) GetStats(ctx *gin.Context) {
 
 	ctx.JSON(200, gin.H{
 		"stats": stats,
+		"spend": spend,
 		"summary": gin.H{
 			"total_generations": totalGenerations,
 			"total_tokens":      totalTokens,
 			"avg_duration":      avgDuration,
 			"total_errors":      totalErrors,
+			"total_failovers":   totalFailovers,
+			"total_cost_usd":    spend.TotalCostUSD,
 			"success_rate":      successRate,
 		},
 	})
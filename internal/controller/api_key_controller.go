@@ -0,0 +1,262 @@
+package controller
+
+import (
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/repository"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyController interface {
+	CreateAPIKey(c *gin.Context)
+	ListAPIKeys(c *gin.Context)
+	RotateAPIKey(c *gin.Context)
+	RevokeAPIKey(c *gin.Context)
+	UpdateAPIKeyQuota(c *gin.Context)
+}
+
+type apiKeyController struct {
+	aiManager  *outbound.Manager
+	apiKeyRepo repository.APIKeyRepository
+}
+
+func NewAPIKeyController(aiManager *outbound.Manager, apiKeyRepo repository.APIKeyRepository) APIKeyController {
+	return &apiKeyController{
+		aiManager:  aiManager,
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CreateAPIKey issues a new client API key. The key itself is only returned
+// by this call; afterwards only its prefix is shown.
+func (c *apiKeyController) CreateAPIKey(ctx *gin.Context) {
+	var request struct {
+		Name             string             `json:"name" binding:"required"`
+		Role             string             `json:"role"`
+		AllowedProviders []string           `json:"allowed_providers"`
+		AllowedModels    []string           `json:"allowed_models"`
+		Quota            *model.QuotaLimits `json:"quota"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{"error": "Name is required", "details": err.Error()})
+		return
+	}
+
+	if request.Role == "" {
+		request.Role = authentication.RoleUser
+	}
+	if request.Role != authentication.RoleUser && request.Role != authentication.RoleAdmin {
+		ctx.JSON(400, gin.H{
+			"error":   "Invalid role",
+			"details": fmt.Sprintf("Role '%s' is not supported. Supported roles: %s, %s", request.Role, authentication.RoleUser, authentication.RoleAdmin),
+		})
+		return
+	}
+
+	for _, provider := range request.AllowedProviders {
+		if !checkProviderRegistered(ctx, c.aiManager, provider) {
+			return
+		}
+	}
+
+	secret, prefix, hash, err := authentication.GenerateAPIKey()
+	if err != nil {
+		log.Printf("Failed to generate API key: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to generate API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key := &model.APIKey{
+		Name:             request.Name,
+		KeyPrefix:        prefix,
+		KeyHash:          hash,
+		Role:             request.Role,
+		AllowedProviders: request.AllowedProviders,
+		AllowedModels:    request.AllowedModels,
+		Quota:            request.Quota,
+	}
+
+	if err := c.apiKeyRepo.Create(ctx, key); err != nil {
+		log.Printf("Failed to create API key: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to create API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"api_key": key,
+		"key":     secret,
+		"warning": "Store this key now, it will not be shown again",
+	})
+}
+
+// ListAPIKeys returns every API key without its secret
+func (c *apiKeyController) ListAPIKeys(ctx *gin.Context) {
+	keys, err := c.apiKeyRepo.List(ctx)
+	if err != nil {
+		log.Printf("Failed to list API keys: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to list API keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"api_keys": keys,
+		"total":    len(keys),
+	})
+}
+
+// RotateAPIKey replaces the secret of an active key. The old secret stops
+// working immediately and the new one is only returned by this call.
+func (c *apiKeyController) RotateAPIKey(ctx *gin.Context) {
+	keyID, ok := apiKeyID(ctx)
+	if !ok {
+		return
+	}
+
+	secret, prefix, hash, err := authentication.GenerateAPIKey()
+	if err != nil {
+		log.Printf("Failed to generate API key: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to generate API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	err = c.apiKeyRepo.Rotate(ctx, keyID, hash, prefix)
+	if errors.Is(err, exceptioncode.ErrEmptyResult) {
+		ctx.JSON(404, gin.H{
+			"error":   "API key not found",
+			"details": fmt.Sprintf("API key '%s' does not exist or has been revoked", keyID),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to rotate API key: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to rotate API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key, err := c.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		log.Printf("Failed to load API key: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"api_key": key,
+		"key":     secret,
+		"warning": "Store this key now, it will not be shown again",
+	})
+}
+
+// RevokeAPIKey permanently disables a key
+func (c *apiKeyController) RevokeAPIKey(ctx *gin.Context) {
+	keyID, ok := apiKeyID(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.apiKeyRepo.Revoke(ctx, keyID)
+	if errors.Is(err, exceptioncode.ErrEmptyResult) {
+		ctx.JSON(404, gin.H{
+			"error":   "API key not found",
+			"details": fmt.Sprintf("API key '%s' does not exist", keyID),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to revoke API key: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to revoke API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "API key revoked"})
+}
+
+// UpdateAPIKeyQuota replaces a key's token and request limits. A null quota
+// restores the default limits.
+func (c *apiKeyController) UpdateAPIKeyQuota(ctx *gin.Context) {
+	keyID, ok := apiKeyID(ctx)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Quota *model.QuotaLimits `json:"quota"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	err := c.apiKeyRepo.UpdateQuota(ctx, keyID, request.Quota)
+	if errors.Is(err, exceptioncode.ErrEmptyResult) {
+		ctx.JSON(404, gin.H{
+			"error":   "API key not found",
+			"details": fmt.Sprintf("API key '%s' does not exist", keyID),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update API key quota: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to update API key quota",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key, err := c.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		log.Printf("Failed to load API key: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, gin.H{"api_key": key})
+}
+
+// apiKeyID returns the :id path parameter, writing a 400 response and
+// returning ok=false when it is not a UUID
+func apiKeyID(ctx *gin.Context) (string, bool) {
+	keyID := ctx.Param("id")
+	if _, err := uuid.Parse(keyID); err != nil {
+		ctx.JSON(400, gin.H{
+			"error":   "Invalid API key ID",
+			"details": err.Error(),
+		})
+		return "", false
+	}
+	return keyID, true
+}
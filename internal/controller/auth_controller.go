@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"ai-service/cmd/config"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController interface {
+	IssueToken(c *gin.Context)
+	RefreshToken(c *gin.Context)
+}
+
+type authController struct {
+	clients []config.AuthClient
+}
+
+func NewAuthController(clients []config.AuthClient) AuthController {
+	return &authController{
+		clients: clients,
+	}
+}
+
+// IssueToken exchanges client credentials for a signed JWT
+func (c *authController) IssueToken(ctx *gin.Context) {
+	var request struct {
+		ClientID     string `json:"client_id" binding:"required"`
+		ClientSecret string `json:"client_secret" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	client, ok := c.findClient(request.ClientID, request.ClientSecret)
+	if !ok {
+		ctx.JSON(401, gin.H{
+			"error":   "Invalid client credentials",
+			"code":    exceptioncode.CodeInvalidCredential,
+			"details": "Unknown client ID or wrong secret",
+		})
+		return
+	}
+
+	token, err := authentication.GenerateToken(client.ID, client.ID, "", client.Role)
+	if err != nil {
+		log.Printf("Failed to generate token: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to generate token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, tokenResponse(token, client.Role))
+}
+
+// RefreshToken issues a new token for a valid token that is close to expiry
+func (c *authController) RefreshToken(ctx *gin.Context) {
+	tokenString, err := authentication.ExtractToken(ctx.GetHeader("Authorization"))
+	if err != nil {
+		ctx.JSON(401, gin.H{
+			"error":   "Unauthorized",
+			"code":    exceptioncode.CodeTokenInvalid,
+			"details": "A valid Bearer token is required",
+		})
+		return
+	}
+
+	claims, err := authentication.ValidateToken(tokenString)
+	if err != nil {
+		code := exceptioncode.CodeTokenInvalid
+		if errors.Is(err, exceptioncode.ErrTokenExpired) {
+			code = exceptioncode.CodeTokenExpired
+		}
+		ctx.JSON(401, gin.H{
+			"error":   "Unauthorized",
+			"code":    code,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	token, err := authentication.RefreshToken(tokenString)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error":   "Token cannot be refreshed yet",
+			"code":    exceptioncode.CodeBadRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, tokenResponse(token, claims.Role))
+}
+
+// findClient looks up a configured client, comparing secrets in constant time
+func (c *authController) findClient(clientID, clientSecret string) (config.AuthClient, bool) {
+	for _, client := range c.clients {
+		if client.ID != clientID {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(client.Secret), []byte(clientSecret)) == 1 {
+			return client, true
+		}
+	}
+	return config.AuthClient{}, false
+}
+
+func tokenResponse(token, role string) gin.H {
+	expiration := authentication.Expiration()
+	return gin.H{
+		"token":      token,
+		"token_type": "Bearer",
+		"role":       role,
+		"expires_in": int(expiration.Seconds()),
+		"expires_at": time.Now().Add(expiration),
+	}
+}
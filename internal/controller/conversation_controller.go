@@ -0,0 +1,346 @@
+package controller
+
+import (
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/repository"
+	"ai-service/internal/service"
+	"ai-service/internal/util/exceptioncode"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ConversationController interface {
+	CreateConversation(c *gin.Context)
+	ListConversations(c *gin.Context)
+	GetConversation(c *gin.Context)
+	UpdateConversation(c *gin.Context)
+	DeleteConversation(c *gin.Context)
+	SendMessage(c *gin.Context)
+}
+
+type conversationController struct {
+	aiManager        *outbound.Manager
+	conversationRepo repository.ConversationRepository
+	generationRepo   repository.GenerationRepository
+	quotaService     service.QuotaService
+	pricingService   service.PricingService
+}
+
+func NewConversationController(aiManager *outbound.Manager, conversationRepo repository.ConversationRepository, generationRepo repository.GenerationRepository, quotaService service.QuotaService, pricingService service.PricingService) ConversationController {
+	return &conversationController{
+		aiManager:        aiManager,
+		conversationRepo: conversationRepo,
+		generationRepo:   generationRepo,
+		quotaService:     quotaService,
+		pricingService:   pricingService,
+	}
+}
+
+func (c *conversationController) CreateConversation(ctx *gin.Context) {
+	var request struct {
+		Title     string `json:"title"`
+		Provider  string `json:"provider" binding:"required"`
+		Model     string `json:"model" binding:"required"`
+		SystemMsg string `json:"system_message"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	provider, ok := validateProviderModel(ctx, c.aiManager.Registry(), request.Provider, request.Model, 0)
+	if !ok {
+		return
+	}
+
+	conversation := &model.Conversation{
+		Title:     request.Title,
+		Provider:  string(provider),
+		Model:     request.Model,
+		SystemMsg: request.SystemMsg,
+	}
+
+	if err := c.conversationRepo.Create(ctx, conversation); err != nil {
+		log.Printf("Failed to create conversation: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to create conversation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(201, conversation)
+}
+
+func (c *conversationController) ListConversations(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	conversations, err := c.conversationRepo.List(ctx, limit, offset)
+	if err != nil {
+		log.Printf("Failed to load conversations: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load conversations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"conversations": conversations,
+		"total":         len(conversations),
+	})
+}
+
+func (c *conversationController) GetConversation(ctx *gin.Context) {
+	conversation, ok := c.loadConversation(ctx)
+	if !ok {
+		return
+	}
+
+	messages, err := c.conversationRepo.GetMessages(ctx, conversation.ID)
+	if err != nil {
+		log.Printf("Failed to load conversation messages: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load conversation messages",
+			"details": err.Error(),
+		})
+		return
+	}
+	conversation.Messages = messages
+
+	ctx.JSON(200, conversation)
+}
+
+func (c *conversationController) UpdateConversation(ctx *gin.Context) {
+	var request struct {
+		Title     *string `json:"title"`
+		Model     *string `json:"model"`
+		SystemMsg *string `json:"system_message"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	conversation, ok := c.loadConversation(ctx)
+	if !ok {
+		return
+	}
+
+	if request.Title != nil {
+		conversation.Title = *request.Title
+	}
+	if request.Model != nil {
+		if _, ok := validateProviderModel(ctx, c.aiManager.Registry(), conversation.Provider, *request.Model, 0); !ok {
+			return
+		}
+		conversation.Model = *request.Model
+	}
+	if request.SystemMsg != nil {
+		conversation.SystemMsg = *request.SystemMsg
+	}
+
+	if err := c.conversationRepo.Update(ctx, conversation); err != nil {
+		log.Printf("Failed to update conversation: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to update conversation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, conversation)
+}
+
+func (c *conversationController) DeleteConversation(ctx *gin.Context) {
+	conversation, ok := c.loadConversation(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.conversationRepo.Delete(ctx, conversation.ID); err != nil {
+		log.Printf("Failed to delete conversation: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to delete conversation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"id":      conversation.ID,
+		"message": "Conversation deleted",
+	})
+}
+
+// SendMessage appends a user turn, replays the full conversation to the
+// provider and stores both the user turn and the assistant reply
+func (c *conversationController) SendMessage(ctx *gin.Context) {
+	var request struct {
+		Content     string  `json:"content" binding:"required"`
+		Temperature float64 `json:"temperature"`
+		MaxTokens   int     `json:"maxTokens"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{"error": "Content is required", "details": err.Error()})
+		return
+	}
+
+	conversation, ok := c.loadConversation(ctx)
+	if !ok {
+		return
+	}
+	if !checkAPIKeyScope(ctx, model.AIProvider(conversation.Provider), conversation.Model) {
+		return
+	}
+	history, err := c.conversationRepo.GetMessages(ctx, conversation.ID)
+	if err != nil {
+		log.Printf("Failed to load conversation messages: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load conversation messages",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	messages := make([]model.Message, len(history))
+	for i, message := range history {
+		messages[i] = model.Message{
+			Role:    message.Role,
+			Content: message.Content,
+		}
+	}
+
+	genReq := &model.GenerationRequest{
+		Provider:    model.AIProvider(conversation.Provider),
+		Model:       conversation.Model,
+		Prompt:      request.Content,
+		SystemMsg:   conversation.SystemMsg,
+		Temperature: float32(request.Temperature),
+		MaxTokens:   request.MaxTokens,
+		Messages:    messages,
+	}
+	if !enforceQuota(ctx, c.quotaService, []model.AIProvider{genReq.Provider}, requestTokens(c.aiManager, genReq)) {
+		return
+	}
+
+	startTime := time.Now()
+	response, err := c.aiManager.Generate(ctx, genReq)
+	if err != nil {
+		ctx.JSON(generationErrorStatus(err), gin.H{
+			"error":           "Failed to generate content",
+			"details":         err.Error(),
+			"failed_attempts": failedAttempts(err),
+		})
+		return
+	}
+	duration := time.Since(startTime)
+
+	userMessage := &model.ConversationMessage{
+		Role:    model.RoleUser,
+		Content: request.Content,
+	}
+	assistantMessage := &model.ConversationMessage{
+		Role:       model.RoleAssistant,
+		Content:    response.Content,
+		TokensUsed: response.TokensUsed,
+	}
+
+	if err := c.conversationRepo.AddMessages(ctx, conversation.ID, userMessage, assistantMessage); err != nil {
+		log.Printf("Failed to save conversation messages: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to save conversation messages",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Save generation record to database
+	generationRecord := &model.GenerationHistory{
+		Provider:   string(response.Provider),
+		Model:      response.Model,
+		Prompt:     request.Content,
+		UserID:     currentUserID(ctx),
+		Response:   response.Content,
+		TokensUsed: response.TokensUsed,
+		Duration:   int64(duration.Milliseconds()),
+		Status:     "success",
+
+		RequestedProvider: string(response.RequestedProvider),
+		FailedAttempts:    response.FailedAttempts,
+		TokenUsage:        response.TokenUsage,
+	}
+
+	applyCost(ctx, c.pricingService, generationRecord)
+	if err := c.generationRepo.Create(ctx, generationRecord); err != nil {
+		// Log the error but don't fail the request
+		log.Printf("Failed to save generation record: %v", err)
+	}
+
+	ctx.JSON(200, gin.H{
+		"conversation_id":    conversation.ID,
+		"message":            assistantMessage,
+		"provider":           string(response.Provider),
+		"model":              response.Model,
+		"tokens_used":        response.TokensUsed,
+		"prompt_tokens":      response.PromptTokens,
+		"completion_tokens":  response.CompletionTokens,
+		"total_tokens":       response.TotalTokens,
+		"tokens_estimated":   response.Estimated,
+		"truncated":          response.Truncated,
+		"duration":           duration.String(),
+		"status":             "success",
+		"requested_provider": string(response.RequestedProvider),
+		"failed_attempts":    response.FailedAttempts,
+		"cost_usd":           generationRecord.CostUSD,
+	})
+}
+
+// loadConversation fetches the conversation named by the :id route parameter,
+// writing a 400 or 404 response and returning ok=false when it cannot
+func (c *conversationController) loadConversation(ctx *gin.Context) (*model.Conversation, bool) {
+	conversationID := ctx.Param("id")
+	if _, err := uuid.Parse(conversationID); err != nil {
+		ctx.JSON(400, gin.H{
+			"error":   "Invalid conversation ID",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	conversation, err := c.conversationRepo.GetByID(ctx, conversationID)
+	if errors.Is(err, exceptioncode.ErrEmptyResult) {
+		ctx.JSON(404, gin.H{
+			"error":   "Conversation not found",
+			"details": fmt.Sprintf("Conversation '%s' does not exist", conversationID),
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Failed to load conversation: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load conversation",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return conversation, true
+}
@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"ai-service/internal/outbound"
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,18 +12,43 @@ type HealthController interface {
 }
 
 type healthController struct {
-	// service will be injected
+	aiManager *outbound.Manager
 }
 
-func NewHealthController() HealthController {
-	return &healthController{}
+func NewHealthController(aiManager *outbound.Manager) HealthController {
+	return &healthController{
+		aiManager: aiManager,
+	}
 }
 
+// GetHealthCheck reports the service status along with the circuit breaker
+// state of every configured provider. The service is degraded while some
+// breakers are open and unhealthy once all of them are.
 func (c *healthController) GetHealthCheck(ctx *gin.Context) {
-	ctx.JSON(200, gin.H{
-		"status":    "healthy",
+	providerHealth := c.aiManager.ProviderHealth()
+
+	providers := make(gin.H, len(providerHealth))
+	openCount := 0
+	for providerType, breaker := range providerHealth {
+		providers[string(providerType)] = breaker
+		if breaker.State == outbound.BreakerOpen {
+			openCount++
+		}
+	}
+
+	status, code := "healthy", 200
+	switch {
+	case len(providerHealth) > 0 && openCount == len(providerHealth):
+		status, code = "unhealthy", 503
+	case openCount > 0:
+		status = "degraded"
+	}
+
+	ctx.JSON(code, gin.H{
+		"status":    status,
 		"service":   "ai-service",
 		"version":   "1.0.0",
-		"timestamp": "2024-01-15T10:30:00Z",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"providers": providers,
 	})
 }
@@ -0,0 +1,385 @@
+package controller
+
+import (
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/repository"
+	"ai-service/internal/service"
+	"ai-service/internal/util/authentication"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OpenAIController serves the OpenAI chat completions and models API, routing
+// each request to the provider that serves the requested model
+type OpenAIController interface {
+	ChatCompletions(c *gin.Context)
+	ListModels(c *gin.Context)
+}
+
+type openAIController struct {
+	aiManager      *outbound.Manager
+	generationRepo repository.GenerationRepository
+	quotaService   service.QuotaService
+	pricingService service.PricingService
+}
+
+func NewOpenAIController(aiManager *outbound.Manager, generationRepo repository.GenerationRepository, quotaService service.QuotaService, pricingService service.PricingService) OpenAIController {
+	return &openAIController{
+		aiManager:      aiManager,
+		generationRepo: generationRepo,
+		quotaService:   quotaService,
+		pricingService: pricingService,
+	}
+}
+
+// chatCompletionRequest is the subset of the OpenAI chat completions request
+// the gateway supports
+type chatCompletionRequest struct {
+	Model               string             `json:"model" binding:"required"`
+	Messages            []chatMessage      `json:"messages" binding:"required"`
+	MaxTokens           int                `json:"max_tokens"`
+	MaxCompletionTokens int                `json:"max_completion_tokens"`
+	Temperature         float32            `json:"temperature"`
+	N                   int                `json:"n"`
+	Stream              bool               `json:"stream"`
+	StreamOptions       *chatStreamOptions `json:"stream_options"`
+}
+
+type chatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatMessage is a chat message whose content is either a string or a list
+// of content parts, of which only text parts are supported
+type chatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// text returns the message content, joining text parts
+func (m chatMessage) text() (string, error) {
+	var content string
+	if err := json.Unmarshal(m.Content, &content); err == nil {
+		return content, nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(m.Content, &parts); err != nil {
+		return "", fmt.Errorf("message content must be a string or a list of content parts")
+	}
+
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != "text" {
+			return "", fmt.Errorf("content part type %q is not supported, only text", part.Type)
+		}
+		texts = append(texts, part.Text)
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// generationRequest converts the chat messages: system messages become the
+// system message, the final user message the prompt, and the rest the prior
+// turns, with consecutive messages of one role merged
+func (r *chatCompletionRequest) generationRequest(provider model.AIProvider, modelName string) (*model.GenerationRequest, error) {
+	var system []string
+	var turns []model.Message
+	for i, message := range r.Messages {
+		content, err := message.text()
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+
+		switch message.Role {
+		case "system", "developer":
+			system = append(system, content)
+			continue
+		case model.RoleUser, model.RoleAssistant:
+		default:
+			return nil, fmt.Errorf("messages[%d]: role %q is not supported", i, message.Role)
+		}
+
+		if len(turns) > 0 && turns[len(turns)-1].Role == message.Role {
+			turns[len(turns)-1].Content += "\n\n" + content
+			continue
+		}
+		turns = append(turns, model.Message{Role: message.Role, Content: content})
+	}
+
+	if len(turns) == 0 || turns[len(turns)-1].Role != model.RoleUser {
+		return nil, fmt.Errorf("the last message must have role \"user\"")
+	}
+	if turns[0].Role != model.RoleUser {
+		return nil, fmt.Errorf("the first non-system message must have role \"user\"")
+	}
+
+	maxTokens := r.MaxCompletionTokens
+	if maxTokens == 0 {
+		maxTokens = r.MaxTokens
+	}
+
+	return &model.GenerationRequest{
+		Provider:    provider,
+		Model:       modelName,
+		Prompt:      turns[len(turns)-1].Content,
+		Messages:    turns[:len(turns)-1],
+		SystemMsg:   strings.Join(system, "\n\n"),
+		MaxTokens:   maxTokens,
+		Temperature: r.Temperature,
+	}, nil
+}
+
+// ChatCompletions handles POST /v1/chat/completions
+func (c *openAIController) ChatCompletions(ctx *gin.Context) {
+	var request chatCompletionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		openAIError(ctx, 400, "invalid_request_error", "", err.Error())
+		return
+	}
+	if request.N > 1 {
+		openAIError(ctx, 400, "invalid_request_error", "", "n greater than 1 is not supported")
+		return
+	}
+
+	registry := c.aiManager.Registry()
+	info, ok := registry.Resolve(request.Model)
+	if !ok {
+		openAIError(ctx, 404, "invalid_request_error", "model_not_found", fmt.Sprintf("The model '%s' does not exist", request.Model))
+		return
+	}
+
+	// Strip a "provider/" qualifier before the name reaches the provider
+	modelName := request.Model
+	if qualifier := string(info.Provider) + "/"; strings.HasPrefix(modelName, qualifier) {
+		modelName = strings.TrimPrefix(modelName, qualifier)
+	}
+
+	genReq, err := request.generationRequest(info.Provider, modelName)
+	if err != nil {
+		openAIError(ctx, 400, "invalid_request_error", "", err.Error())
+		return
+	}
+	if err := registry.Validate(info.Provider, modelName, genReq.MaxTokens, time.Now()); err != nil {
+		openAIError(ctx, 400, "invalid_request_error", "", err.Error())
+		return
+	}
+	if key, ok := authentication.APIKeyFromContext(ctx.Request.Context()); ok && !key.Allows(string(info.Provider), modelName) {
+		openAIError(ctx, 403, "permission_error", "", fmt.Sprintf("This API key may not use model '%s'", request.Model))
+		return
+	}
+	if !enforceQuota(ctx, c.quotaService, []model.AIProvider{genReq.Provider}, requestTokens(c.aiManager, genReq)) {
+		return
+	}
+
+	if request.Stream {
+		includeUsage := request.StreamOptions != nil && request.StreamOptions.IncludeUsage
+		c.streamCompletion(ctx, genReq, includeUsage)
+		return
+	}
+
+	startTime := time.Now()
+	response, err := c.aiManager.Generate(ctx, genReq)
+	duration := time.Since(startTime)
+
+	record := c.saveGeneration(ctx, genReq, response, err, duration)
+	if err != nil {
+		openAIError(ctx, generationErrorStatus(err), "api_error", "", err.Error())
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"id":      "chatcmpl-" + uuid.NewString(),
+		"object":  "chat.completion",
+		"created": response.GeneratedAt.Unix(),
+		"model":   response.Model,
+		"choices": []gin.H{{
+			"index":         0,
+			"message":       gin.H{"role": model.RoleAssistant, "content": response.Content},
+			"finish_reason": finishReason(genReq, response),
+		}},
+		"usage": openAIUsage(response),
+		// Gateway extensions, ignored by OpenAI clients
+		"provider": string(response.Provider),
+		"cost_usd": record.CostUSD,
+	})
+}
+
+// streamCompletion sends chat.completion.chunk events, ending with "[DONE]".
+// Failures before the first chunk are returned as a regular JSON error.
+func (c *openAIController) streamCompletion(ctx *gin.Context, genReq *model.GenerationRequest, includeUsage bool) {
+	id := "chatcmpl-" + uuid.NewString()
+	created := time.Now().Unix()
+
+	started := false
+	send := func(payload interface{}) {
+		if !started {
+			started = true
+			ctx.Header("Content-Type", "text/event-stream")
+			ctx.Header("Cache-Control", "no-cache")
+			ctx.Header("Connection", "keep-alive")
+			ctx.Header("X-Accel-Buffering", "no")
+			ctx.Status(200)
+		}
+		data, _ := json.Marshal(payload)
+		fmt.Fprintf(ctx.Writer, "data: %s\n\n", data)
+		ctx.Writer.Flush()
+	}
+	chunk := func(delta gin.H, finishReason interface{}) gin.H {
+		return gin.H{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   genReq.Model,
+			"choices": []gin.H{{"index": 0, "delta": delta, "finish_reason": finishReason}},
+		}
+	}
+
+	startTime := time.Now()
+	response, err := c.aiManager.GenerateStream(ctx.Request.Context(), genReq, func(content string) error {
+		delta := gin.H{"content": content}
+		if !started {
+			delta["role"] = model.RoleAssistant
+		}
+		send(chunk(delta, nil))
+		return ctx.Request.Context().Err()
+	})
+	duration := time.Since(startTime)
+
+	c.saveGeneration(context.WithoutCancel(ctx.Request.Context()), genReq, response, err, duration)
+
+	if err != nil {
+		if !started {
+			openAIError(ctx, generationErrorStatus(err), "api_error", "", err.Error())
+			return
+		}
+		send(gin.H{"error": gin.H{"message": err.Error(), "type": "api_error"}})
+		return
+	}
+
+	send(chunk(gin.H{}, finishReason(genReq, response)))
+	if includeUsage {
+		send(gin.H{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   response.Model,
+			"choices": []gin.H{},
+			"usage":   openAIUsage(response),
+		})
+	}
+	fmt.Fprint(ctx.Writer, "data: [DONE]\n\n")
+	ctx.Writer.Flush()
+}
+
+// finishReason reports why generation stopped, as the provider said or,
+// when it said nothing, "length" once the completion used all of max_tokens
+func finishReason(genReq *model.GenerationRequest, response *model.GenerationResponse) string {
+	if response.FinishReason != "" {
+		return response.FinishReason
+	}
+	if genReq.MaxTokens > 0 && response.TokenUsage.CompletionTokens >= genReq.MaxTokens {
+		return model.FinishReasonLength
+	}
+	return model.FinishReasonStop
+}
+
+// saveGeneration records the generation in the history table so it counts
+// towards quotas and stats, logging rather than failing on errors
+func (c *openAIController) saveGeneration(ctx context.Context, genReq *model.GenerationRequest, response *model.GenerationResponse, err error, duration time.Duration) *model.GenerationHistory {
+	userID := ""
+	if claims, ok := authentication.ClaimsFromContext(ctx); ok {
+		userID = claims.UserID
+	}
+
+	record := &model.GenerationHistory{
+		Provider: string(genReq.Provider),
+		Model:    genReq.Model,
+		Prompt:   genReq.Prompt,
+		UserID:   userID,
+		Duration: int64(duration.Milliseconds()),
+		Status:   "success",
+	}
+	if err != nil {
+		record.Status = "error"
+		record.ErrorMessage = err.Error()
+		record.FailedAttempts = failedAttempts(err)
+	} else {
+		record.Provider = string(response.Provider)
+		record.Model = response.Model
+		record.Response = response.Content
+		record.TokensUsed = response.TokensUsed
+		record.TokenUsage = response.TokenUsage
+		record.RequestedProvider = string(response.RequestedProvider)
+		record.FailedAttempts = response.FailedAttempts
+	}
+
+	applyCost(ctx, c.pricingService, record)
+	if saveErr := c.generationRepo.Create(ctx, record); saveErr != nil {
+		// Log the error but don't fail the request
+		log.Printf("Failed to save generation record: %v", saveErr)
+	}
+	return record
+}
+
+// ListModels handles GET /v1/models, listing the models of every configured
+// provider
+func (c *openAIController) ListModels(ctx *gin.Context) {
+	registry := c.aiManager.ModelCatalog(ctx)
+	now := time.Now()
+
+	models := []gin.H{}
+	for _, provider := range registry.Providers() {
+		if _, err := c.aiManager.GetProvider(provider); err != nil {
+			continue
+		}
+		for _, id := range registry.ModelIDs(provider, now) {
+			models = append(models, gin.H{
+				"id":       id,
+				"object":   "model",
+				"created":  0,
+				"owned_by": string(provider),
+			})
+		}
+	}
+
+	ctx.JSON(200, gin.H{
+		"object": "list",
+		"data":   models,
+	})
+}
+
+// openAIUsage returns the usage object of a chat completion
+func openAIUsage(response *model.GenerationResponse) gin.H {
+	return gin.H{
+		"prompt_tokens":     response.PromptTokens,
+		"completion_tokens": response.CompletionTokens,
+		"total_tokens":      response.TotalTokens,
+	}
+}
+
+// openAIError writes an error in the OpenAI error schema
+func openAIError(ctx *gin.Context, status int, errType, code, message string) {
+	var errCode interface{}
+	if code != "" {
+		errCode = code
+	}
+	ctx.JSON(status, gin.H{
+		"error": gin.H{
+			"message": message,
+			"type":    errType,
+			"param":   nil,
+			"code":    errCode,
+		},
+	})
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/service"
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingController interface {
+	ListPrices(c *gin.Context)
+	CreatePrice(c *gin.Context)
+}
+
+type pricingController struct {
+	aiManager      *outbound.Manager
+	pricingService service.PricingService
+}
+
+func NewPricingController(aiManager *outbound.Manager, pricingService service.PricingService) PricingController {
+	return &pricingController{
+		aiManager:      aiManager,
+		pricingService: pricingService,
+	}
+}
+
+// ListPrices returns every version of every model price
+func (c *pricingController) ListPrices(ctx *gin.Context) {
+	prices, err := c.pricingService.List(ctx)
+	if err != nil {
+		log.Printf("Failed to list prices: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to list prices",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"prices": prices,
+		"total":  len(prices),
+	})
+}
+
+// CreatePrice adds a new price version for a model. Earlier generations keep
+// the cost computed when they were recorded.
+func (c *pricingController) CreatePrice(ctx *gin.Context) {
+	var request struct {
+		Provider             string     `json:"provider" binding:"required"`
+		Model                string     `json:"model" binding:"required"`
+		PromptPerMillion     *float64   `json:"prompt_usd_per_million" binding:"required,min=0"`
+		CompletionPerMillion *float64   `json:"completion_usd_per_million" binding:"required,min=0"`
+		EffectiveFrom        *time.Time `json:"effective_from"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	if !checkProviderRegistered(ctx, c.aiManager, request.Provider) {
+		return
+	}
+
+	price := &model.ModelPrice{
+		Provider:             request.Provider,
+		Model:                request.Model,
+		PromptPerMillion:     *request.PromptPerMillion,
+		CompletionPerMillion: *request.CompletionPerMillion,
+	}
+	if request.EffectiveFrom != nil {
+		price.EffectiveFrom = *request.EffectiveFrom
+	}
+
+	if err := c.pricingService.Create(ctx, price); err != nil {
+		log.Printf("Failed to create price: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to create price",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(201, gin.H{"price": price})
+}
+
+// applyCost sets a generation's cost from the price in effect now. Generations
+// without a catalogue price, or whose price cannot be loaded, have no cost.
+func applyCost(ctx context.Context, pricingService service.PricingService, generation *model.GenerationHistory) {
+	if pricingService == nil || generation.TokensUsed == 0 {
+		return
+	}
+
+	price, err := pricingService.Price(ctx, generation.Provider, generation.Model, time.Now())
+	if err != nil {
+		log.Printf("Failed to load price: %v", err)
+		return
+	}
+	if price == nil {
+		return
+	}
+
+	// Rows without a prompt/completion split are priced at the blended rate
+	cost := price.BlendedCost(generation.TokensUsed)
+	if generation.PromptTokens > 0 || generation.CompletionTokens > 0 {
+		cost = price.Cost(generation.PromptTokens, generation.CompletionTokens)
+	}
+	generation.CostUSD = &cost
+}
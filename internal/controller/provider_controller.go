@@ -0,0 +1,424 @@
+package controller
+
+import (
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/repository"
+	"ai-service/internal/service"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderController manages the providers table, through which admins switch
+// providers on and off, edit their settings and store their API keys without
+// a redeploy
+type ProviderController interface {
+	ListProviders(c *gin.Context)
+	EnableProvider(c *gin.Context)
+	DisableProvider(c *gin.Context)
+	UpdateProviderConfig(c *gin.Context)
+	SetProviderKey(c *gin.Context)
+	RotateProviderKey(c *gin.Context)
+	DeleteProviderKey(c *gin.Context)
+	GetProviderKeyAudit(c *gin.Context)
+}
+
+type providerController struct {
+	aiManager    *outbound.Manager
+	providerRepo repository.AIProviderRepository
+	// keyService is nil when no master key is configured
+	keyService service.ProviderKeyService
+}
+
+func NewProviderController(aiManager *outbound.Manager, providerRepo repository.AIProviderRepository, keyService service.ProviderKeyService) ProviderController {
+	return &providerController{
+		aiManager:    aiManager,
+		providerRepo: providerRepo,
+		keyService:   keyService,
+	}
+}
+
+type setProviderKeyRequest struct {
+	APIKey string `json:"api_key" binding:"required"`
+}
+
+// ListProviders returns every registered provider with its stored state and
+// whether it is currently serving requests
+func (c *providerController) ListProviders(ctx *gin.Context) {
+	records, err := c.providerRepo.List(ctx)
+	if err != nil {
+		log.Printf("Failed to list providers: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to list providers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rows := make(map[string]*model.ProviderRecord, len(records))
+	for _, record := range records {
+		rows[record.Name] = record
+	}
+
+	definitions := c.aiManager.ProviderDefinitions()
+	providers := make([]gin.H, 0, len(definitions))
+	for _, definition := range definitions {
+		_, err := c.aiManager.GetProvider(definition.ID)
+		provider := gin.H{
+			"id":            string(definition.ID),
+			"name":          definition.Name,
+			"description":   definition.Description,
+			"is_active":     true,
+			"configured":    err == nil,
+			"config":        nil,
+			"config_schema": definition.ConfigSchema,
+		}
+		// Providers without a row are active with no stored config
+		if row, ok := rows[string(definition.ID)]; ok {
+			provider["is_active"] = row.IsActive
+			if len(row.Config) > 0 {
+				provider["config"] = row.Config
+			}
+			provider["has_api_key"] = row.HasAPIKey
+			provider["api_key_updated_at"] = row.APIKeyUpdatedAt
+			provider["updated_at"] = row.UpdatedAt
+		}
+		providers = append(providers, provider)
+	}
+
+	ctx.JSON(200, gin.H{
+		"providers": providers,
+		"total":     len(providers),
+	})
+}
+
+// EnableProvider lets a provider serve requests again
+func (c *providerController) EnableProvider(ctx *gin.Context) {
+	c.setActive(ctx, true)
+}
+
+// DisableProvider stops a provider from serving requests; requests already
+// running finish
+func (c *providerController) DisableProvider(ctx *gin.Context) {
+	c.setActive(ctx, false)
+}
+
+func (c *providerController) setActive(ctx *gin.Context, active bool) {
+	definition, ok := c.providerDefinition(ctx)
+	if !ok {
+		return
+	}
+
+	record, err := c.providerRepo.SetActive(ctx, string(definition.ID), active)
+	if err != nil {
+		log.Printf("Failed to update provider %s: %v", definition.ID, err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to update provider",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	action := "disabled"
+	if active {
+		action = "enabled"
+	}
+	log.Printf("Provider %s %s by %s", definition.ID, action, requestCaller(ctx))
+
+	c.reloadAndRespond(ctx, record)
+}
+
+// UpdateProviderConfig merges settings into the provider's stored config.
+// Keys must be settings of the provider's schema, or models; null removes a
+// key. API keys and other secrets cannot be stored this way.
+func (c *providerController) UpdateProviderConfig(ctx *gin.Context) {
+	definition, ok := c.providerDefinition(ctx)
+	if !ok {
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	if len(patch) == 0 {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": "At least one setting is required"})
+		return
+	}
+
+	names := make([]string, 0, len(patch))
+	for name, value := range patch {
+		if err := validateProviderSetting(&definition, name, value); err != nil {
+			ctx.JSON(400, gin.H{
+				"error":   "Invalid provider setting",
+				"details": err.Error(),
+				"setting": name,
+			})
+			return
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	body, _ := json.Marshal(patch)
+	record, err := c.providerRepo.UpdateConfig(ctx, string(definition.ID), body)
+	if err != nil {
+		log.Printf("Failed to update provider %s config: %v", definition.ID, err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to update provider config",
+			"details": err.Error(),
+		})
+		return
+	}
+	log.Printf("Provider %s settings %s changed by %s", definition.ID, strings.Join(names, ", "), requestCaller(ctx))
+
+	c.reloadAndRespond(ctx, record)
+}
+
+// SetProviderKey encrypts and stores a provider's API key, which then
+// overrides its environment
+func (c *providerController) SetProviderKey(ctx *gin.Context) {
+	definition, ok := c.keyDefinition(ctx)
+	if !ok {
+		return
+	}
+
+	var req setProviderKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	if _, err := c.keyService.SetKey(ctx, string(definition.ID), req.APIKey, requestCaller(ctx)); err != nil {
+		log.Printf("Failed to store API key for %s: %v", definition.ID, err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to store API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.reloadStoredProvider(ctx, definition)
+}
+
+// RotateProviderKey encrypts a provider's stored API key again under a new
+// data key and the current master key, such as after the master key changes
+func (c *providerController) RotateProviderKey(ctx *gin.Context) {
+	definition, ok := c.keyDefinition(ctx)
+	if !ok {
+		return
+	}
+
+	if _, err := c.keyService.RotateKey(ctx, string(definition.ID), requestCaller(ctx)); err != nil {
+		c.storedKeyError(ctx, definition, "rotate", err)
+		return
+	}
+
+	c.reloadStoredProvider(ctx, definition)
+}
+
+// DeleteProviderKey removes a provider's stored API key
+func (c *providerController) DeleteProviderKey(ctx *gin.Context) {
+	definition, ok := c.keyDefinition(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.keyService.DeleteKey(ctx, string(definition.ID), requestCaller(ctx)); err != nil {
+		c.storedKeyError(ctx, definition, "delete", err)
+		return
+	}
+
+	c.reloadStoredProvider(ctx, definition)
+}
+
+// GetProviderKeyAudit lists who changed a provider's stored API key and when
+func (c *providerController) GetProviderKeyAudit(ctx *gin.Context) {
+	definition, ok := c.keyDefinition(ctx)
+	if !ok {
+		return
+	}
+
+	entries, err := c.keyService.Audit(ctx, string(definition.ID))
+	if err != nil {
+		log.Printf("Failed to list API key audit for %s: %v", definition.ID, err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to list API key audit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"provider": definition.ID,
+		"audit":    entries,
+		"total":    len(entries),
+	})
+}
+
+// keyDefinition looks up the provider named in the URL for a key endpoint,
+// writing an error response when keys cannot be stored for it
+func (c *providerController) keyDefinition(ctx *gin.Context) (outbound.ProviderDefinition, bool) {
+	if c.keyService == nil {
+		ctx.JSON(503, gin.H{
+			"error":   "Provider key storage is not configured",
+			"details": "Set PROVIDER_MASTER_KEY or PROVIDER_MASTER_KEY_FILE to store provider API keys",
+		})
+		return outbound.ProviderDefinition{}, false
+	}
+
+	definition, ok := c.providerDefinition(ctx)
+	if !ok {
+		return definition, false
+	}
+	if field, ok := definition.Field(outbound.SettingAPIKey); !ok || !field.Secret {
+		ctx.JSON(400, gin.H{
+			"error":   "Provider has no API key",
+			"details": fmt.Sprintf("Provider '%s' does not take an API key", definition.ID),
+		})
+		return definition, false
+	}
+	return definition, true
+}
+
+// storedKeyError writes the response for a failed change to a stored key
+func (c *providerController) storedKeyError(ctx *gin.Context, definition outbound.ProviderDefinition, action string, err error) {
+	if errors.Is(err, exceptioncode.ErrEmptyResult) {
+		ctx.JSON(404, gin.H{
+			"error":   "API key not found",
+			"details": fmt.Sprintf("No API key is stored for provider '%s'", definition.ID),
+		})
+		return
+	}
+
+	log.Printf("Failed to %s API key for %s: %v", action, definition.ID, err)
+	ctx.JSON(500, gin.H{
+		"error":   fmt.Sprintf("Failed to %s API key", action),
+		"details": err.Error(),
+	})
+}
+
+// reloadStoredProvider reloads providers after a stored key changed and
+// returns the provider's row, which never includes the key
+func (c *providerController) reloadStoredProvider(ctx *gin.Context, definition outbound.ProviderDefinition) {
+	record, err := c.providerRepo.GetByName(ctx, string(definition.ID))
+	if err != nil {
+		log.Printf("Failed to get provider %s: %v", definition.ID, err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to get provider",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.reloadAndRespond(ctx, record)
+}
+
+// validateProviderSetting checks one key of a config update
+func validateProviderSetting(definition *outbound.ProviderDefinition, name string, value json.RawMessage) error {
+	if name == "models" {
+		var models []model.ModelInfo
+		if err := json.Unmarshal(value, &models); err != nil {
+			return fmt.Errorf("models must be a list of models: %v", err)
+		}
+		for _, info := range models {
+			if info.ID == "" {
+				return fmt.Errorf("every model needs an id")
+			}
+		}
+		return nil
+	}
+
+	field, ok := definition.Field(name)
+	if !ok {
+		allowed := []string{"models"}
+		for _, field := range definition.ConfigSchema {
+			if !field.Secret {
+				allowed = append(allowed, field.Name)
+			}
+		}
+		return fmt.Errorf("%s has no setting %q. Supported settings: %s", definition.ID, name, strings.Join(allowed, ", "))
+	}
+	if field.Secret {
+		return fmt.Errorf("%s is a secret and cannot be stored in provider config", name)
+	}
+	if string(value) == "null" {
+		return nil
+	}
+
+	var setting interface{}
+	if err := json.Unmarshal(value, &setting); err != nil {
+		return err
+	}
+	switch setting.(type) {
+	case string, float64, bool:
+	default:
+		return fmt.Errorf("%s must be a string, number or boolean", name)
+	}
+	text := strings.Trim(string(value), `"`)
+
+	switch name {
+	case outbound.SettingMaxTokens:
+		if tokens, err := strconv.Atoi(text); err != nil || tokens <= 0 {
+			return fmt.Errorf("max_tokens must be a positive integer")
+		}
+	case outbound.SettingTimeout:
+		if timeout, err := time.ParseDuration(text); err != nil || timeout <= 0 {
+			return fmt.Errorf("timeout must be a positive duration such as 30s")
+		}
+	}
+	return nil
+}
+
+// providerDefinition looks up the provider named in the URL, writing a 404
+// response and returning ok=false when it is not registered
+func (c *providerController) providerDefinition(ctx *gin.Context) (outbound.ProviderDefinition, bool) {
+	name := ctx.Param("name")
+	definition, ok := c.aiManager.ProviderDefinition(model.AIProvider(name))
+	if !ok {
+		ctx.JSON(404, gin.H{
+			"error":   "Provider not found",
+			"details": fmt.Sprintf("Provider '%s' is not registered. Registered providers: %s", name, registeredProviderIDs(c.aiManager)),
+		})
+	}
+	return definition, ok
+}
+
+// reloadAndRespond applies the stored change to the running providers and
+// returns the updated row with the reload report
+func (c *providerController) reloadAndRespond(ctx *gin.Context, record *model.ProviderRecord) {
+	reload, err := c.aiManager.Reload(ctx)
+	if err != nil {
+		log.Printf("Failed to reload provider configuration: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Provider saved but reloading provider configuration failed",
+			"details": err.Error(),
+		})
+		return
+	}
+	outbound.LogProviderReload(reload)
+
+	ctx.JSON(200, gin.H{
+		"provider": record,
+		"reload":   reload,
+	})
+}
+
+// requestCaller identifies the authenticated caller for audit logs
+func requestCaller(ctx *gin.Context) string {
+	if claims, ok := authentication.ClaimsFromContext(ctx.Request.Context()); ok {
+		return claims.UserID
+	}
+	return "anonymous"
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/service"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"ai-service/internal/util/tokenizer"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuotaController interface {
+	GetQuota(c *gin.Context)
+}
+
+type quotaController struct {
+	quotaService service.QuotaService
+}
+
+func NewQuotaController(quotaService service.QuotaService) QuotaController {
+	return &quotaController{
+		quotaService: quotaService,
+	}
+}
+
+// GetQuota reports the caller's remaining quota and every provider quota
+func (c *quotaController) GetQuota(ctx *gin.Context) {
+	if !c.quotaService.Enabled() {
+		ctx.JSON(200, gin.H{
+			"enabled": false,
+			"quotas":  []model.QuotaStatus{},
+		})
+		return
+	}
+
+	apiKey, _ := authentication.APIKeyFromContext(ctx.Request.Context())
+	statuses, err := c.quotaService.Status(ctx, currentUserID(ctx), apiKey)
+	if err != nil {
+		log.Printf("Failed to load quota status: %v", err)
+		ctx.JSON(500, gin.H{
+			"error":   "Failed to load quota status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if statuses == nil {
+		statuses = []model.QuotaStatus{}
+	}
+	setQuotaHeaders(ctx, statuses)
+	ctx.JSON(200, gin.H{
+		"enabled": true,
+		"quotas":  statuses,
+	})
+}
+
+// enforceQuota checks quotas before generating with providers, writing a 429
+// response and returning false when a quota would be exceeded. tokens is the
+// estimate of each generation, prompt included. Fallback providers are checked
+// as failover reaches them. Quota lookups that fail are logged and let through.
+func enforceQuota(ctx *gin.Context, quotaService service.QuotaService, providers []model.AIProvider, tokens int) bool {
+	if quotaService == nil || !quotaService.Enabled() {
+		return true
+	}
+
+	userID := currentUserID(ctx)
+	apiKey, _ := authentication.APIKeyFromContext(ctx.Request.Context())
+	statuses, err := quotaService.Check(ctx, userID, apiKey, providers, tokens)
+	if err != nil {
+		log.Printf("Failed to check quota: %v", err)
+		return true
+	}
+
+	setQuotaHeaders(ctx, statuses)
+
+	for _, status := range statuses {
+		if !status.Exceeded {
+			continue
+		}
+		ctx.Header("Retry-After", strconv.Itoa(secondsUntil(status.ResetAt)))
+		ctx.JSON(429, gin.H{
+			"error":   "Quota exceeded",
+			"code":    exceptioncode.CodeQuotaLimitReached,
+			"details": fmt.Sprintf("The %s %s quota for %s '%s' is used up (%d of %d), it resets at %s", status.Window, status.Metric, status.Scope, status.Subject, status.Used, status.Limit, status.ResetAt.Format(time.RFC3339)),
+			"quota":   status,
+		})
+		return false
+	}
+
+	ctx.Request = ctx.Request.WithContext(outbound.WithProviderCheck(ctx.Request.Context(), func(checkCtx context.Context, provider model.AIProvider) error {
+		return checkProviderQuota(checkCtx, quotaService, userID, apiKey, provider, tokens)
+	}))
+	return true
+}
+
+// checkProviderQuota returns an error when a fallback provider's quota would
+// be exceeded; the caller's own quota was already checked for the request
+func checkProviderQuota(ctx context.Context, quotaService service.QuotaService, userID string, apiKey *model.APIKey, provider model.AIProvider, tokens int) error {
+	statuses, err := quotaService.Check(ctx, userID, apiKey, []model.AIProvider{provider}, tokens)
+	if err != nil {
+		log.Printf("Failed to check quota: %v", err)
+		return nil
+	}
+	for _, status := range statuses {
+		if status.Exceeded && status.Scope == service.QuotaScopeProvider {
+			return fmt.Errorf("the %s %s quota for provider '%s' is used up", status.Window, status.Metric, status.Subject)
+		}
+	}
+	return nil
+}
+
+// requestTokens estimates the tokens a generation may use: its prompt, counted
+// offline, plus the completion budget
+func requestTokens(aiManager *outbound.Manager, req *model.GenerationRequest) int {
+	modelName := req.Model
+	if modelName == "" {
+		modelName = aiManager.DefaultModel(req.Provider)
+	}
+	return tokenizer.CountRequest(modelName, req) + req.MaxTokens
+}
+
+// setQuotaHeaders reports the tightest token and request quotas, and a
+// warning when any quota has passed its soft limit
+func setQuotaHeaders(ctx *gin.Context, statuses []model.QuotaStatus) {
+	for _, metric := range []string{service.QuotaMetricTokens, service.QuotaMetricRequests} {
+		tightest := tightestQuota(statuses, metric)
+		if tightest == nil {
+			continue
+		}
+
+		suffix := "Tokens"
+		if metric == service.QuotaMetricRequests {
+			suffix = "Requests"
+		}
+		ctx.Header("X-Quota-Limit-"+suffix, strconv.Itoa(tightest.Limit))
+		ctx.Header("X-Quota-Remaining-"+suffix, strconv.Itoa(tightest.Remaining))
+		ctx.Header("X-Quota-Reset-"+suffix, strconv.Itoa(secondsUntil(tightest.ResetAt)))
+	}
+
+	for _, status := range statuses {
+		if status.Warning && !status.Exceeded {
+			warning := fmt.Sprintf("%s %s quota for %s '%s' is %d%% used", status.Window, status.Metric, status.Scope, status.Subject, status.Used*100/status.Limit)
+			log.Printf("Quota warning: %s", warning)
+			ctx.Header("X-Quota-Warning", warning)
+			break
+		}
+	}
+}
+
+// tightestQuota returns the status for metric with the smallest share of its
+// limit remaining
+func tightestQuota(statuses []model.QuotaStatus, metric string) *model.QuotaStatus {
+	var tightest *model.QuotaStatus
+	for i := range statuses {
+		status := &statuses[i]
+		if status.Metric != metric {
+			continue
+		}
+		if tightest == nil || float64(status.Remaining)/float64(status.Limit) < float64(tightest.Remaining)/float64(tightest.Limit) {
+			tightest = status
+		}
+	}
+	return tightest
+}
+
+func secondsUntil(t time.Time) int {
+	return int(math.Ceil(time.Until(t).Seconds()))
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/util/tokenizer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TokenController interface {
+	CountTokens(c *gin.Context)
+}
+
+type tokenController struct {
+	aiManager *outbound.Manager
+}
+
+func NewTokenController(aiManager *outbound.Manager) TokenController {
+	return &tokenController{
+		aiManager: aiManager,
+	}
+}
+
+// CountTokens estimates the prompt tokens of a request offline and reports
+// whether it fits the model's context window
+func (c *tokenController) CountTokens(ctx *gin.Context) {
+	var request struct {
+		Provider  string          `json:"provider"`
+		Model     string          `json:"model"`
+		Prompt    string          `json:"prompt" binding:"required"`
+		SystemMsg string          `json:"system_message"`
+		Messages  []model.Message `json:"messages"`
+		MaxTokens int             `json:"max_tokens" binding:"min=0"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	modelName := request.Model
+	if modelName == "" {
+		modelName = c.aiManager.DefaultModel(model.AIProvider(request.Provider))
+	}
+	if modelName == "" {
+		ctx.JSON(400, gin.H{
+			"error":   "Model is required",
+			"details": "Set model, or a provider with a default model. Supported providers: " + registeredProviderIDs(c.aiManager),
+		})
+		return
+	}
+
+	promptTokens := tokenizer.CountRequest(modelName, &model.GenerationRequest{
+		Prompt:    request.Prompt,
+		SystemMsg: request.SystemMsg,
+		Messages:  request.Messages,
+	})
+	contextWindow := 0
+	if info, ok := c.aiManager.Registry().Match(model.AIProvider(request.Provider), modelName); ok {
+		contextWindow = info.ContextWindow
+	}
+
+	response := gin.H{
+		"model":          modelName,
+		"tokenizer":      tokenizer.FamilyOf(modelName),
+		"prompt_tokens":  promptTokens,
+		"max_tokens":     request.MaxTokens,
+		"total_tokens":   promptTokens + request.MaxTokens,
+		"context_window": contextWindow,
+		"estimated":      true,
+	}
+	// The fit is unknown for models with no known context window
+	if contextWindow > 0 {
+		response["fits"] = promptTokens+request.MaxTokens <= contextWindow
+	}
+
+	ctx.JSON(200, response)
+}
@@ -2,11 +2,14 @@ package controller
 
 import (
 	"ai-service/internal/model"
+	"ai-service/internal/outbound"
 	"ai-service/internal/repository"
 	"ai-service/internal/util/template"
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,50 +23,56 @@ type WebController interface {
 }
 
 type webController struct {
+	aiManager      *outbound.Manager
 	generationRepo repository.GenerationRepository
 }
 
-func NewWebController(generationRepo repository.GenerationRepository) WebController {
+func NewWebController(aiManager *outbound.Manager, generationRepo repository.GenerationRepository) WebController {
 	return &webController{
+		aiManager:      aiManager,
 		generationRepo: generationRepo,
 	}
 }
 
 // Home renders the home page with AI generation interface
 func (c *webController) Home(ctx *gin.Context) {
-	providers := map[string]gin.H{
-		"openai": {
-			"Name":      "OpenAI",
-			"Models":    []string{"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo"},
-			"Strengths": "Advanced language models for text generation",
-			"BestFor":   "Text generation, coding, analysis",
-			"MaxTokens": "4096",
-			"Pricing":   "Pay per token",
-		},
-		"gemini": {
-			"Name":      "Google Gemini",
-			"Models":    []string{"gemini-1.5-flash", "gemini-1.5-pro", "gemini-2.0-flash"},
-			"Strengths": "Multimodal AI with fast response times",
-			"BestFor":   "Multimodal tasks, quick responses",
-			"MaxTokens": "8192",
-			"Pricing":   "Pay per token",
-		},
-		"anthropic": {
-			"Name":      "Anthropic Claude",
-			"Models":    []string{"claude-3-sonnet", "claude-3-opus", "claude-3-haiku"},
-			"Strengths": "Constitutional AI for safe and helpful responses",
-			"BestFor":   "Safe content, detailed analysis",
-			"MaxTokens": "8192",
-			"Pricing":   "Pay per token",
-		},
-	}
-
-	// Create provider models mapping for JavaScript
+	// Providers come from the provider registry, and their models and
+	// output limits from the model registry
+	registry := c.aiManager.ModelCatalog(ctx)
+	now := time.Now()
+	providers := make(map[string]gin.H)
+	providerCards := make([]gin.H, 0)
 	providerModels := make(map[string][]string)
-	for key, provider := range providers {
-		if models, ok := provider["Models"].([]string); ok {
-			providerModels[key] = models
+	for _, definition := range c.aiManager.ProviderDefinitions() {
+		key := string(definition.ID)
+		models := registry.ModelIDs(definition.ID, now)
+		if len(models) == 0 {
+			continue
+		}
+		modelLimits := make(map[string]int)
+		maxTokens := 0
+		for _, info := range registry.Models(definition.ID) {
+			modelLimits[info.ID] = info.MaxOutputTokens
+			if info.MaxOutputTokens > maxTokens {
+				maxTokens = info.MaxOutputTokens
+			}
+		}
+
+		providers[key] = gin.H{
+			"Name":        definition.Name,
+			"Strengths":   definition.Description,
+			"BestFor":     strings.Join(definition.BestFor, ", "),
+			"Pricing":     definition.Pricing,
+			"Models":      models,
+			"ModelLimits": modelLimits,
+			"MaxTokens":   strconv.Itoa(maxTokens),
 		}
+		providerCards = append(providerCards, gin.H{
+			"ID":          key,
+			"Name":        definition.Name,
+			"Description": definition.Description,
+		})
+		providerModels[key] = models
 	}
 
 	// Convert to JSON for JavaScript
@@ -73,6 +82,7 @@ func (c *webController) Home(ctx *gin.Context) {
 	data := gin.H{
 		"Title":              "Home",
 		"Providers":          providers,
+		"ProviderCards":      providerCards,
 		"ProvidersJSON":      string(providersJSON),
 		"ProviderModelsJSON": string(providerModelsJSON),
 	}
@@ -124,16 +134,25 @@ func (c *webController) Stats(ctx *gin.Context) {
 		providerStats = []*model.ProviderStats{}
 	}
 
+	spend, err := c.generationRepo.GetSpend(ctx, startDate, endDate)
+	if err != nil {
+		log.Printf("Failed to load spend: %v", err)
+		spend = &model.SpendReport{}
+	}
+
 	// Calculate totals
-	var totalGenerations, totalTokens int
+	var totalGenerations, totalTokens, totalFailovers int
 	providerStatsMap := make(map[string]gin.H)
 
 	for _, stat := range providerStats {
 		totalGenerations += stat.TotalGenerations
 		totalTokens += stat.TotalTokens
+		totalFailovers += stat.FailoverCount
 		providerStatsMap[stat.Provider] = gin.H{
-			"Count":  stat.TotalGenerations,
-			"Tokens": stat.TotalTokens,
+			"Count":     stat.TotalGenerations,
+			"Tokens":    stat.TotalTokens,
+			"Failovers": stat.FailoverCount,
+			"Cost":      stat.TotalCostUSD,
 		}
 	}
 
@@ -141,6 +160,9 @@ func (c *webController) Stats(ctx *gin.Context) {
 	statsData := gin.H{
 		"TotalGenerations":           totalGenerations,
 		"TotalTokensUsed":            totalTokens,
+		"FailoverCount":              totalFailovers,
+		"TotalCost":                  spend.TotalCostUSD,
+		"Spend":                      spend,
 		"AverageDuration":            0, // TODO: Calculate from actual data
 		"DaysActive":                 30,
 		"ProviderStats":              providerStatsMap,
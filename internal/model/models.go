@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,6 +14,7 @@ const (
 	OpenAI    AIProvider = "openai"
 	Gemini    AIProvider = "gemini"
 	Anthropic AIProvider = "anthropic"
+	Ollama    AIProvider = "ollama"
 )
 
 // GenerationRequest represents the input for AI generation
@@ -23,8 +25,21 @@ type GenerationRequest struct {
 	MaxTokens   int        `json:"max_tokens,omitempty" example:"1000"`
 	Temperature float32    `json:"temperature,omitempty" example:"0.7"`
 	SystemMsg   string     `json:"system_message,omitempty" example:"You are a helpful coding assistant"`
+	Messages    []Message  `json:"messages,omitempty"`
 } // @name GenerationRequest
 
+// Message roles used in multi-turn conversations
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single prior turn replayed to the provider before Prompt
+type Message struct {
+	Role    string `json:"role" example:"user"`
+	Content string `json:"content" example:"What is a goroutine?"`
+} // @name Message
+
 // GenerationResponse represents the AI generation output
 type GenerationResponse struct {
 	ID          string     `json:"id"`
@@ -34,21 +49,92 @@ type GenerationResponse struct {
 	TokensUsed  int        `json:"tokens_used"`
 	GeneratedAt time.Time  `json:"generated_at"`
 	Duration    string     `json:"duration"`
+	// Retries counts upstream calls repeated after a retryable failure
+	Retries int `json:"retries"`
+
+	// Prompt and completion split of TokensUsed
+	TokenUsage
+
+	// Truncated is set when the prompt or history was cut to fit the
+	// model's context window
+	Truncated bool `json:"truncated,omitempty"`
+
+	// FinishReason says why the provider stopped generating, named as in
+	// the OpenAI API; FinishReasonLength means the completion was cut off
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// Set by the manager when the request failed over from another provider
+	RequestedProvider AIProvider        `json:"requested_provider,omitempty"`
+	FailedAttempts    []ProviderAttempt `json:"failed_attempts,omitempty"`
 } // @name GenerationResponse
 
+// Finish reasons reported in GenerationResponse.FinishReason
+const (
+	FinishReasonStop   = "stop"
+	FinishReasonLength = "length"
+)
+
+// TokenUsage splits the tokens used by a generation
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	// Estimated is set when the counts are approximated locally rather than
+	// reported by the provider
+	Estimated bool `json:"tokens_estimated"`
+}
+
+// NewTokenUsage builds a TokenUsage from prompt and completion counts
+func NewTokenUsage(promptTokens, completionTokens int, estimated bool) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		Estimated:        estimated,
+	}
+}
+
+// ProviderAttempt records a provider that failed before another one served the request
+type ProviderAttempt struct {
+	Provider  AIProvider `json:"provider"`
+	Model     string     `json:"model"`
+	Error     string     `json:"error"`
+	Retryable bool       `json:"retryable"`
+	LatencyMs int64      `json:"latency_ms"`
+} // @name ProviderAttempt
+
 // ComparisonRequest for comparing AI providers
 type ComparisonRequest struct {
-	Prompt      string       `json:"prompt" binding:"required"`
-	Providers   []AIProvider `json:"providers" binding:"required"`
-	MaxTokens   int          `json:"max_tokens,omitempty"`
-	Temperature float32      `json:"temperature,omitempty"`
+	Prompt      string                `json:"prompt" binding:"required"`
+	Providers   []AIProvider          `json:"providers" binding:"required,min=1"`
+	Models      map[AIProvider]string `json:"models,omitempty"`
+	MaxTokens   int                   `json:"max_tokens,omitempty"`
+	Temperature float32               `json:"temperature,omitempty"`
+	SystemMsg   string                `json:"system_message,omitempty"`
 } // @name ComparisonRequest
 
+// ComparisonResult holds the outcome of a single provider leg in a comparison
+type ComparisonResult struct {
+	Provider     AIProvider `json:"provider"`
+	Model        string     `json:"model"`
+	Content      string     `json:"content"`
+	TokensUsed   int        `json:"tokens_used"`
+	LatencyMs    int64      `json:"latency_ms"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	GenerationID string     `json:"generation_id,omitempty"`
+	CostUSD      *float64   `json:"cost_usd,omitempty"`
+
+	// Prompt and completion split of TokensUsed
+	TokenUsage
+} // @name ComparisonResult
+
 // ComparisonResponse contains results from multiple providers
 type ComparisonResponse struct {
-	Prompt    string               `json:"prompt"`
-	Results   []GenerationResponse `json:"results"`
-	CreatedAt time.Time            `json:"created_at"`
+	ID        string             `json:"id"`
+	Prompt    string             `json:"prompt"`
+	Results   []ComparisonResult `json:"results"`
+	CreatedAt time.Time          `json:"created_at"`
 } // @name ComparisonResponse
 
 // GenerationHistory stores generation history in database
@@ -65,6 +151,113 @@ type GenerationHistory struct {
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
 	Status       string         `json:"status"`
 	ErrorMessage string         `json:"error_message,omitempty"`
+	ComparisonID string         `json:"comparison_id,omitempty"`
+	UserID       string         `json:"user_id,omitempty"`
+
+	RequestedProvider string            `json:"requested_provider,omitempty"`
+	FailedAttempts    []ProviderAttempt `json:"failed_attempts,omitempty"`
+	// CostUSD is nil when the model has no price in the catalogue
+	CostUSD *float64 `json:"cost_usd,omitempty"`
+
+	// Prompt and completion split of TokensUsed
+	TokenUsage
+}
+
+// Conversation stores a multi-turn chat session in database
+type Conversation struct {
+	ID           string                 `json:"id"`
+	Title        string                 `json:"title"`
+	Provider     string                 `json:"provider"`
+	Model        string                 `json:"model"`
+	SystemMsg    string                 `json:"system_message,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	Messages     []*ConversationMessage `json:"messages,omitempty"`
+	MessageCount int                    `json:"message_count"`
+}
+
+// ConversationMessage stores a single turn of a conversation in database
+type ConversationMessage struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	Role           string    `json:"role"`
+	Content        string    `json:"content"`
+	TokensUsed     int       `json:"tokens_used"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// APIKey is a client API key; the secret itself is never stored
+type APIKey struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	KeyPrefix        string   `json:"key_prefix"`
+	KeyHash          string   `json:"-"`
+	Role             string   `json:"role"`
+	AllowedProviders []string `json:"allowed_providers"`
+	AllowedModels    []string `json:"allowed_models"`
+	IsActive         bool     `json:"is_active"`
+	UsageCount       int64    `json:"usage_count"`
+	// Quota overrides the default caller quota when set
+	Quota      *QuotaLimits `json:"quota,omitempty"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// Allows reports whether the key may call the provider and model. Empty
+// allow lists place no restriction.
+func (k *APIKey) Allows(provider, modelName string) bool {
+	return allowListed(k.AllowedProviders, provider) && allowListed(k.AllowedModels, modelName)
+}
+
+func allowListed(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, item := range allowed {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// QuotaLimits caps tokens and requests per UTC day and calendar month.
+// Zero means unlimited.
+type QuotaLimits struct {
+	DailyTokens     int `json:"daily_tokens,omitempty"`
+	MonthlyTokens   int `json:"monthly_tokens,omitempty"`
+	DailyRequests   int `json:"daily_requests,omitempty"`
+	MonthlyRequests int `json:"monthly_requests,omitempty"`
+}
+
+// IsZero reports whether no limit is set
+func (l QuotaLimits) IsZero() bool {
+	return l == QuotaLimits{}
+}
+
+// Usage is the number of tokens and generations recorded in a window
+type Usage struct {
+	Tokens   int `json:"tokens"`
+	Requests int `json:"requests"`
+}
+
+// QuotaStatus reports usage against one limit
+type QuotaStatus struct {
+	// Scope is "user", "api_key" or "provider"
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+	// Window is "daily" or "monthly"
+	Window string `json:"window"`
+	// Metric is "tokens" or "requests"
+	Metric    string    `json:"metric"`
+	Used      int       `json:"used"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+	Warning   bool      `json:"warning"`
+	Exceeded  bool      `json:"exceeded"`
 }
 
 // ErrorResponse represents error responses
@@ -94,10 +287,130 @@ type AIProviderComparison struct {
 	Available  bool       `json:"available"`
 }
 
+// ProviderRecord is a row of the providers table
+type ProviderRecord struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	IsActive bool   `json:"is_active"`
+	// Config holds provider settings such as default_model and models
+	Config json.RawMessage `json:"config,omitempty"`
+	// HasAPIKey reports whether an encrypted API key is stored
+	HasAPIKey       bool       `json:"has_api_key"`
+	APIKeyUpdatedAt *time.Time `json:"api_key_updated_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// ProviderKey is a provider API key stored with envelope encryption: the key
+// encrypted under a data key, and the data key encrypted under the master key
+type ProviderKey struct {
+	Provider         string    `json:"provider"`
+	EncryptedAPIKey  []byte    `json:"-"`
+	EncryptedDataKey []byte    `json:"-"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Provider key audit actions
+const (
+	ProviderKeySet     = "set"
+	ProviderKeyRotated = "rotated"
+	ProviderKeyDeleted = "deleted"
+)
+
+// ProviderKeyAudit records a change to a stored provider API key. It never
+// holds the key.
+type ProviderKeyAudit struct {
+	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Model capabilities listed in the model registry
+const (
+	CapabilityChat      = "chat"
+	CapabilityStreaming = "streaming"
+	CapabilityVision    = "vision"
+)
+
+// ModelInfo describes one model in the model registry
+type ModelInfo struct {
+	ID       string     `json:"id" yaml:"id"`
+	Provider AIProvider `json:"provider" yaml:"provider"`
+	// Aliases are other names accepted for the model, such as dated IDs
+	Aliases         []string `json:"aliases,omitempty" yaml:"aliases"`
+	ContextWindow   int      `json:"context_window" yaml:"context_window"`
+	MaxOutputTokens int      `json:"max_output_tokens" yaml:"max_output_tokens"`
+	Capabilities    []string `json:"capabilities" yaml:"capabilities"`
+	// Pricing is the list price; billing uses the model_pricing table
+	Pricing *ModelListPrice `json:"pricing,omitempty" yaml:"pricing"`
+	// DeprecatedAt is the YYYY-MM-DD date from which the model is refused
+	DeprecatedAt string `json:"deprecated_at,omitempty" yaml:"deprecated_at"`
+} // @name ModelInfo
+
+// ModelListPrice is a model's list price in USD per million tokens
+type ModelListPrice struct {
+	PromptPerMillion     float64 `json:"prompt_usd_per_million" yaml:"prompt_usd_per_million"`
+	CompletionPerMillion float64 `json:"completion_usd_per_million" yaml:"completion_usd_per_million"`
+}
+
+// Deprecated reports whether the model is retired at the given time
+func (m *ModelInfo) Deprecated(at time.Time) bool {
+	if m.DeprecatedAt == "" {
+		return false
+	}
+	date, err := time.Parse(time.DateOnly, m.DeprecatedAt)
+	return err == nil && !at.Before(date)
+}
+
 type ProviderStats struct {
 	Provider         string  `json:"provider"`
 	TotalGenerations int     `json:"total_generations"`
 	TotalTokens      int     `json:"total_tokens"`
 	AvgDuration      float64 `json:"avg_duration"`
 	ErrorCount       int     `json:"error_count"`
+	FailoverCount    int     `json:"failover_count"`
+	TotalCostUSD     float64 `json:"total_cost_usd"`
+}
+
+// ModelPrice is one version of a model's price in USD per million tokens.
+// A version applies from EffectiveFrom until a newer one takes over, so
+// recorded costs are not changed by later price updates.
+type ModelPrice struct {
+	ID                   string    `json:"id"`
+	Provider             string    `json:"provider"`
+	Model                string    `json:"model"`
+	PromptPerMillion     float64   `json:"prompt_usd_per_million"`
+	CompletionPerMillion float64   `json:"completion_usd_per_million"`
+	EffectiveFrom        time.Time `json:"effective_from"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// Cost returns the price in USD of the given prompt and completion tokens
+func (p *ModelPrice) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.PromptPerMillion + float64(completionTokens)*p.CompletionPerMillion) / 1e6
+}
+
+// BlendedCost prices a token total with no prompt/completion split at the
+// mean of the two rates
+func (p *ModelPrice) BlendedCost(totalTokens int) float64 {
+	return float64(totalTokens) * (p.PromptPerMillion + p.CompletionPerMillion) / 2 / 1e6
+}
+
+// SpendLine is the spend of one provider, model, user or day
+type SpendLine struct {
+	Key         string  `json:"key"`
+	Generations int     `json:"generations"`
+	Tokens      int     `json:"tokens"`
+	CostUSD     float64 `json:"cost_usd"`
+}
+
+// SpendReport breaks spend in a period down by provider, model, user and day
+type SpendReport struct {
+	TotalCostUSD float64     `json:"total_cost_usd"`
+	ByProvider   []SpendLine `json:"by_provider"`
+	ByModel      []SpendLine `json:"by_model"`
+	ByUser       []SpendLine `json:"by_user"`
+	ByDay        []SpendLine `json:"by_day"`
 }
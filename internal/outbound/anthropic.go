@@ -0,0 +1,360 @@
+package outbound
+
+import (
+	"ai-service/internal/model"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	anthropicAPIVersion       = "2023-06-01"
+	anthropicDefaultMaxTokens = 1024
+)
+
+// anthropicModelAliases maps the short model names used by the UI to the
+// dated model IDs accepted by the Messages API
+var anthropicModelAliases = map[string]string{
+	"claude-3-sonnet": "claude-3-sonnet-20240229",
+	"claude-3-opus":   "claude-3-opus-20240229",
+	"claude-3-haiku":  "claude-3-haiku-20240307",
+}
+
+type AnthropicProvider struct {
+	apiKey       string
+	baseURL      string
+	defaultModel string
+	client       *http.Client
+}
+
+func NewAnthropicProvider(apiKey, baseURL, defaultModel string) *AnthropicProvider {
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com/v1"
+	}
+	if defaultModel == "" {
+		defaultModel = "claude-3-sonnet-20240229"
+	}
+
+	return &AnthropicProvider{
+		apiKey:       apiKey,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		defaultModel: defaultModel,
+		client:       newHTTPClient(30*time.Second, DefaultRetryPolicy()),
+	}
+}
+
+// SetRetryPolicy replaces the retry policy used for API calls
+func (p *AnthropicProvider) SetRetryPolicy(policy RetryPolicy) {
+	p.client = newHTTPClient(p.client.Timeout, policy)
+}
+
+func (p *AnthropicProvider) Generate(ctx context.Context, req *model.GenerationRequest) (*model.GenerationResponse, error) {
+	startTime := time.Now()
+	ctx, retries := withRetryCounter(ctx)
+
+	payload, modelName := p.buildPayload(req)
+
+	httpReq, err := p.newRequest(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make request
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		return nil, anthropicAPIError(resp, body)
+	}
+
+	// Parse response
+	var anthropicResp struct {
+		ID      string `json:"id"`
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// Extract content
+	var content string
+	for _, block := range anthropicResp.Content {
+		if block.Type == "text" {
+			content += block.Text
+		}
+	}
+
+	if content == "" {
+		return nil, fmt.Errorf("no response from Anthropic")
+	}
+
+	duration := time.Since(startTime)
+
+	if anthropicResp.Model != "" {
+		modelName = anthropicResp.Model
+	}
+
+	return &model.GenerationResponse{
+		ID:           fmt.Sprintf("anthropic-%d", time.Now().UnixNano()),
+		Provider:     model.Anthropic,
+		Model:        modelName,
+		Content:      content,
+		TokensUsed:   anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens,
+		GeneratedAt:  time.Now(),
+		Duration:     duration.String(),
+		Retries:      int(retries.Load()),
+		TokenUsage:   model.NewTokenUsage(anthropicResp.Usage.InputTokens, anthropicResp.Usage.OutputTokens, false),
+		FinishReason: anthropicFinishReason(anthropicResp.StopReason),
+	}, nil
+}
+
+// GenerateStream generates content using the Messages API stream option
+func (p *AnthropicProvider) GenerateStream(ctx context.Context, req *model.GenerationRequest, onChunk func(chunk string) error) (*model.GenerationResponse, error) {
+	startTime := time.Now()
+	ctx, retries := withRetryCounter(ctx)
+
+	payload, modelName := p.buildPayload(req)
+	payload["stream"] = true
+
+	httpReq, err := p.newRequest(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	// Streams can outlive the client timeout, so rely on ctx for cancellation
+	streamClient := *p.client
+	streamClient.Timeout = 0
+
+	// Make request
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, anthropicAPIError(resp, body)
+	}
+
+	var content strings.Builder
+	var inputTokens, outputTokens int
+	var stopReason string
+
+	err = readSSE(resp.Body, func(_, data string) error {
+		var streamEvent struct {
+			Type    string `json:"type"`
+			Message struct {
+				Model string `json:"model"`
+				Usage struct {
+					InputTokens int `json:"input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+			Delta struct {
+				Type       string `json:"type"`
+				Text       string `json:"text"`
+				StopReason string `json:"stop_reason"`
+			} `json:"delta"`
+			Usage struct {
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &streamEvent); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		switch streamEvent.Type {
+		case "message_start":
+			inputTokens = streamEvent.Message.Usage.InputTokens
+			if streamEvent.Message.Model != "" {
+				modelName = streamEvent.Message.Model
+			}
+		case "content_block_delta":
+			if streamEvent.Delta.Type != "text_delta" || streamEvent.Delta.Text == "" {
+				return nil
+			}
+			content.WriteString(streamEvent.Delta.Text)
+			return onChunk(streamEvent.Delta.Text)
+		case "message_delta":
+			outputTokens = streamEvent.Usage.OutputTokens
+			stopReason = streamEvent.Delta.StopReason
+		case "message_stop":
+			return io.EOF
+		case "error":
+			return fmt.Errorf("%s: %s", streamEvent.Error.Type, streamEvent.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic stream error: %w", err)
+	}
+
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no response from Anthropic")
+	}
+
+	duration := time.Since(startTime)
+
+	return &model.GenerationResponse{
+		ID:           fmt.Sprintf("anthropic-%d", time.Now().UnixNano()),
+		Provider:     model.Anthropic,
+		Model:        modelName,
+		Content:      content.String(),
+		TokensUsed:   inputTokens + outputTokens,
+		GeneratedAt:  time.Now(),
+		Duration:     duration.String(),
+		Retries:      int(retries.Load()),
+		TokenUsage:   model.NewTokenUsage(inputTokens, outputTokens, false),
+		FinishReason: anthropicFinishReason(stopReason),
+	}, nil
+}
+
+// anthropicFinishReason maps a Messages API stop_reason to its OpenAI name
+func anthropicFinishReason(stopReason string) string {
+	switch stopReason {
+	case "":
+		return ""
+	case "max_tokens":
+		return model.FinishReasonLength
+	}
+	return model.FinishReasonStop
+}
+
+// buildPayload prepares the Messages API request body and resolves the model name
+func (p *AnthropicProvider) buildPayload(req *model.GenerationRequest) (map[string]interface{}, string) {
+	// Set default model if not specified
+	modelName := req.Model
+	if modelName == "" {
+		modelName = p.defaultModel
+	}
+	if alias, ok := anthropicModelAliases[modelName]; ok {
+		modelName = alias
+	}
+
+	// max_tokens is required by the Messages API
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = anthropicDefaultMaxTokens
+	}
+
+	// Prior turns followed by the new prompt
+	var messages []map[string]string
+	for _, message := range req.Messages {
+		messages = append(messages, map[string]string{
+			"role":    message.Role,
+			"content": message.Content,
+		})
+	}
+	messages = append(messages, map[string]string{
+		"role":    "user",
+		"content": req.Prompt,
+	})
+
+	// Prepare request payload
+	payload := map[string]interface{}{
+		"model":      modelName,
+		"max_tokens": maxTokens,
+		"messages":   messages,
+	}
+
+	if req.Temperature > 0 {
+		payload["temperature"] = req.Temperature
+	}
+
+	if req.SystemMsg != "" {
+		// System prompts are a top-level field, not a message role
+		payload["system"] = req.SystemMsg
+	}
+
+	return payload, modelName
+}
+
+// newRequest creates an authenticated Messages API request
+func (p *AnthropicProvider) newRequest(ctx context.Context, payload map[string]interface{}) (*http.Request, error) {
+	// Marshal payload to JSON
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/messages", bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	return httpReq, nil
+}
+
+// anthropicAPIError builds an error from a non-200 Messages API response
+func anthropicAPIError(resp *http.Response, body []byte) error {
+	var errResp struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error.Message != "" {
+		return newProviderError("Anthropic", resp, errResp.Error.Type+": "+errResp.Error.Message)
+	}
+	return newProviderError("Anthropic", resp, string(body))
+}
+
+func (p *AnthropicProvider) GetName() string {
+	return "Anthropic Claude"
+}
+
+func (p *AnthropicProvider) IsAvailable() bool {
+	return p.apiKey != ""
+}
+
+func (p *AnthropicProvider) ValidateRequest(req *model.GenerationRequest) error {
+	if req.Prompt == "" {
+		return fmt.Errorf("prompt is required")
+	}
+
+	if req.MaxTokens > 8192 {
+		return fmt.Errorf("max_tokens cannot exceed 8192 for Anthropic models")
+	}
+
+	if req.Temperature < 0 || req.Temperature > 1 {
+		return fmt.Errorf("temperature must be between 0 and 1 for Anthropic")
+	}
+
+	return nil
+}
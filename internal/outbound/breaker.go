@@ -0,0 +1,156 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a provider circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed lets every call through
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen rejects calls until the open timeout has passed
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a limited number of trial calls through
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// ErrCircuitOpen is returned when a provider's circuit breaker rejects a call
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerSettings controls when a circuit breaker opens and recovers
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before allowing trial calls
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is the number of concurrent trial calls when half-open
+	HalfOpenMaxRequests int
+}
+
+// DefaultBreakerSettings is used for any setting that is not configured
+func DefaultBreakerSettings() BreakerSettings {
+	return BreakerSettings{
+		FailureThreshold:    5,
+		OpenTimeout:         30 * time.Second,
+		HalfOpenMaxRequests: 1,
+	}
+}
+
+// BreakerStatus is a point-in-time view of a circuit breaker
+type BreakerStatus struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	LastError           string       `json:"last_error,omitempty"`
+}
+
+// CircuitBreaker stops calls to a provider after repeated retryable failures
+// and lets trial calls through once the open timeout has passed
+type CircuitBreaker struct {
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu               sync.Mutex
+	state            BreakerState
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	lastError        string
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(settings BreakerSettings) *CircuitBreaker {
+	return &CircuitBreaker{
+		settings: settings,
+		now:      time.Now,
+		state:    BreakerClosed,
+	}
+}
+
+// Allow reports whether a call may proceed, returning ErrCircuitOpen when it
+// may not. Every allowed call must be followed by Record.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		if b.now().Sub(b.openedAt) < b.settings.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.halfOpenInFlight = 0
+	}
+
+	if b.state == BreakerHalfOpen {
+		if b.halfOpenInFlight >= b.settings.HalfOpenMaxRequests {
+			return ErrCircuitOpen
+		}
+		b.halfOpenInFlight++
+	}
+
+	return nil
+}
+
+// Record reports the outcome of an allowed call. Only retryable errors count
+// as failures; a fatal error such as a bad request still shows the provider
+// is reachable.
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen && b.halfOpenInFlight > 0 {
+		b.halfOpenInFlight--
+	}
+
+	// A caller that gave up tells us nothing about the provider
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if err == nil || !IsRetryable(err) {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == BreakerHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Status returns the current state of the breaker
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == BreakerOpen && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		// The next call will be let through as a trial
+		state = BreakerHalfOpen
+	}
+
+	status := BreakerStatus{
+		State:               state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if !b.openedAt.IsZero() && state != BreakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+// circuitOpenError names the provider whose breaker rejected a call
+func circuitOpenError(provider string) error {
+	return fmt.Errorf("provider %s unavailable: %w", provider, ErrCircuitOpen)
+}
@@ -0,0 +1,229 @@
+package outbound
+
+import (
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+)
+
+// Built-in providers, registered in the order they are listed
+func init() {
+	RegisterProvider(ProviderDefinition{
+		ID:          model.OpenAI,
+		Name:        "OpenAI",
+		Description: "Advanced language models for text generation",
+		Strengths: []string{
+			"Excellent general knowledge",
+			"Strong reasoning capabilities",
+			"Good code generation",
+			"Wide range of models",
+			"Reliable API",
+		},
+		Weaknesses: []string{
+			"Can be expensive for high usage",
+			"Knowledge cutoff limitations",
+			"Rate limiting on free tier",
+		},
+		BestFor: []string{
+			"General text generation",
+			"Code completion and debugging",
+			"Creative writing",
+			"Question answering",
+		},
+		Pricing:      "Pay per token (~$0.002/1K tokens for GPT-3.5)",
+		ConfigSchema: openAISchema("OPENAI_", true),
+		Settings: func(cfg *config.Config) ProviderSettings {
+			openAIConfig := cfg.AIProviders.OpenAI
+			return ProviderSettings{
+				SettingAPIKey:       openAIConfig.APIKey,
+				SettingBaseURL:      openAIConfig.BaseURL,
+				SettingDefaultModel: openAIConfig.DefaultModel,
+				SettingAPIType:      openAIConfig.APIType,
+				SettingAPIVersion:   openAIConfig.APIVersion,
+			}
+		},
+		New: openAIFactory(model.OpenAI, "OpenAI"),
+	})
+
+	RegisterProvider(ProviderDefinition{
+		ID:          model.Gemini,
+		Name:        "Google Gemini",
+		Description: "Google's multimodal AI model",
+		Strengths: []string{
+			"Multimodal capabilities",
+			"Large context window",
+			"Good at reasoning tasks",
+			"Free tier available",
+			"Fast response times",
+		},
+		Weaknesses: []string{
+			"Less mature than OpenAI",
+			"Limited third-party integrations",
+			"Newer model with less community support",
+		},
+		BestFor: []string{
+			"Multimodal tasks",
+			"Long document analysis",
+			"Research and analysis",
+			"Cost-effective solutions",
+		},
+		Pricing: "Free tier available, pay per token for pro usage",
+		ConfigSchema: []ConfigField{
+			{Name: SettingAPIKey, Env: "GEMINI_API_KEY", Description: "Google AI Studio API key", Required: true, Secret: true},
+			{Name: SettingDefaultModel, Env: "GEMINI_DEFAULT_MODEL", Description: "Model used when a request names none", Default: "gemini-1.5-flash"},
+		},
+		Settings: func(cfg *config.Config) ProviderSettings {
+			return ProviderSettings{
+				SettingAPIKey:       cfg.AIProviders.Gemini.APIKey,
+				SettingDefaultModel: cfg.AIProviders.Gemini.DefaultModel,
+			}
+		},
+		New: func(settings ProviderSettings, retryPolicy RetryPolicy) (Provider, error) {
+			return NewGeminiProvider(settings[SettingAPIKey], settings[SettingDefaultModel]), nil
+		},
+	})
+
+	RegisterProvider(ProviderDefinition{
+		ID:          model.Anthropic,
+		Name:        "Anthropic Claude",
+		Description: "Constitutional AI for safe and helpful responses",
+		Strengths: []string{
+			"Strong safety focus",
+			"Excellent at analysis",
+			"Good refusal mechanisms",
+			"Thoughtful responses",
+		},
+		Weaknesses: []string{
+			"More conservative responses",
+			"Limited availability",
+			"Higher cost",
+		},
+		BestFor: []string{
+			"Safety-critical applications",
+			"Research and analysis",
+			"Ethical AI use cases",
+		},
+		Pricing: "Pay per token (premium pricing)",
+		ConfigSchema: []ConfigField{
+			{Name: SettingAPIKey, Env: "ANTHROPIC_API_KEY", Description: "Anthropic API key", Required: true, Secret: true},
+			{Name: SettingBaseURL, Env: "ANTHROPIC_BASE_URL", Description: "Messages API base URL", Default: "https://api.anthropic.com/v1"},
+			{Name: SettingDefaultModel, Env: "ANTHROPIC_DEFAULT_MODEL", Description: "Model used when a request names none", Default: "claude-3-sonnet-20240229"},
+		},
+		Settings: func(cfg *config.Config) ProviderSettings {
+			return ProviderSettings{
+				SettingAPIKey:       cfg.AIProviders.Anthropic.APIKey,
+				SettingBaseURL:      cfg.AIProviders.Anthropic.BaseURL,
+				SettingDefaultModel: cfg.AIProviders.Anthropic.DefaultModel,
+			}
+		},
+		New: func(settings ProviderSettings, retryPolicy RetryPolicy) (Provider, error) {
+			provider := NewAnthropicProvider(settings[SettingAPIKey], settings[SettingBaseURL], settings[SettingDefaultModel])
+			provider.SetRetryPolicy(retryPolicy)
+			return provider, nil
+		},
+	})
+
+	RegisterProvider(ProviderDefinition{
+		ID:          model.Ollama,
+		Name:        "Ollama",
+		Description: "Local models served by an Ollama daemon",
+		Strengths: []string{
+			"Data never leaves the machine",
+			"No per-token cost",
+			"Works offline",
+		},
+		Weaknesses: []string{
+			"Limited by local hardware",
+			"Smaller models than hosted APIs",
+		},
+		BestFor: []string{
+			"Offline development",
+			"Sensitive data",
+		},
+		Pricing: "Free, runs on your hardware",
+		ConfigSchema: []ConfigField{
+			{Name: SettingBaseURL, Env: "OLLAMA_BASE_URL", Description: "Ollama daemon URL; the provider is enabled when set", Required: true},
+			{Name: SettingDefaultModel, Env: "OLLAMA_DEFAULT_MODEL", Description: "Model used when a request names none", Default: "llama3"},
+		},
+		Settings: func(cfg *config.Config) ProviderSettings {
+			return ProviderSettings{
+				SettingBaseURL:      cfg.AIProviders.Ollama.BaseURL,
+				SettingDefaultModel: cfg.AIProviders.Ollama.DefaultModel,
+			}
+		},
+		New: func(settings ProviderSettings, retryPolicy RetryPolicy) (Provider, error) {
+			provider := NewOllamaProvider(settings[SettingBaseURL], settings[SettingDefaultModel])
+			provider.SetRetryPolicy(retryPolicy)
+			return provider, nil
+		},
+	})
+}
+
+// openAISchema is the configuration schema of the OpenAI API and of
+// OpenAI-compatible backends, which need no API key
+func openAISchema(envPrefix string, official bool) []ConfigField {
+	env := func(name string) string {
+		if envPrefix == "" {
+			return ""
+		}
+		return envPrefix + name
+	}
+
+	baseURL := ConfigField{Name: SettingBaseURL, Env: env("BASE_URL"), Description: "Chat completions API base URL", Required: !official}
+	defaultModel := ConfigField{Name: SettingDefaultModel, Env: env("DEFAULT_MODEL"), Description: "Model used when a request names none"}
+	if official {
+		baseURL.Default = openAIDefaultBaseURL
+		defaultModel.Default = "gpt-3.5-turbo"
+	}
+
+	return []ConfigField{
+		{Name: SettingAPIKey, Env: env("API_KEY"), Description: "API key, sent as a bearer token or as api-key for Azure", Required: official, Secret: true},
+		baseURL,
+		defaultModel,
+		{Name: SettingAPIType, Env: env("API_TYPE"), Description: "openai, or azure to address models as deployments", Default: config.OpenAIAPITypeOpenAI},
+		{Name: SettingAPIVersion, Env: env("API_VERSION"), Description: "Azure OpenAI API version"},
+	}
+}
+
+// openAIFactory returns a factory for an OpenAI API provider with the given
+// ID and display name
+func openAIFactory(id model.AIProvider, name string) func(ProviderSettings, RetryPolicy) (Provider, error) {
+	return func(settings ProviderSettings, retryPolicy RetryPolicy) (Provider, error) {
+		provider := NewOpenAICompatibleProvider(OpenAIOptions{
+			Provider:        id,
+			Name:            name,
+			APIKey:          settings[SettingAPIKey],
+			BaseURL:         settings[SettingBaseURL],
+			DefaultModel:    settings[SettingDefaultModel],
+			AzureAPIVersion: azureAPIVersion(settings[SettingAPIType], settings[SettingAPIVersion]),
+		})
+		provider.SetRetryPolicy(retryPolicy)
+		return provider, nil
+	}
+}
+
+// openAICompatibleDefinition describes a named OpenAI-compatible backend from
+// OPENAI_COMPATIBLE_BACKENDS as a provider of its own
+func openAICompatibleDefinition(backend config.OpenAICompatibleConfig) ProviderDefinition {
+	id := model.AIProvider(backend.Name)
+	name := backend.DisplayName
+	if name == "" {
+		name = backend.Name
+	}
+
+	return ProviderDefinition{
+		ID:           id,
+		Name:         name,
+		Description:  "OpenAI-compatible backend at " + backend.BaseURL,
+		ConfigSchema: withCommonFields(openAISchema("", false)),
+		Settings: func(cfg *config.Config) ProviderSettings {
+			return ProviderSettings{
+				SettingAPIKey:       backend.APIKey,
+				SettingBaseURL:      backend.BaseURL,
+				SettingDefaultModel: backend.DefaultModel,
+				SettingAPIType:      backend.APIType,
+				SettingAPIVersion:   backend.APIVersion,
+			}
+		},
+		New: openAIFactory(id, name),
+	}
+}
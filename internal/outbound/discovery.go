@@ -0,0 +1,252 @@
+package outbound
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"ai-service/internal/model"
+)
+
+// discoveryTimeout bounds a single provider's list-models call
+const discoveryTimeout = 15 * time.Second
+
+// discoveredModels is the cached result of a provider's list-models call
+type discoveredModels struct {
+	models      []model.ModelInfo
+	refreshedAt time.Time
+	err         error
+}
+
+// ModelRefresh reports the outcome of refreshing one provider's models
+type ModelRefresh struct {
+	Provider    model.AIProvider `json:"provider"`
+	Models      int              `json:"models"`
+	Added       []string         `json:"added"`
+	Removed     []string         `json:"removed"`
+	RefreshedAt time.Time        `json:"refreshed_at"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// WithModels returns a copy of the registry with the provider's models replaced
+func (r *Registry) WithModels(provider model.AIProvider, models []model.ModelInfo) *Registry {
+	merged := &Registry{models: make(map[model.AIProvider][]model.ModelInfo, len(r.models)+1)}
+	for p, providerModels := range r.models {
+		merged.models[p] = providerModels
+	}
+	merged.models[provider] = models
+	return merged
+}
+
+// WithDiscovered returns a copy of the registry in which the provider's models
+// are the ones its API reported. Known models keep their registry entry, in
+// registry order; unknown ones follow sorted by ID, inheriting the limits and
+// list price of a base model that prefixes their name.
+func (r *Registry) WithDiscovered(provider model.AIProvider, discovered []model.ModelInfo) *Registry {
+	listed := make(map[string]bool, len(discovered))
+	for _, info := range discovered {
+		listed[info.ID] = true
+	}
+
+	var models []model.ModelInfo
+	for _, info := range r.models[provider] {
+		if listed[info.ID] {
+			models = append(models, info)
+			continue
+		}
+		for _, alias := range info.Aliases {
+			if listed[alias] {
+				models = append(models, info)
+				break
+			}
+		}
+	}
+
+	var unknown []model.ModelInfo
+	for _, info := range discovered {
+		if _, ok := r.Get(provider, info.ID); ok {
+			continue
+		}
+		info.Provider = provider
+		if base, ok := r.Match(provider, info.ID); ok {
+			if info.ContextWindow == 0 {
+				info.ContextWindow = base.ContextWindow
+			}
+			if info.MaxOutputTokens == 0 {
+				info.MaxOutputTokens = base.MaxOutputTokens
+			}
+			if len(info.Capabilities) == 0 {
+				info.Capabilities = base.Capabilities
+			}
+			if info.Pricing == nil {
+				info.Pricing = base.Pricing
+			}
+		}
+		unknown = append(unknown, info)
+	}
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].ID < unknown[j].ID
+	})
+
+	return r.WithModels(provider, append(models, unknown...))
+}
+
+// ModelCatalog returns the model registry after refreshing the models of any
+// provider whose discovered list is older than the discovery TTL. Refresh
+// failures are logged and the previous list is kept.
+func (m *Manager) ModelCatalog(ctx context.Context) *Registry {
+	for _, refresh := range m.refreshModels(ctx, false) {
+		if refresh.Error != "" {
+			log.Printf("Failed to discover %s models: %s", refresh.Provider, refresh.Error)
+		}
+	}
+	return m.Registry()
+}
+
+// RefreshModels lists the models of every provider that supports discovery,
+// regardless of the cache age, and reports what changed
+func (m *Manager) RefreshModels(ctx context.Context) []ModelRefresh {
+	return m.refreshModels(ctx, true)
+}
+
+// refreshModels calls ListModels on each provider whose cached list is stale,
+// or on all of them when force is set, and rebuilds the catalogue. Providers
+// are listed concurrently without holding m.mu; a stale list already being
+// refreshed by another caller is served as is unless force is set.
+func (m *Manager) refreshModels(ctx context.Context, force bool) []ModelRefresh {
+	type listing struct {
+		provider Provider
+		lister   ModelLister
+		owned    bool
+		result   discoveredModels
+	}
+
+	m.mu.Lock()
+	listings := make(map[model.AIProvider]*listing)
+	for providerType, provider := range m.providers {
+		lister, ok := provider.(ModelLister)
+		if !ok {
+			continue
+		}
+		cached, hasCache := m.discovered[providerType]
+		expired := !hasCache || time.Since(cached.refreshedAt) >= m.discoveryTTL()
+		// Providers without registry models, such as Ollama, are unusable
+		// until listed, so they are discovered even with discovery disabled
+		needed := m.config.ModelDiscovery.Enabled || len(m.catalog.Models(providerType)) == 0
+		inFlight := m.refreshing[providerType]
+		if force || (expired && needed && !inFlight) {
+			listings[providerType] = &listing{provider: provider, lister: lister, owned: !inFlight}
+			m.refreshing[providerType] = true
+		}
+	}
+	m.mu.Unlock()
+
+	if len(listings) == 0 {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for _, l := range listings {
+		wg.Add(1)
+		go func(l *listing) {
+			defer wg.Done()
+			listCtx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+			defer cancel()
+			models, err := l.lister.ListModels(listCtx)
+			l.result = discoveredModels{models: models, refreshedAt: time.Now(), err: err}
+		}(l)
+	}
+	wg.Wait()
+
+	refreshes := make([]ModelRefresh, 0, len(listings))
+	m.mu.Lock()
+	before := m.catalog
+	for providerType, l := range listings {
+		if l.owned {
+			delete(m.refreshing, providerType)
+		}
+		refresh := ModelRefresh{Provider: providerType, RefreshedAt: l.result.refreshedAt}
+		if l.result.err != nil {
+			refresh.Error = l.result.err.Error()
+		}
+		refreshes = append(refreshes, refresh)
+
+		// Drop lists from an instance a reload replaced while it was listed
+		if m.providers[providerType] != l.provider {
+			continue
+		}
+		result := l.result
+		// Keep the last good list when a refresh fails or comes back empty
+		if previous, ok := m.discovered[providerType]; ok && (result.err != nil || len(result.models) == 0) {
+			result.models = previous.models
+		}
+		m.discovered[providerType] = result
+	}
+	m.catalog = m.buildCatalog()
+	after := m.catalog
+	m.mu.Unlock()
+
+	for i := range refreshes {
+		refresh := &refreshes[i]
+		refresh.Models = len(after.Models(refresh.Provider))
+		refresh.Added, refresh.Removed = diffModelIDs(before.Models(refresh.Provider), after.Models(refresh.Provider))
+	}
+	sort.Slice(refreshes, func(i, j int) bool {
+		return refreshes[i].Provider < refreshes[j].Provider
+	})
+
+	return refreshes
+}
+
+// buildCatalog merges the models configured for compatible backends the
+// registry has no entries for, then the discovered models, into the registry;
+// the caller must hold m.mu
+func (m *Manager) buildCatalog() *Registry {
+	catalog := m.registry
+	for providerType, models := range m.configuredModels {
+		if len(catalog.Models(providerType)) == 0 {
+			catalog = catalog.WithModels(providerType, models)
+		}
+	}
+	for providerType, discovered := range m.discovered {
+		if len(discovered.models) > 0 {
+			catalog = catalog.WithDiscovered(providerType, discovered.models)
+		}
+	}
+	return catalog
+}
+
+// discoveryTTL returns how long discovered models are kept before being listed again
+func (m *Manager) discoveryTTL() time.Duration {
+	if m.config.ModelDiscovery.TTL > 0 {
+		return m.config.ModelDiscovery.TTL
+	}
+	return time.Hour
+}
+
+// diffModelIDs returns the IDs present only in after and only in before
+func diffModelIDs(before, after []model.ModelInfo) ([]string, []string) {
+	inBefore := make(map[string]bool, len(before))
+	for _, info := range before {
+		inBefore[info.ID] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, info := range after {
+		inAfter[info.ID] = true
+	}
+
+	added, removed := []string{}, []string{}
+	for _, info := range after {
+		if !inBefore[info.ID] {
+			added = append(added, info.ID)
+		}
+	}
+	for _, info := range before {
+		if !inAfter[info.ID] {
+			removed = append(removed, info.ID)
+		}
+	}
+	return added, removed
+}
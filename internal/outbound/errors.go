@@ -0,0 +1,90 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errBodyNotReplayable is returned when a request needs retrying but its body
+// cannot be read a second time
+var errBodyNotReplayable = errors.New("request body cannot be replayed for retry")
+
+// ProviderError is returned when a provider API answers with a non-200 status
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s API error: %s - %s", e.Provider, e.Status, e.Message)
+}
+
+// newProviderError builds a ProviderError from an HTTP response status
+func newProviderError(provider string, resp *http.Response, message string) *ProviderError {
+	return &ProviderError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Message:    message,
+	}
+}
+
+// IsRetryable reports whether a failed generation may succeed if sent again,
+// either to the same provider later or to another provider. Rate limits,
+// timeouts, server errors and network failures are retryable; bad requests,
+// authentication failures and caller cancellation are fatal.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// The caller gave up, so nobody is waiting for a retry
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Another provider may still be healthy
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return isRetryableStatus(providerErr.StatusCode)
+	}
+
+	// Gemini errors carry a gRPC status
+	if grpcStatus, ok := status.FromError(err); ok && grpcStatus.Code() != codes.Unknown {
+		switch grpcStatus.Code() {
+		case codes.ResourceExhausted, codes.Unavailable, codes.Internal, codes.DeadlineExceeded, codes.Aborted:
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isRetryableStatus reports whether an HTTP status code is worth retrying
+func isRetryableStatus(statusCode int) bool {
+	switch {
+	case statusCode == http.StatusTooManyRequests, statusCode == http.StatusRequestTimeout:
+		return true
+	case statusCode >= 500:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ai-service/internal/model"
+	"ai-service/internal/util/authentication"
+)
+
+// defaultModelEquivalents groups roughly interchangeable models across
+// providers. It is used when FAILOVER_MODEL_MAP is not set.
+var defaultModelEquivalents = []map[string]string{
+	{"openai": "gpt-4", "gemini": "gemini-1.5-pro", "anthropic": "claude-3-opus"},
+	{"openai": "gpt-4-turbo", "gemini": "gemini-1.5-pro", "anthropic": "claude-3-sonnet"},
+	{"openai": "gpt-4-turbo", "gemini": "gemini-2.0-flash", "anthropic": "claude-3-sonnet"},
+	{"openai": "gpt-3.5-turbo", "gemini": "gemini-1.5-flash", "anthropic": "claude-3-haiku"},
+}
+
+// FailoverError is returned when the requested provider and every fallback
+// provider failed. Attempts lists each failure in the order it was tried.
+type FailoverError struct {
+	Attempts []model.ProviderAttempt
+	Err      error
+}
+
+func (e *FailoverError) Error() string {
+	failures := make([]string, len(e.Attempts))
+	for i, attempt := range e.Attempts {
+		failures[i] = fmt.Sprintf("%s: %s", attempt.Provider, attempt.Error)
+	}
+	return fmt.Sprintf("all providers failed: %s", strings.Join(failures, "; "))
+}
+
+func (e *FailoverError) Unwrap() error {
+	return e.Err
+}
+
+// ProviderCheck decides whether a fallback provider may serve a request,
+// returning an error to skip it, e.g. when the provider is over quota
+type ProviderCheck func(ctx context.Context, provider model.AIProvider) error
+
+type providerCheckContextKey struct{}
+
+// WithProviderCheck returns a copy of ctx whose generations only fail over
+// to providers check accepts
+func WithProviderCheck(ctx context.Context, check ProviderCheck) context.Context {
+	return context.WithValue(ctx, providerCheckContextKey{}, check)
+}
+
+// providerCheckFromContext returns the check stored by WithProviderCheck
+func providerCheckFromContext(ctx context.Context) (ProviderCheck, bool) {
+	check, ok := ctx.Value(providerCheckContextKey{}).(ProviderCheck)
+	return check, ok && check != nil
+}
+
+// generateFunc performs one generation attempt against a resolved provider
+type generateFunc func(ctx context.Context, provider Provider, req *model.GenerationRequest) (*model.GenerationResponse, error)
+
+// generateWithFailover tries the requested provider and, when failover is
+// enabled and the error is retryable, each provider in the failover chain.
+// canFailover, when set, is consulted before moving on so streams that have
+// already emitted output are not restarted on another provider.
+func (m *Manager) generateWithFailover(ctx context.Context, req *model.GenerationRequest, generate generateFunc, canFailover func() bool) (*model.GenerationResponse, error) {
+	provider, err := m.resolveProvider(req)
+	if err != nil {
+		return nil, err
+	}
+	attemptReq, truncated, err := m.preflight(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var attempts []model.ProviderAttempt
+	candidates := m.failoverChain(req.Provider)
+	apiKey, scoped := authentication.APIKeyFromContext(ctx)
+	check, checked := providerCheckFromContext(ctx)
+
+	for {
+		startTime := time.Now()
+		resp, err := m.callProvider(ctx, provider, attemptReq, generate)
+		if err == nil {
+			resp.Truncated = truncated
+			if len(attempts) > 0 {
+				resp.RequestedProvider = req.Provider
+				resp.FailedAttempts = attempts
+			}
+			return resp, nil
+		}
+
+		retryable := IsRetryable(err)
+		attempts = append(attempts, model.ProviderAttempt{
+			Provider:  attemptReq.Provider,
+			Model:     m.modelOrDefault(attemptReq),
+			Error:     err.Error(),
+			Retryable: retryable,
+			LatencyMs: time.Since(startTime).Milliseconds(),
+		})
+
+		if !retryable || ctx.Err() != nil || (canFailover != nil && !canFailover()) {
+			return nil, failoverResult(attempts, err)
+		}
+
+		// Move to the next fallback provider that accepts the request
+		provider = nil
+		for provider == nil && len(candidates) > 0 {
+			next := *req
+			next.Provider = candidates[0]
+			next.Model = m.equivalentModel(req, candidates[0])
+			candidates = candidates[1:]
+
+			// Never fail over to a provider the caller's API key may not use
+			if scoped && !apiKey.Allows(string(next.Provider), m.modelOrDefault(&next)) {
+				continue
+			}
+			// Nor to one the caller's checks reject, such as one over quota
+			if checked && check(ctx, next.Provider) != nil {
+				continue
+			}
+
+			resolved, resolveErr := m.resolveProvider(&next)
+			if resolveErr != nil {
+				continue
+			}
+			// The fallback model may have a smaller context window
+			if checked, checkedTruncated, checkErr := m.preflight(&next); checkErr == nil {
+				provider = resolved
+				attemptReq = checked
+				truncated = checkedTruncated
+			}
+		}
+		if provider == nil {
+			return nil, failoverResult(attempts, err)
+		}
+	}
+}
+
+// failoverResult returns the last error as-is when only one provider was tried
+func failoverResult(attempts []model.ProviderAttempt, err error) error {
+	if len(attempts) == 1 {
+		return err
+	}
+	return &FailoverError{Attempts: attempts, Err: err}
+}
+
+// failoverChain returns the providers to try after the requested one
+func (m *Manager) failoverChain(requested model.AIProvider) []model.AIProvider {
+	if !m.config.Failover.Enabled {
+		return nil
+	}
+
+	var chain []model.AIProvider
+	for _, name := range m.config.Failover.Chain {
+		providerType := model.AIProvider(strings.ToLower(strings.TrimSpace(name)))
+		if providerType != requested {
+			chain = append(chain, providerType)
+		}
+	}
+	return chain
+}
+
+// equivalentModel picks the model on target that best matches the requested
+// model, falling back to the target's configured default model
+func (m *Manager) equivalentModel(req *model.GenerationRequest, target model.AIProvider) string {
+	groups := m.config.Failover.ModelEquivalents
+	if len(groups) == 0 {
+		groups = defaultModelEquivalents
+	}
+
+	requestedModel := m.modelOrDefault(req)
+	for _, group := range groups {
+		if sameModel(group[string(req.Provider)], requestedModel) {
+			if equivalent := group[string(target)]; equivalent != "" {
+				return equivalent
+			}
+		}
+	}
+
+	return m.DefaultModel(target)
+}
+
+// modelOrDefault returns the requested model or the provider's configured default
+func (m *Manager) modelOrDefault(req *model.GenerationRequest) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return m.DefaultModel(req.Provider)
+}
+
+// sameModel compares model names, treating Anthropic aliases and their dated
+// IDs as equal
+func sameModel(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return a == b || anthropicModelAliases[a] == b || anthropicModelAliases[b] == a
+}
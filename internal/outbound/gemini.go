@@ -3,58 +3,251 @@ package outbound
 import (
 	"context"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"ai-service/internal/model"
 
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
 type GeminiProvider struct {
-	client *genai.Client
-	apiKey string
+	apiKey       string
+	defaultModel string
+
+	// mu guards the client, which is created on first use, and the count
+	// of requests using it
+	mu       sync.Mutex
+	client   *genai.Client
+	inFlight int
+	closed   bool
 }
 
-func NewGeminiProvider(apiKey string) *GeminiProvider {
+func NewGeminiProvider(apiKey, defaultModel string) *GeminiProvider {
+	if defaultModel == "" {
+		defaultModel = "gemini-1.5-flash"
+	}
+
 	return &GeminiProvider{
-		apiKey: apiKey,
+		apiKey:       apiKey,
+		defaultModel: defaultModel,
 	}
 }
 
-func (p *GeminiProvider) initClient(ctx context.Context) error {
-	if p.client != nil {
+// acquireClient returns the client, creating it if needed, and counts the
+// caller as using it until releaseClient is called
+func (p *GeminiProvider) acquireClient(ctx context.Context) (*genai.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		if p.apiKey == "" {
+			return nil, fmt.Errorf("Gemini API key not configured")
+		}
+
+		client, err := genai.NewClient(ctx, option.WithAPIKey(p.apiKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+		}
+		p.client = client
+	}
+
+	p.inFlight++
+	return p.client, nil
+}
+
+// releaseClient ends a use of the client, closing it once the provider has
+// been closed and no request still uses it
+func (p *GeminiProvider) releaseClient() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inFlight--
+	if p.closed && p.inFlight == 0 {
+		if err := p.closeClient(); err != nil {
+			log.Printf("Failed to close Gemini client: %v", err)
+		}
+	}
+}
+
+// Close releases the client of a provider a reload replaced. Requests still
+// running keep it until they finish.
+func (p *GeminiProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	if p.inFlight > 0 {
 		return nil
 	}
+	return p.closeClient()
+}
 
-	if p.apiKey == "" {
-		return fmt.Errorf("Gemini API key not configured")
+// closeClient closes and forgets the client; the caller must hold p.mu
+func (p *GeminiProvider) closeClient() error {
+	if p.client == nil {
+		return nil
 	}
+	err := p.client.Close()
+	p.client = nil
+	return err
+}
 
-	client, err := genai.NewClient(ctx, option.WithAPIKey(p.apiKey))
+func (p *GeminiProvider) Generate(ctx context.Context, req *model.GenerationRequest) (*model.GenerationResponse, error) {
+	client, err := p.acquireClient(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create Gemini client: %w", err)
+		return nil, err
 	}
+	defer p.releaseClient()
 
-	p.client = client
-	return nil
+	startTime := time.Now()
+
+	geminiModel, modelName, history := p.prepare(client, req)
+
+	// Generate content, replaying prior turns through a chat session
+	var resp *genai.GenerateContentResponse
+	if len(history) > 0 {
+		chat := geminiModel.StartChat()
+		chat.History = history
+		resp, err = chat.SendMessage(ctx, genai.Text(req.Prompt))
+	} else {
+		resp, err = geminiModel.GenerateContent(ctx, genai.Text(req.Prompt))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Gemini API error: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no response from Gemini")
+	}
+
+	// Extract content
+	content := candidateText(resp)
+
+	duration := time.Since(startTime)
+
+	usage := responseUsage(req, resp.UsageMetadata, content)
+
+	return &model.GenerationResponse{
+		ID:           fmt.Sprintf("gemini-%d", time.Now().UnixNano()),
+		Provider:     model.Gemini,
+		Model:        modelName,
+		Content:      content,
+		TokensUsed:   usage.TotalTokens,
+		GeneratedAt:  time.Now(),
+		Duration:     duration.String(),
+		TokenUsage:   usage,
+		FinishReason: geminiFinishReason(resp.Candidates[0].FinishReason),
+	}, nil
 }
 
-func (p *GeminiProvider) Generate(ctx context.Context, req *model.GenerationRequest) (*model.GenerationResponse, error) {
-	if err := p.initClient(ctx); err != nil {
+// GenerateStream generates content using GenerateContentStream
+func (p *GeminiProvider) GenerateStream(ctx context.Context, req *model.GenerationRequest, onChunk func(chunk string) error) (*model.GenerationResponse, error) {
+	client, err := p.acquireClient(ctx)
+	if err != nil {
 		return nil, err
 	}
+	defer p.releaseClient()
 
 	startTime := time.Now()
 
+	geminiModel, modelName, history := p.prepare(client, req)
+
+	var iter *genai.GenerateContentResponseIterator
+	if len(history) > 0 {
+		chat := geminiModel.StartChat()
+		chat.History = history
+		iter = chat.SendMessageStream(ctx, genai.Text(req.Prompt))
+	} else {
+		iter = geminiModel.GenerateContentStream(ctx, genai.Text(req.Prompt))
+	}
+
+	var content strings.Builder
+	var usageMetadata *genai.UsageMetadata
+	var finishReason genai.FinishReason
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Gemini API error: %w", err)
+		}
+		// Each chunk reports the usage so far; the last one has the totals
+		if resp.UsageMetadata != nil {
+			usageMetadata = resp.UsageMetadata
+		}
+		if len(resp.Candidates) > 0 && resp.Candidates[0].FinishReason != genai.FinishReasonUnspecified {
+			finishReason = resp.Candidates[0].FinishReason
+		}
+
+		chunk := candidateText(resp)
+		if chunk == "" {
+			continue
+		}
+
+		content.WriteString(chunk)
+		if err := onChunk(chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no response from Gemini")
+	}
+
+	duration := time.Since(startTime)
+
+	usage := responseUsage(req, usageMetadata, content.String())
+
+	return &model.GenerationResponse{
+		ID:           fmt.Sprintf("gemini-%d", time.Now().UnixNano()),
+		Provider:     model.Gemini,
+		Model:        modelName,
+		Content:      content.String(),
+		TokensUsed:   usage.TotalTokens,
+		GeneratedAt:  time.Now(),
+		Duration:     duration.String(),
+		TokenUsage:   usage,
+		FinishReason: geminiFinishReason(finishReason),
+	}, nil
+}
+
+// geminiFinishReason maps a candidate's finish reason to its OpenAI name
+func geminiFinishReason(reason genai.FinishReason) string {
+	switch reason {
+	case genai.FinishReasonUnspecified:
+		return ""
+	case genai.FinishReasonMaxTokens:
+		return model.FinishReasonLength
+	}
+	return model.FinishReasonStop
+}
+
+// responseUsage reads the token counts Gemini reports with a response,
+// falling back to an estimate when the response carries none
+func responseUsage(req *model.GenerationRequest, usage *genai.UsageMetadata, completion string) model.TokenUsage {
+	if usage == nil || usage.PromptTokenCount == 0 {
+		return estimateUsage(req, completion)
+	}
+	return model.NewTokenUsage(int(usage.PromptTokenCount), int(usage.CandidatesTokenCount), false)
+}
+
+// prepare configures the generative model for a request, including its system
+// instruction, and returns the chat history built from any prior turns
+func (p *GeminiProvider) prepare(client *genai.Client, req *model.GenerationRequest) (*genai.GenerativeModel, string, []*genai.Content) {
 	// Set default model if not specified
 	modelName := req.Model
 	if modelName == "" {
-		modelName = "gemini-1.5-flash"
+		modelName = p.defaultModel
 	}
 
 	// Get the model
-	geminiModel := p.client.GenerativeModel(modelName)
+	geminiModel := client.GenerativeModel(modelName)
 
 	// Configure generation parameters
 	if req.MaxTokens > 0 {
@@ -65,47 +258,92 @@ func (p *GeminiProvider) Generate(ctx context.Context, req *model.GenerationRequ
 		geminiModel.SetTemperature(req.Temperature)
 	}
 
-	// Prepare prompt
-	prompt := req.Prompt
 	if req.SystemMsg != "" {
-		prompt = fmt.Sprintf("System: %s\n\nUser: %s", req.SystemMsg, req.Prompt)
+		geminiModel.SystemInstruction = &genai.Content{
+			Parts: []genai.Part{genai.Text(req.SystemMsg)},
+		}
 	}
 
-	// Generate content
-	resp, err := geminiModel.GenerateContent(ctx, genai.Text(prompt))
-	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
+	if len(req.Messages) == 0 {
+		return geminiModel, modelName, nil
 	}
 
-	duration := time.Since(startTime)
+	// Gemini names the assistant role "model"
+	history := make([]*genai.Content, 0, len(req.Messages))
+	for _, message := range req.Messages {
+		role := "user"
+		if message.Role == model.RoleAssistant {
+			role = "model"
+		}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response from Gemini")
+		history = append(history, &genai.Content{
+			Role:  role,
+			Parts: []genai.Part{genai.Text(message.Content)},
+		})
+	}
+
+	return geminiModel, modelName, history
+}
+
+// candidateText concatenates the text parts of the first candidate
+func candidateText(resp *genai.GenerateContentResponse) string {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
 	}
 
-	// Extract content
 	var content string
 	for _, part := range resp.Candidates[0].Content.Parts {
 		if text, ok := part.(genai.Text); ok {
 			content += string(text)
 		}
 	}
+	return content
+}
+
+// ListModels lists the models available to the API key that support
+// generateContent
+func (p *GeminiProvider) ListModels(ctx context.Context) ([]model.ModelInfo, error) {
+	client, err := p.acquireClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.releaseClient()
 
-	// Calculate tokens used (approximate - field may not be available in all versions)
-	tokensUsed := 0
-	// Note: UsageMetadata field may not be available in all versions of the Gemini API
-	// For now, we'll estimate based on content length
-	tokensUsed = len(content) / 4 // Rough estimation: 1 token ≈ 4 characters
+	var models []model.ModelInfo
+	iter := client.ListModels(ctx)
+	for {
+		listed, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Gemini API error: %w", err)
+		}
 
-	return &model.GenerationResponse{
-		ID:          fmt.Sprintf("gemini-%d", time.Now().UnixNano()),
-		Provider:    model.Gemini,
-		Model:       modelName,
-		Content:     content,
-		TokensUsed:  tokensUsed,
-		GeneratedAt: time.Now(),
-		Duration:    duration.String(),
-	}, nil
+		// Skip embedding and other models that can't generate content
+		capabilities := []string{model.CapabilityChat}
+		chat := false
+		for _, method := range listed.SupportedGenerationMethods {
+			switch method {
+			case "generateContent":
+				chat = true
+			case "streamGenerateContent":
+				capabilities = append(capabilities, model.CapabilityStreaming)
+			}
+		}
+		if !chat {
+			continue
+		}
+
+		models = append(models, model.ModelInfo{
+			ID:              strings.TrimPrefix(listed.Name, "models/"),
+			Provider:        model.Gemini,
+			ContextWindow:   int(listed.InputTokenLimit),
+			MaxOutputTokens: int(listed.OutputTokenLimit),
+			Capabilities:    capabilities,
+		})
+	}
+	return models, nil
 }
 
 func (p *GeminiProvider) GetName() string {
@@ -116,15 +354,6 @@ func (p *GeminiProvider) IsAvailable() bool {
 	return p.apiKey != ""
 }
 
-func (p *GeminiProvider) GetSupportedModels() []string {
-	return []string{
-		"gemini-1.5-flash",
-		"gemini-1.5-pro",
-		"gemini-1.0-pro",
-		"gemini-pro-vision",
-	}
-}
-
 func (p *GeminiProvider) ValidateRequest(req *model.GenerationRequest) error {
 	if req.Prompt == "" {
 		return fmt.Errorf("prompt is required")
@@ -16,9 +16,22 @@ type Provider interface {
 	// IsAvailable returns whether the provider is available
 	IsAvailable() bool
 
-	// GetSupportedModels returns the list of supported models
-	GetSupportedModels() []string
-
 	// ValidateRequest validates the generation request
 	ValidateRequest(req *model.GenerationRequest) error
 }
+
+// StreamingProvider is implemented by providers that can stream partial output
+type StreamingProvider interface {
+	Provider
+
+	// GenerateStream generates content, calling onChunk for every partial piece
+	// of text, and returns the assembled response once the stream ends
+	GenerateStream(ctx context.Context, req *model.GenerationRequest, onChunk func(chunk string) error) (*model.GenerationResponse, error)
+}
+
+// ModelLister is implemented by providers that can list the models their API
+// key has access to
+type ModelLister interface {
+	// ListModels returns the chat models the provider serves to this key
+	ListModels(ctx context.Context) ([]model.ModelInfo, error)
+}
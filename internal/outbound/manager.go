@@ -3,23 +3,55 @@ package outbound
 import (
 	"context"
 	"fmt"
+	"log"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"ai-service/cmd/config"
 	"ai-service/internal/model"
+
+	"github.com/google/uuid"
 )
 
 type Manager struct {
 	providers map[model.AIProvider]Provider
-	config    *config.Config
-	mu        sync.RWMutex
+	// definitions lists every provider that can be configured, and settings
+	// the configuration each was built from
+	definitions []ProviderDefinition
+	settings    map[model.AIProvider]ProviderSettings
+	// inactive holds the providers switched off in the providers table
+	inactive map[model.AIProvider]bool
+	breakers map[model.AIProvider]*CircuitBreaker
+	registry *Registry
+	config   *config.Config
+	mu       sync.RWMutex
+
+	// providerStore supplies the providers table read on reload, keySource
+	// the API keys stored there, and reloadMu serializes reloads
+	providerStore ProviderConfigStore
+	keySource     ProviderKeySource
+	reloadMu      sync.Mutex
+
+	// catalog is the registry with each provider's configured and discovered
+	// models merged in
+	catalog          *Registry
+	configuredModels map[model.AIProvider][]model.ModelInfo
+	discovered       map[model.AIProvider]discoveredModels
+	// refreshing marks the providers whose models are being listed
+	refreshing map[model.AIProvider]bool
 }
 
 func NewManager(cfg *config.Config) *Manager {
+	registry := DefaultRegistry()
 	manager := &Manager{
-		providers: make(map[model.AIProvider]Provider),
-		config:    cfg,
+		providers:  make(map[model.AIProvider]Provider),
+		breakers:   make(map[model.AIProvider]*CircuitBreaker),
+		registry:   registry,
+		config:     cfg,
+		discovered: make(map[model.AIProvider]discoveredModels),
+		refreshing: make(map[model.AIProvider]bool),
 	}
 
 	// Initialize providers
@@ -30,22 +62,76 @@ func NewManager(cfg *config.Config) *Manager {
 
 func (m *Manager) initProviders() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	// Registered providers first, then each named OpenAI-compatible backend
+	// as a provider of its own
+	m.definitions = RegisteredProviders()
+	m.configuredModels = make(map[model.AIProvider][]model.ModelInfo)
+	for _, backend := range m.config.AIProviders.OpenAICompatible {
+		definition := openAICompatibleDefinition(backend)
+		if _, exists := m.definition(definition.ID); exists {
+			log.Printf("Skipping OpenAI-compatible backend %s: a provider with that name is already registered", backend.Name)
+			continue
+		}
+		m.definitions = append(m.definitions, definition)
 
-	// Initialize OpenAI provider
-	if m.config.AIProviders.OpenAI.APIKey != "" && !isPlaceholderAPIKey(m.config.AIProviders.OpenAI.APIKey) {
-		m.providers[model.OpenAI] = NewOpenAIProvider(m.config.AIProviders.OpenAI.APIKey)
+		models := make([]model.ModelInfo, len(backend.Models))
+		for i, info := range backend.Models {
+			info.Provider = definition.ID
+			models[i] = info
+		}
+		if len(models) > 0 {
+			m.configuredModels[definition.ID] = models
+		}
 	}
+	registry := m.registry
+	m.mu.Unlock()
+
+	// Build every provider whose required settings are configured
+	m.applyProviders(nil, nil, registry)
+}
 
-	// Initialize Gemini provider
-	if m.config.AIProviders.Gemini.APIKey != "" && !isPlaceholderAPIKey(m.config.AIProviders.Gemini.APIKey) {
-		m.providers[model.Gemini] = NewGeminiProvider(m.config.AIProviders.Gemini.APIKey)
+// azureAPIVersion returns the API version to use for an Azure OpenAI backend,
+// or "" for the OpenAI API
+func azureAPIVersion(apiType, apiVersion string) string {
+	if apiType != config.OpenAIAPITypeAzure {
+		return ""
 	}
+	if apiVersion == "" {
+		return "2024-06-01"
+	}
+	return apiVersion
+}
 
-	// Note: Anthropic provider would be initialized here when implemented
-	// if m.config.AIProviders.Anthropic.APIKey != "" && !isPlaceholderAPIKey(m.config.AIProviders.Anthropic.APIKey) {
-	//     m.providers[model.Anthropic] = NewAnthropicProvider(m.config.AIProviders.Anthropic.APIKey)
-	// }
+// breakerSettings builds the circuit breaker settings from configuration,
+// using the defaults for any value that is not set
+func (m *Manager) breakerSettings() BreakerSettings {
+	settings := DefaultBreakerSettings()
+	if m.config.CircuitBreaker.FailureThreshold > 0 {
+		settings.FailureThreshold = m.config.CircuitBreaker.FailureThreshold
+	}
+	if m.config.CircuitBreaker.OpenTimeout > 0 {
+		settings.OpenTimeout = m.config.CircuitBreaker.OpenTimeout
+	}
+	if m.config.CircuitBreaker.HalfOpenMaxRequests > 0 {
+		settings.HalfOpenMaxRequests = m.config.CircuitBreaker.HalfOpenMaxRequests
+	}
+	return settings
+}
+
+// retryPolicy builds the provider retry policy from configuration, using the
+// defaults for any value that is not set
+func (m *Manager) retryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	if m.config.Retry.MaxAttempts > 0 {
+		policy.MaxAttempts = m.config.Retry.MaxAttempts
+	}
+	if m.config.Retry.InitialBackoff > 0 {
+		policy.InitialBackoff = m.config.Retry.InitialBackoff
+	}
+	if m.config.Retry.MaxBackoff > 0 {
+		policy.MaxBackoff = m.config.Retry.MaxBackoff
+	}
+	return policy
 }
 
 // isPlaceholderAPIKey checks if the API key is a placeholder value
@@ -74,17 +160,127 @@ func isPlaceholderAPIKey(apiKey string) bool {
 	return false
 }
 
+// Generate generates content with the requested provider, failing over to the
+// configured fallback chain on retryable errors
 func (m *Manager) Generate(ctx context.Context, req *model.GenerationRequest) (*model.GenerationResponse, error) {
+	return m.generateWithFailover(ctx, req, func(ctx context.Context, provider Provider, attemptReq *model.GenerationRequest) (*model.GenerationResponse, error) {
+		return provider.Generate(ctx, attemptReq)
+	}, nil)
+}
+
+// GenerateStream generates content and calls onChunk with each partial piece of
+// output. Providers without streaming support deliver the full response as a
+// single chunk. Failover only happens before the first chunk is delivered.
+func (m *Manager) GenerateStream(ctx context.Context, req *model.GenerationRequest, onChunk func(chunk string) error) (*model.GenerationResponse, error) {
+	emitted := false
+	emit := func(chunk string) error {
+		emitted = true
+		return onChunk(chunk)
+	}
+
+	return m.generateWithFailover(ctx, req, func(ctx context.Context, provider Provider, attemptReq *model.GenerationRequest) (*model.GenerationResponse, error) {
+		if streamer, ok := provider.(StreamingProvider); ok {
+			return streamer.GenerateStream(ctx, attemptReq, emit)
+		}
+
+		resp, err := provider.Generate(ctx, attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		if err := emit(resp.Content); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}, func() bool {
+		return !emitted
+	})
+}
+
+// generateOnce generates content with exactly the requested provider
+func (m *Manager) generateOnce(ctx context.Context, req *model.GenerationRequest) (*model.GenerationResponse, error) {
+	provider, err := m.resolveProvider(req)
+	if err != nil {
+		return nil, err
+	}
+	req, truncated, err := m.preflight(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.callProvider(ctx, provider, req, func(ctx context.Context, provider Provider, req *model.GenerationRequest) (*model.GenerationResponse, error) {
+		return provider.Generate(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Truncated = truncated
+	return resp, nil
+}
+
+// callProvider runs generate through the provider's circuit breaker, failing
+// fast while the circuit is open, within the provider's timeout if it has one
+func (m *Manager) callProvider(ctx context.Context, provider Provider, req *model.GenerationRequest, generate generateFunc) (*model.GenerationResponse, error) {
+	m.mu.RLock()
+	breaker := m.breakers[req.Provider]
+	timeout, _ := time.ParseDuration(m.settings[req.Provider][SettingTimeout])
+	m.mu.RUnlock()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if breaker == nil {
+		return generate(ctx, provider, req)
+	}
+
+	if err := breaker.Allow(); err != nil {
+		return nil, circuitOpenError(string(req.Provider))
+	}
+
+	resp, err := generate(ctx, provider, req)
+	breaker.Record(err)
+	return resp, err
+}
+
+// ProviderHealth returns the circuit breaker status of every configured provider
+func (m *Manager) ProviderHealth() map[model.AIProvider]BreakerStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	health := make(map[model.AIProvider]BreakerStatus, len(m.breakers))
+	for providerType, breaker := range m.breakers {
+		health[providerType] = breaker.Status()
+	}
+	return health
+}
+
+// resolveProvider looks up the requested provider and validates the request against it
+func (m *Manager) resolveProvider(req *model.GenerationRequest) (Provider, error) {
+	// Read the provider and its settings together so a reload cannot pair
+	// one with the other's replacement
 	m.mu.RLock()
 	provider, exists := m.providers[req.Provider]
+	disabled := m.inactive[req.Provider]
+	configured := len(m.providers)
+	maxTokens, _ := strconv.Atoi(m.settings[req.Provider][SettingMaxTokens])
+	var names []string
+	if !exists {
+		names = m.getAvailableProviderNames()
+	}
 	m.mu.RUnlock()
 
 	if !exists {
+		if disabled {
+			return nil, fmt.Errorf("provider %s is disabled", req.Provider)
+		}
+
 		// Check if any providers are configured
-		if len(m.providers) == 0 {
+		if configured == 0 {
 			return nil, fmt.Errorf("no AI providers configured. Please set at least one API key (OPENAI_API_KEY, GEMINI_API_KEY, or ANTHROPIC_API_KEY)")
 		}
-		return nil, fmt.Errorf("provider %s not found. Available providers: %v", req.Provider, m.getAvailableProviderNames())
+		return nil, fmt.Errorf("provider %s not found. Available providers: %v", req.Provider, names)
 	}
 
 	if !provider.IsAvailable() {
@@ -92,150 +288,174 @@ func (m *Manager) Generate(ctx context.Context, req *model.GenerationRequest) (*
 	}
 
 	// Validate request
+	if err := validateMessages(req.Messages); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
 	if err := provider.ValidateRequest(req); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	// Generate content
-	return provider.Generate(ctx, req)
+	if maxTokens > 0 && req.MaxTokens > maxTokens {
+		return nil, fmt.Errorf("validation error: max_tokens %d exceeds the limit of %d for %s", req.MaxTokens, maxTokens, req.Provider)
+	}
+
+	return provider, nil
+}
+
+// validateMessages checks that prior conversation turns alternate between the
+// user and the assistant, starting with the user
+func validateMessages(messages []model.Message) error {
+	for i, message := range messages {
+		expected := model.RoleUser
+		if i%2 == 1 {
+			expected = model.RoleAssistant
+		}
+		if message.Role != expected {
+			return fmt.Errorf("message %d must have role %q, got %q", i, expected, message.Role)
+		}
+		if message.Content == "" {
+			return fmt.Errorf("message %d has empty content", i)
+		}
+	}
+	return nil
 }
 
+// Compare runs the request against every requested provider concurrently and
+// returns one result per provider, in request order, including failed legs
 func (m *Manager) Compare(ctx context.Context, req *model.ComparisonRequest) (*model.ComparisonResponse, error) {
-	var results []model.GenerationResponse
-	var wg sync.WaitGroup
-	var mu sync.Mutex
+	if len(req.Providers) == 0 {
+		return nil, fmt.Errorf("at least one provider is required")
+	}
 
-	errChan := make(chan error, len(req.Providers))
+	results := make([]model.ComparisonResult, len(req.Providers))
+	var wg sync.WaitGroup
 
-	for _, providerType := range req.Providers {
+	for i, providerType := range req.Providers {
 		wg.Add(1)
-		go func(pt model.AIProvider) {
+		go func(i int, pt model.AIProvider) {
 			defer wg.Done()
 
 			genReq := &model.GenerationRequest{
 				Provider:    pt,
+				Model:       req.Models[pt],
 				Prompt:      req.Prompt,
 				MaxTokens:   req.MaxTokens,
 				Temperature: req.Temperature,
+				SystemMsg:   req.SystemMsg,
+			}
+
+			startTime := time.Now()
+			// Each leg must reflect its own provider, so never fail over here
+			resp, err := m.generateOnce(ctx, genReq)
+			latency := time.Since(startTime)
+
+			result := model.ComparisonResult{
+				Provider:  pt,
+				Model:     genReq.Model,
+				LatencyMs: latency.Milliseconds(),
+			}
+			if result.Model == "" {
+				result.Model = m.DefaultModel(pt)
 			}
 
-			resp, err := m.Generate(ctx, genReq)
 			if err != nil {
-				errChan <- fmt.Errorf("provider %s failed: %w", pt, err)
-				return
+				result.Status = "error"
+				result.Error = err.Error()
+			} else {
+				result.Status = "success"
+				result.Model = resp.Model
+				result.Content = resp.Content
+				result.TokensUsed = resp.TokensUsed
+				result.TokenUsage = resp.TokenUsage
 			}
 
-			mu.Lock()
-			results = append(results, *resp)
-			mu.Unlock()
-		}(providerType)
+			results[i] = result
+		}(i, providerType)
 	}
 
 	wg.Wait()
-	close(errChan)
-
-	// Check for errors
-	var errs []error
-	for err := range errChan {
-		errs = append(errs, err)
-	}
-
-	if len(errs) > 0 && len(results) == 0 {
-		return nil, fmt.Errorf("all providers failed: %v", errs)
-	}
 
 	return &model.ComparisonResponse{
+		ID:        uuid.NewString(),
 		Prompt:    req.Prompt,
 		Results:   results,
-		CreatedAt: results[0].GeneratedAt,
+		CreatedAt: time.Now(),
 	}, nil
 }
 
+// Registry returns the model registry, including any models discovered from
+// the providers so far
+func (m *Manager) Registry() *Registry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.catalog
+}
+
+// SetRegistry replaces the model registry, keeping the discovered models
+func (m *Manager) SetRegistry(registry *Registry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registry = registry
+	m.catalog = m.buildCatalog()
+}
+
+// DefaultModel returns the configured default model for a provider
+func (m *Manager) DefaultModel(providerType model.AIProvider) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.settings[providerType][SettingDefaultModel]
+}
+
+// ProviderDefinitions returns every provider that can be configured, in
+// listing order, whether or not it is
+func (m *Manager) ProviderDefinitions() []ProviderDefinition {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]ProviderDefinition(nil), m.definitions...)
+}
+
+// ProviderDefinition returns the definition of a provider
+func (m *Manager) ProviderDefinition(providerType model.AIProvider) (ProviderDefinition, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.definition(providerType)
+}
+
+// definition looks up a provider definition; the caller must hold m.mu
+func (m *Manager) definition(providerType model.AIProvider) (ProviderDefinition, bool) {
+	for _, definition := range m.definitions {
+		if definition.ID == providerType {
+			return definition, true
+		}
+	}
+	return ProviderDefinition{}, false
+}
+
+// GetAvailableProviders compares every provider that can be configured
 func (m *Manager) GetAvailableProviders() map[string]model.AIProviderComparison {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	comparisons := make(map[string]model.AIProviderComparison)
-
-	// OpenAI comparison
-	openaiProvider, hasOpenAI := m.providers[model.OpenAI]
-	comparisons["openai"] = model.AIProviderComparison{
-		Provider: model.OpenAI,
-		Name:     "OpenAI GPT",
-		Strengths: []string{
-			"Excellent general knowledge",
-			"Strong reasoning capabilities",
-			"Good code generation",
-			"Wide range of models",
-			"Reliable API",
-		},
-		Weaknesses: []string{
-			"Can be expensive for high usage",
-			"Knowledge cutoff limitations",
-			"Rate limiting on free tier",
-		},
-		BestFor: []string{
-			"General text generation",
-			"Code completion and debugging",
-			"Creative writing",
-			"Question answering",
-		},
-		Pricing:   "Pay per token (~$0.002/1K tokens for GPT-3.5)",
-		MaxTokens: 4096,
-		Available: hasOpenAI && openaiProvider.IsAvailable(),
-	}
-
-	// Gemini comparison
-	geminiProvider, hasGemini := m.providers[model.Gemini]
-	comparisons["gemini"] = model.AIProviderComparison{
-		Provider: model.Gemini,
-		Name:     "Google Gemini",
-		Strengths: []string{
-			"Multimodal capabilities",
-			"Large context window",
-			"Good at reasoning tasks",
-			"Free tier available",
-			"Fast response times",
-		},
-		Weaknesses: []string{
-			"Less mature than OpenAI",
-			"Limited third-party integrations",
-			"Newer model with less community support",
-		},
-		BestFor: []string{
-			"Multimodal tasks",
-			"Long document analysis",
-			"Research and analysis",
-			"Cost-effective solutions",
-		},
-		Pricing:   "Free tier available, pay per token for pro usage",
-		MaxTokens: 8192,
-		Available: hasGemini && geminiProvider.IsAvailable(),
-	}
-
-	// Anthropic comparison (placeholder for future implementation)
-	comparisons["anthropic"] = model.AIProviderComparison{
-		Provider: model.Anthropic,
-		Name:     "Anthropic Claude",
-		Strengths: []string{
-			"Strong safety focus",
-			"Excellent at analysis",
-			"Good refusal mechanisms",
-			"Thoughtful responses",
-		},
-		Weaknesses: []string{
-			"More conservative responses",
-			"Limited availability",
-			"Higher cost",
-		},
-		BestFor: []string{
-			"Safety-critical applications",
-			"Research and analysis",
-			"Ethical AI use cases",
-		},
-		Pricing:   "Pay per token (premium pricing)",
-		MaxTokens: 8192,
-		Available: false, // Not implemented yet
+	comparisons := make(map[string]model.AIProviderComparison, len(m.definitions))
+	for _, definition := range m.definitions {
+		maxTokens := 0
+		for _, info := range m.catalog.Models(definition.ID) {
+			if info.MaxOutputTokens > maxTokens {
+				maxTokens = info.MaxOutputTokens
+			}
+		}
+
+		provider, exists := m.providers[definition.ID]
+		comparisons[string(definition.ID)] = model.AIProviderComparison{
+			Provider:   definition.ID,
+			Name:       definition.Name,
+			Strengths:  definition.Strengths,
+			Weaknesses: definition.Weaknesses,
+			BestFor:    definition.BestFor,
+			Pricing:    definition.Pricing,
+			MaxTokens:  maxTokens,
+			Available:  exists && provider.IsAvailable(),
+		}
 	}
 
 	return comparisons
@@ -258,7 +478,8 @@ func (m *Manager) GetDefaultProvider() model.AIProvider {
 	return model.OpenAI
 }
 
-// getAvailableProviderNames returns a list of available provider names
+// getAvailableProviderNames returns a list of available provider names; the
+// caller must hold m.mu
 func (m *Manager) getAvailableProviderNames() []string {
 	var names []string
 	for provider := range m.providers {
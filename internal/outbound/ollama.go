@@ -0,0 +1,383 @@
+package outbound
+
+import (
+	"ai-service/internal/model"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ollamaTimeout is longer than for hosted APIs as local models can take a
+// while to load and generate
+const ollamaTimeout = 5 * time.Minute
+
+// OllamaProvider generates content with models served by a local Ollama daemon
+type OllamaProvider struct {
+	baseURL      string
+	defaultModel string
+	client       *http.Client
+}
+
+func NewOllamaProvider(baseURL, defaultModel string) *OllamaProvider {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+
+	return &OllamaProvider{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		defaultModel: defaultModel,
+		client:       newHTTPClient(ollamaTimeout, DefaultRetryPolicy()),
+	}
+}
+
+// SetRetryPolicy replaces the retry policy used for API calls
+func (p *OllamaProvider) SetRetryPolicy(policy RetryPolicy) {
+	p.client = newHTTPClient(p.client.Timeout, policy)
+}
+
+// ollamaResponse is a response, or a streamed line, of /api/generate and
+// /api/chat; generate fills Response and chat fills Message
+type ollamaResponse struct {
+	Model    string `json:"model"`
+	Response string `json:"response"`
+	Message  struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+// finishReason maps done_reason to its OpenAI name; Ollama already uses
+// "stop" and "length", and older servers send none
+func (r *ollamaResponse) finishReason() string {
+	if r.DoneReason == model.FinishReasonLength {
+		return model.FinishReasonLength
+	}
+	if r.Done {
+		return model.FinishReasonStop
+	}
+	return ""
+}
+
+// text returns the generated text of either endpoint
+func (r *ollamaResponse) text() string {
+	if r.Response != "" {
+		return r.Response
+	}
+	return r.Message.Content
+}
+
+func (p *OllamaProvider) Generate(ctx context.Context, req *model.GenerationRequest) (*model.GenerationResponse, error) {
+	startTime := time.Now()
+	ctx, retries := withRetryCounter(ctx)
+
+	endpoint, payload, modelName := p.buildPayload(req)
+	payload["stream"] = false
+
+	httpReq, err := p.newRequest(ctx, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make request
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		return nil, newProviderError("Ollama", resp, ollamaErrorMessage(body))
+	}
+
+	var ollamaResp ollamaResponse
+	if err := json.Unmarshal(body, &ollamaResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	content := ollamaResp.text()
+	if content == "" {
+		return nil, fmt.Errorf("no response from Ollama")
+	}
+
+	duration := time.Since(startTime)
+	usage := p.tokenUsage(req, &ollamaResp, content)
+
+	return &model.GenerationResponse{
+		ID:           fmt.Sprintf("ollama-%d", time.Now().UnixNano()),
+		Provider:     model.Ollama,
+		Model:        modelName,
+		Content:      content,
+		TokensUsed:   usage.TotalTokens,
+		GeneratedAt:  time.Now(),
+		Duration:     duration.String(),
+		Retries:      int(retries.Load()),
+		TokenUsage:   usage,
+		FinishReason: ollamaResp.finishReason(),
+	}, nil
+}
+
+// GenerateStream generates content from Ollama's newline-delimited JSON stream
+func (p *OllamaProvider) GenerateStream(ctx context.Context, req *model.GenerationRequest, onChunk func(chunk string) error) (*model.GenerationResponse, error) {
+	startTime := time.Now()
+	ctx, retries := withRetryCounter(ctx)
+
+	endpoint, payload, modelName := p.buildPayload(req)
+	payload["stream"] = true
+
+	httpReq, err := p.newRequest(ctx, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Streams can outlive the client timeout, so rely on ctx for cancellation
+	streamClient := *p.client
+	streamClient.Timeout = 0
+
+	// Make request
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newProviderError("Ollama", resp, ollamaErrorMessage(body))
+	}
+
+	var content strings.Builder
+	var final ollamaResponse
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var chunk ollamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("Ollama stream error: failed to parse stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("Ollama stream error: %s", chunk.Error)
+		}
+
+		if text := chunk.text(); text != "" {
+			content.WriteString(text)
+			if err := onChunk(text); err != nil {
+				return nil, err
+			}
+		}
+
+		if chunk.Done {
+			final = chunk
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Ollama stream error: %w", err)
+	}
+
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no response from Ollama")
+	}
+
+	duration := time.Since(startTime)
+	usage := p.tokenUsage(req, &final, content.String())
+
+	return &model.GenerationResponse{
+		ID:           fmt.Sprintf("ollama-%d", time.Now().UnixNano()),
+		Provider:     model.Ollama,
+		Model:        modelName,
+		Content:      content.String(),
+		TokensUsed:   usage.TotalTokens,
+		GeneratedAt:  time.Now(),
+		Duration:     duration.String(),
+		Retries:      int(retries.Load()),
+		TokenUsage:   usage,
+		FinishReason: final.finishReason(),
+	}, nil
+}
+
+// tokenUsage uses the evaluation counts Ollama reports, estimating them when
+// they are missing, as they are when the prompt was served from cache
+func (p *OllamaProvider) tokenUsage(req *model.GenerationRequest, resp *ollamaResponse, completion string) model.TokenUsage {
+	if resp.PromptEvalCount == 0 || resp.EvalCount == 0 {
+		return estimateUsage(req, completion)
+	}
+	return model.NewTokenUsage(resp.PromptEvalCount, resp.EvalCount, false)
+}
+
+// buildPayload picks the endpoint for the request, /api/generate for single
+// prompts and /api/chat when there are prior turns, and prepares its body
+func (p *OllamaProvider) buildPayload(req *model.GenerationRequest) (string, map[string]interface{}, string) {
+	// Set default model if not specified
+	modelName := req.Model
+	if modelName == "" {
+		modelName = p.defaultModel
+	}
+
+	options := map[string]interface{}{}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	if req.Temperature > 0 {
+		options["temperature"] = req.Temperature
+	}
+
+	payload := map[string]interface{}{
+		"model":   modelName,
+		"options": options,
+	}
+
+	if len(req.Messages) == 0 {
+		payload["prompt"] = req.Prompt
+		if req.SystemMsg != "" {
+			payload["system"] = req.SystemMsg
+		}
+		return "/api/generate", payload, modelName
+	}
+
+	// System message, prior turns, then the new prompt
+	var messages []map[string]string
+	if req.SystemMsg != "" {
+		messages = append(messages, map[string]string{
+			"role":    "system",
+			"content": req.SystemMsg,
+		})
+	}
+	for _, message := range req.Messages {
+		messages = append(messages, map[string]string{
+			"role":    message.Role,
+			"content": message.Content,
+		})
+	}
+	messages = append(messages, map[string]string{
+		"role":    "user",
+		"content": req.Prompt,
+	})
+	payload["messages"] = messages
+
+	return "/api/chat", payload, modelName
+}
+
+// newRequest creates a request to an Ollama API endpoint
+func (p *OllamaProvider) newRequest(ctx context.Context, endpoint string, payload map[string]interface{}) (*http.Request, error) {
+	// Marshal payload to JSON
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+endpoint, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	return httpReq, nil
+}
+
+// ollamaErrorMessage extracts the message of an Ollama error body
+func ollamaErrorMessage(body []byte) string {
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+		return errResp.Error
+	}
+	return string(body)
+}
+
+// ListModels lists the models pulled into the local daemon. Names carry a tag,
+// so "llama3:latest" is also registered as "llama3".
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]model.ModelInfo, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newProviderError("Ollama", resp, ollamaErrorMessage(body))
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	models := make([]model.ModelInfo, 0, len(tags.Models))
+	for _, tag := range tags.Models {
+		info := model.ModelInfo{
+			ID:           tag.Name,
+			Provider:     model.Ollama,
+			Capabilities: []string{model.CapabilityChat, model.CapabilityStreaming},
+		}
+		if base, ok := strings.CutSuffix(tag.Name, ":latest"); ok {
+			info.Aliases = []string{base}
+		}
+		models = append(models, info)
+	}
+	return models, nil
+}
+
+func (p *OllamaProvider) GetName() string {
+	return "Ollama"
+}
+
+func (p *OllamaProvider) IsAvailable() bool {
+	return p.baseURL != ""
+}
+
+func (p *OllamaProvider) ValidateRequest(req *model.GenerationRequest) error {
+	if req.Prompt == "" {
+		return fmt.Errorf("prompt is required")
+	}
+
+	if req.Model == "" && p.defaultModel == "" {
+		return fmt.Errorf("model is required for Ollama when OLLAMA_DEFAULT_MODEL is not set")
+	}
+
+	if req.Temperature < 0 || req.Temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2 for Ollama")
+	}
+
+	return nil
+}
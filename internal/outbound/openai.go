@@ -8,41 +8,274 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
+// openAIDefaultBaseURL is the OpenAI API root used when no base URL is set
+const openAIDefaultBaseURL = "https://api.openai.com/v1"
+
+// OpenAIOptions configures an OpenAI or OpenAI-compatible backend
+type OpenAIOptions struct {
+	// Provider is the ID the backend is registered under, Name its display name
+	Provider model.AIProvider
+	Name     string
+	APIKey   string
+	BaseURL  string
+	// DefaultModel is used for requests that name no model
+	DefaultModel string
+	// AzureAPIVersion switches to Azure OpenAI: the model name is used as the
+	// deployment and the key is sent in the api-key header
+	AzureAPIVersion string
+}
+
 type OpenAIProvider struct {
-	apiKey string
-	client *http.Client
+	options OpenAIOptions
+	client  *http.Client
+}
+
+func NewOpenAIProvider(apiKey, baseURL string) *OpenAIProvider {
+	return NewOpenAICompatibleProvider(OpenAIOptions{
+		Provider: model.OpenAI,
+		Name:     "OpenAI",
+		APIKey:   apiKey,
+		BaseURL:  baseURL,
+	})
 }
 
-func NewOpenAIProvider(apiKey string) *OpenAIProvider {
+// NewOpenAICompatibleProvider creates a provider for any server speaking the
+// OpenAI chat completions API, such as Azure OpenAI, vLLM, LocalAI or Ollama
+func NewOpenAICompatibleProvider(options OpenAIOptions) *OpenAIProvider {
+	if options.BaseURL == "" {
+		options.BaseURL = openAIDefaultBaseURL
+	}
+	options.BaseURL = strings.TrimRight(options.BaseURL, "/")
+	if options.DefaultModel == "" && options.Provider == model.OpenAI {
+		options.DefaultModel = "gpt-3.5-turbo"
+	}
+
 	return &OpenAIProvider{
-		apiKey: apiKey,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		options: options,
+		client:  newHTTPClient(30*time.Second, DefaultRetryPolicy()),
 	}
 }
 
+// SetRetryPolicy replaces the retry policy used for API calls
+func (p *OpenAIProvider) SetRetryPolicy(policy RetryPolicy) {
+	p.client = newHTTPClient(p.client.Timeout, policy)
+}
+
 func (p *OpenAIProvider) Generate(ctx context.Context, req *model.GenerationRequest) (*model.GenerationResponse, error) {
 	startTime := time.Now()
+	ctx, retries := withRetryCounter(ctx)
+
+	payload, modelName := p.buildPayload(req)
+
+	httpReq, err := p.newRequest(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make request
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		return nil, newProviderError(p.options.Name, resp, string(body))
+	}
+
+	// Parse response
+	var openAIResp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage openAIUsage `json:"usage"`
+	}
+
+	if err := json.Unmarshal(body, &openAIResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(openAIResp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from %s", p.options.Name)
+	}
+
+	duration := time.Since(startTime)
+	usage := openAIResp.Usage.tokenUsage()
+
+	return &model.GenerationResponse{
+		ID:           fmt.Sprintf("%s-%d", p.options.Provider, time.Now().UnixNano()),
+		Provider:     p.options.Provider,
+		Model:        modelName,
+		Content:      openAIResp.Choices[0].Message.Content,
+		TokensUsed:   usage.TotalTokens,
+		GeneratedAt:  time.Now(),
+		Duration:     duration.String(),
+		Retries:      int(retries.Load()),
+		TokenUsage:   usage,
+		FinishReason: openAIResp.Choices[0].FinishReason,
+	}, nil
+}
+
+// GenerateStream generates content using the chat completions stream option
+func (p *OpenAIProvider) GenerateStream(ctx context.Context, req *model.GenerationRequest, onChunk func(chunk string) error) (*model.GenerationResponse, error) {
+	startTime := time.Now()
+	ctx, retries := withRetryCounter(ctx)
+
+	payload, modelName := p.buildPayload(req)
+	payload["stream"] = true
+	payload["stream_options"] = map[string]bool{"include_usage": true}
+
+	httpReq, err := p.newRequest(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	// Streams can outlive the client timeout, so rely on ctx for cancellation
+	streamClient := *p.client
+	streamClient.Timeout = 0
+
+	// Make request
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newProviderError(p.options.Name, resp, string(body))
+	}
+
+	var content strings.Builder
+	var usage *openAIUsage
+	var finishReason string
+
+	err = readSSE(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return io.EOF
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+				FinishReason string `json:"finish_reason"`
+			} `json:"choices"`
+			Usage *openAIUsage `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
 
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].FinishReason != "" {
+			finishReason = chunk.Choices[0].FinishReason
+		}
+
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		return onChunk(chunk.Choices[0].Delta.Content)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s stream error: %w", p.options.Name, err)
+	}
+
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no response from %s", p.options.Name)
+	}
+
+	duration := time.Since(startTime)
+
+	// Backends that ignore stream_options send no usage chunk
+	tokenUsage := estimateUsage(req, content.String())
+	if usage != nil {
+		tokenUsage = usage.tokenUsage()
+	}
+
+	return &model.GenerationResponse{
+		ID:           fmt.Sprintf("%s-%d", p.options.Provider, time.Now().UnixNano()),
+		Provider:     p.options.Provider,
+		Model:        modelName,
+		Content:      content.String(),
+		TokensUsed:   tokenUsage.TotalTokens,
+		GeneratedAt:  time.Now(),
+		Duration:     duration.String(),
+		Retries:      int(retries.Load()),
+		TokenUsage:   tokenUsage,
+		FinishReason: finishReason,
+	}, nil
+}
+
+// openAIUsage is the usage object of a chat completion
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// tokenUsage converts the reported usage, keeping the reported total
+func (u openAIUsage) tokenUsage() model.TokenUsage {
+	usage := model.NewTokenUsage(u.PromptTokens, u.CompletionTokens, false)
+	if u.TotalTokens > 0 {
+		usage.TotalTokens = u.TotalTokens
+	}
+	return usage
+}
+
+// buildPayload prepares the chat completions request body and resolves the model name
+func (p *OpenAIProvider) buildPayload(req *model.GenerationRequest) (map[string]interface{}, string) {
 	// Set default model if not specified
 	modelName := req.Model
 	if modelName == "" {
-		modelName = "gpt-3.5-turbo"
+		modelName = p.options.DefaultModel
+	}
+
+	// Build the message list: system message, prior turns, then the new prompt
+	var messages []map[string]string
+	if req.SystemMsg != "" {
+		messages = append(messages, map[string]string{
+			"role":    "system",
+			"content": req.SystemMsg,
+		})
+	}
+	for _, message := range req.Messages {
+		messages = append(messages, map[string]string{
+			"role":    message.Role,
+			"content": message.Content,
+		})
 	}
+	messages = append(messages, map[string]string{
+		"role":    "user",
+		"content": req.Prompt,
+	})
 
 	// Prepare request payload
 	payload := map[string]interface{}{
-		"model": modelName,
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": req.Prompt,
-			},
-		},
+		"model":    modelName,
+		"messages": messages,
 	}
 
 	if req.MaxTokens > 0 {
@@ -53,15 +286,11 @@ func (p *OpenAIProvider) Generate(ctx context.Context, req *model.GenerationRequ
 		payload["temperature"] = req.Temperature
 	}
 
-	if req.SystemMsg != "" {
-		// Add system message as the first message
-		systemMsg := map[string]string{
-			"role":    "system",
-			"content": req.SystemMsg,
-		}
-		payload["messages"] = append([]map[string]string{systemMsg}, payload["messages"].([]map[string]string)...)
-	}
+	return payload, modelName
+}
 
+// newRequest creates an authenticated chat completions request
+func (p *OpenAIProvider) newRequest(ctx context.Context, payload map[string]interface{}) (*http.Request, error) {
 	// Marshal payload to JSON
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
@@ -69,80 +298,116 @@ func (p *OpenAIProvider) Generate(ctx context.Context, req *model.GenerationRequ
 	}
 
 	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonPayload))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.chatCompletionsURL(payload["model"].(string)), bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	p.authenticate(httpReq)
+
+	return httpReq, nil
+}
+
+// chatCompletionsURL returns the chat completions endpoint for a model. Azure
+// routes by deployment in the path and versions the API in the query string.
+func (p *OpenAIProvider) chatCompletionsURL(modelName string) string {
+	if p.options.AzureAPIVersion != "" {
+		return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+			p.options.BaseURL, url.PathEscape(modelName), url.QueryEscape(p.options.AzureAPIVersion))
+	}
+	return p.options.BaseURL + "/chat/completions"
+}
+
+// authenticate sets the API key header, if a key is configured; keyless local
+// servers get no header
+func (p *OpenAIProvider) authenticate(httpReq *http.Request) {
+	if p.options.APIKey == "" {
+		return
+	}
+	if p.options.AzureAPIVersion != "" {
+		httpReq.Header.Set("api-key", p.options.APIKey)
+		return
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.options.APIKey)
+}
+
+// ListModels lists the chat models available to the API key. Azure lists
+// base models rather than deployments, so it reports none and the registry
+// is used as is.
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]model.ModelInfo, error) {
+	if p.options.AzureAPIVersion != "" {
+		return nil, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.options.BaseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.authenticate(httpReq)
 
-	// Make request
 	resp, err := p.client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenAI API error: %s - %s", resp.Status, string(body))
+		return nil, newProviderError(p.options.Name, resp, string(body))
 	}
 
-	// Parse response
-	var openAIResp struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-		Usage struct {
-			TotalTokens int `json:"total_tokens"`
-		} `json:"usage"`
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
 	}
-
-	if err := json.Unmarshal(body, &openAIResp); err != nil {
+	if err := json.Unmarshal(body, &list); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	if len(openAIResp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+	var models []model.ModelInfo
+	for _, entry := range list.Data {
+		// Compatible servers list only the models they serve
+		if p.options.Provider == model.OpenAI && !isOpenAIChatModel(entry.ID) {
+			continue
+		}
+		models = append(models, model.ModelInfo{
+			ID:           entry.ID,
+			Provider:     p.options.Provider,
+			Capabilities: []string{model.CapabilityChat, model.CapabilityStreaming},
+		})
 	}
+	return models, nil
+}
 
-	duration := time.Since(startTime)
-
-	return &model.GenerationResponse{
-		ID:          fmt.Sprintf("openai-%d", time.Now().UnixNano()),
-		Provider:    model.OpenAI,
-		Model:       modelName,
-		Content:     openAIResp.Choices[0].Message.Content,
-		TokensUsed:  openAIResp.Usage.TotalTokens,
-		GeneratedAt: time.Now(),
-		Duration:    duration.String(),
-	}, nil
+// isOpenAIChatModel reports whether a listed model serves chat completions;
+// the models endpoint also returns embedding, audio and image models
+func isOpenAIChatModel(id string) bool {
+	oSeries := len(id) > 1 && id[0] == 'o' && id[1] >= '0' && id[1] <= '9'
+	if !strings.HasPrefix(id, "gpt-") && !strings.HasPrefix(id, "chatgpt-") && !oSeries {
+		return false
+	}
+	for _, marker := range []string{"instruct", "audio", "realtime", "transcribe", "tts", "image", "search"} {
+		if strings.Contains(id, marker) {
+			return false
+		}
+	}
+	return true
 }
 
 func (p *OpenAIProvider) GetName() string {
-	return "OpenAI"
+	return p.options.Name
 }
 
+// IsAvailable reports whether the provider can be called; OpenAI itself needs
+// an API key, compatible servers may run without one
 func (p *OpenAIProvider) IsAvailable() bool {
-	return p.apiKey != ""
-}
-
-func (p *OpenAIProvider) GetSupportedModels() []string {
-	return []string{
-		"gpt-4",
-		"gpt-4-turbo",
-		"gpt-3.5-turbo",
-		"gpt-3.5-turbo-16k",
-	}
+	return p.options.APIKey != "" || p.options.Provider != model.OpenAI
 }
 
 func (p *OpenAIProvider) ValidateRequest(req *model.GenerationRequest) error {
@@ -150,12 +415,13 @@ func (p *OpenAIProvider) ValidateRequest(req *model.GenerationRequest) error {
 		return fmt.Errorf("prompt is required")
 	}
 
-	if req.MaxTokens > 4096 {
+	// Compatible servers' output limits come from the model registry
+	if p.options.Provider == model.OpenAI && req.MaxTokens > 4096 {
 		return fmt.Errorf("max_tokens cannot exceed 4096 for OpenAI models")
 	}
 
 	if req.Temperature < 0 || req.Temperature > 2 {
-		return fmt.Errorf("temperature must be between 0 and 2 for OpenAI")
+		return fmt.Errorf("temperature must be between 0 and 2 for %s", p.options.Name)
 	}
 
 	return nil
@@ -0,0 +1,75 @@
+package outbound
+
+import (
+	"fmt"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/util/tokenizer"
+)
+
+// ContextWindowError is returned when a request's prompt plus its completion
+// budget does not fit the model's context window
+type ContextWindowError struct {
+	Provider      model.AIProvider
+	Model         string
+	PromptTokens  int
+	MaxTokens     int
+	ContextWindow int
+}
+
+func (e *ContextWindowError) Error() string {
+	return fmt.Sprintf("request needs about %d prompt tokens plus %d completion tokens, which exceeds the %d token context window of %s",
+		e.PromptTokens, e.MaxTokens, e.ContextWindow, e.Model)
+}
+
+// preflight checks the request against the model's context window before it
+// is sent. In truncate mode the oldest conversation turns are dropped first,
+// then the prompt is shortened; the returned request is a copy whenever it
+// was changed. Models with no known window are passed through.
+func (m *Manager) preflight(req *model.GenerationRequest) (*model.GenerationRequest, bool, error) {
+	if m.config.Preflight.Mode == config.PreflightOff {
+		return req, false, nil
+	}
+
+	modelName := m.modelOrDefault(req)
+	info, ok := m.Registry().Match(req.Provider, modelName)
+	if !ok || info.ContextWindow == 0 {
+		return req, false, nil
+	}
+	window := info.ContextWindow
+
+	promptTokens := tokenizer.CountRequest(modelName, req)
+	if promptTokens+req.MaxTokens <= window {
+		return req, false, nil
+	}
+
+	windowErr := &ContextWindowError{
+		Provider:      req.Provider,
+		Model:         modelName,
+		PromptTokens:  promptTokens,
+		MaxTokens:     req.MaxTokens,
+		ContextWindow: window,
+	}
+	if m.config.Preflight.Mode != config.PreflightTruncate {
+		return nil, false, windowErr
+	}
+
+	truncated := *req
+	budget := window - req.MaxTokens
+
+	// Drop turns in user/assistant pairs so the history keeps alternating
+	for len(truncated.Messages) >= 2 && tokenizer.CountRequest(modelName, &truncated) > budget {
+		truncated.Messages = truncated.Messages[2:]
+	}
+
+	if excess := tokenizer.CountRequest(modelName, &truncated) - budget; excess > 0 {
+		promptBudget := tokenizer.Count(modelName, truncated.Prompt) - excess
+		if promptBudget <= 0 {
+			return nil, false, windowErr
+		}
+		truncated.Prompt = tokenizer.Truncate(modelName, truncated.Prompt, promptBudget)
+	}
+
+	return &truncated, true, nil
+}
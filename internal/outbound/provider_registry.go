@@ -0,0 +1,164 @@
+package outbound
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+)
+
+// Settings most providers read, by ConfigField name
+const (
+	SettingAPIKey       = "api_key"
+	SettingBaseURL      = "base_url"
+	SettingDefaultModel = "default_model"
+	SettingAPIType      = "api_type"
+	SettingAPIVersion   = "api_version"
+	SettingMaxTokens    = "max_tokens"
+	SettingTimeout      = "timeout"
+)
+
+// commonConfigFields are the settings the Manager applies to every provider
+var commonConfigFields = []ConfigField{
+	{Name: SettingMaxTokens, Description: "Most completion tokens a request may ask for"},
+	{Name: SettingTimeout, Description: "Time limit for each call, such as 60s"},
+}
+
+// ConfigField describes one setting of a provider's configuration schema
+type ConfigField struct {
+	Name        string `json:"name"`
+	Env         string `json:"env,omitempty"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+	// Secret fields, such as API keys, are never shown and must not be
+	// placeholder values
+	Secret  bool   `json:"secret"`
+	Default string `json:"default,omitempty"`
+}
+
+// ProviderSettings holds a provider's settings keyed by ConfigField name
+type ProviderSettings map[string]string
+
+// ProviderDefinition describes a provider the Manager can build: its ID,
+// display metadata, configuration schema and factory
+type ProviderDefinition struct {
+	ID          model.AIProvider `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Strengths   []string         `json:"strengths,omitempty"`
+	Weaknesses  []string         `json:"weaknesses,omitempty"`
+	BestFor     []string         `json:"best_for,omitempty"`
+	Pricing     string           `json:"pricing,omitempty"`
+
+	ConfigSchema []ConfigField `json:"config_schema"`
+
+	// Settings reads the provider's settings from the service configuration.
+	// When nil, each field is read from its environment variable.
+	Settings func(cfg *config.Config) ProviderSettings `json:"-"`
+
+	// New builds the provider from settings that have every required field
+	New func(settings ProviderSettings, retryPolicy RetryPolicy) (Provider, error) `json:"-"`
+}
+
+// providerIDPattern matches valid provider IDs, as used in requests and URLs
+var providerIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+var (
+	definitionsMu sync.RWMutex
+	definitions   []ProviderDefinition
+)
+
+// RegisterProvider makes a provider available to every Manager created
+// afterwards. It is meant to be called from init and panics if the
+// definition is invalid or its ID is already registered.
+func RegisterProvider(definition ProviderDefinition) {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+
+	if !providerIDPattern.MatchString(string(definition.ID)) {
+		panic(fmt.Sprintf("outbound: invalid provider ID %q", definition.ID))
+	}
+	if definition.New == nil {
+		panic(fmt.Sprintf("outbound: provider %s has no factory", definition.ID))
+	}
+	for _, registered := range definitions {
+		if registered.ID == definition.ID {
+			panic(fmt.Sprintf("outbound: provider %s registered twice", definition.ID))
+		}
+	}
+	definition.ConfigSchema = withCommonFields(definition.ConfigSchema)
+	definitions = append(definitions, definition)
+}
+
+// withCommonFields appends the common settings a schema does not declare
+func withCommonFields(schema []ConfigField) []ConfigField {
+	merged := append([]ConfigField(nil), schema...)
+	for _, common := range commonConfigFields {
+		declared := false
+		for _, field := range schema {
+			declared = declared || field.Name == common.Name
+		}
+		if !declared {
+			merged = append(merged, common)
+		}
+	}
+	return merged
+}
+
+// Field returns the schema field with the given name
+func (d *ProviderDefinition) Field(name string) (ConfigField, bool) {
+	for _, field := range d.ConfigSchema {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return ConfigField{}, false
+}
+
+// RegisteredProviders returns the registered provider definitions in
+// registration order
+func RegisteredProviders() []ProviderDefinition {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+	return append([]ProviderDefinition(nil), definitions...)
+}
+
+// settings reads the definition's settings, filling unset fields with their
+// defaults
+func (d *ProviderDefinition) settings(cfg *config.Config) ProviderSettings {
+	settings := ProviderSettings{}
+	if d.Settings != nil {
+		settings = d.Settings(cfg)
+	} else {
+		for _, field := range d.ConfigSchema {
+			if field.Env != "" {
+				settings[field.Name] = os.Getenv(field.Env)
+			}
+		}
+	}
+
+	for _, field := range d.ConfigSchema {
+		if settings[field.Name] == "" && field.Default != "" {
+			settings[field.Name] = field.Default
+		}
+	}
+	return settings
+}
+
+// configured reports whether the settings set every required field, with
+// real values for secrets
+func (d *ProviderDefinition) configured(settings ProviderSettings) bool {
+	for _, field := range d.ConfigSchema {
+		if !field.Required {
+			continue
+		}
+		value := settings[field.Name]
+		if value == "" || (field.Secret && isPlaceholderAPIKey(value)) {
+			return false
+		}
+	}
+	return true
+}
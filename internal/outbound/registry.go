@@ -0,0 +1,224 @@
+package outbound
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-service/internal/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed models.yaml
+var builtinModels []byte
+
+// Registry is the catalogue of models each provider serves. It drives request
+// validation, the provider listings and the context window preflight.
+type Registry struct {
+	models map[model.AIProvider][]model.ModelInfo
+}
+
+// registryFile is the layout of a registry YAML file
+type registryFile struct {
+	Models []model.ModelInfo `yaml:"models"`
+}
+
+// NewRegistry builds a registry from a list of models, keeping their order
+func NewRegistry(models []model.ModelInfo) (*Registry, error) {
+	registry := &Registry{models: make(map[model.AIProvider][]model.ModelInfo)}
+	for _, info := range models {
+		if err := validateModelInfo(info); err != nil {
+			return nil, err
+		}
+		registry.models[info.Provider] = append(registry.models[info.Provider], info)
+	}
+	return registry, nil
+}
+
+// ParseRegistry reads a registry from YAML, or JSON, with a top-level models list
+func ParseRegistry(data []byte) (*Registry, error) {
+	var file registryFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse model registry: %w", err)
+	}
+	return NewRegistry(file.Models)
+}
+
+// DefaultRegistry returns the built-in registry
+func DefaultRegistry() *Registry {
+	registry, err := ParseRegistry(builtinModels)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in model registry: %v", err))
+	}
+	return registry
+}
+
+// ProviderConfigStore reads the stored configuration of each provider
+type ProviderConfigStore interface {
+	List(ctx context.Context) ([]*model.ProviderRecord, error)
+}
+
+// LoadRegistry builds the registry from file when set. Otherwise each
+// provider's models come from the "models" list in its providers.config
+// column, falling back to the built-in models for providers without one.
+func LoadRegistry(ctx context.Context, file string, store ProviderConfigStore) (*Registry, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read model registry: %w", err)
+		}
+		return ParseRegistry(data)
+	}
+
+	registry := DefaultRegistry()
+	if store == nil {
+		return registry, nil
+	}
+
+	records, err := store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load provider config: %w", err)
+	}
+
+	for _, record := range records {
+		if len(record.Config) == 0 {
+			continue
+		}
+
+		var providerConfig struct {
+			Models []model.ModelInfo `json:"models"`
+		}
+		if err := json.Unmarshal(record.Config, &providerConfig); err != nil {
+			return nil, fmt.Errorf("invalid config for provider %s: %w", record.Name, err)
+		}
+		if len(providerConfig.Models) == 0 {
+			continue
+		}
+
+		provider := model.AIProvider(record.Name)
+		for i := range providerConfig.Models {
+			providerConfig.Models[i].Provider = provider
+			if err := validateModelInfo(providerConfig.Models[i]); err != nil {
+				return nil, err
+			}
+		}
+		registry.models[provider] = providerConfig.Models
+	}
+
+	return registry, nil
+}
+
+// validateModelInfo checks the fields every registry entry needs
+func validateModelInfo(info model.ModelInfo) error {
+	if info.ID == "" || info.Provider == "" {
+		return fmt.Errorf("model registry entries need an id and a provider")
+	}
+	if info.DeprecatedAt != "" {
+		if _, err := time.Parse(time.DateOnly, info.DeprecatedAt); err != nil {
+			return fmt.Errorf("model %s has an invalid deprecated_at %q, want YYYY-MM-DD", info.ID, info.DeprecatedAt)
+		}
+	}
+	return nil
+}
+
+// Providers returns every provider with at least one model, sorted by name
+func (r *Registry) Providers() []model.AIProvider {
+	providers := make([]model.AIProvider, 0, len(r.models))
+	for provider := range r.models {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i] < providers[j]
+	})
+	return providers
+}
+
+// Models returns the models of a provider in registry order
+func (r *Registry) Models(provider model.AIProvider) []model.ModelInfo {
+	return r.models[provider]
+}
+
+// ModelIDs returns the IDs of a provider's models that are not deprecated at
+// the given time
+func (r *Registry) ModelIDs(provider model.AIProvider, at time.Time) []string {
+	var ids []string
+	for _, info := range r.models[provider] {
+		if !info.Deprecated(at) {
+			ids = append(ids, info.ID)
+		}
+	}
+	return ids
+}
+
+// Get looks up a model by ID or alias
+func (r *Registry) Get(provider model.AIProvider, modelName string) (model.ModelInfo, bool) {
+	for _, info := range r.models[provider] {
+		if info.ID == modelName {
+			return info, true
+		}
+		for _, alias := range info.Aliases {
+			if alias == modelName {
+				return info, true
+			}
+		}
+	}
+	return model.ModelInfo{}, false
+}
+
+// Resolve finds the provider serving a model, looking the name up by ID or
+// alias across providers in name order. A "provider/model" name picks the
+// provider explicitly.
+func (r *Registry) Resolve(name string) (model.ModelInfo, bool) {
+	if providerName, modelName, ok := strings.Cut(name, "/"); ok {
+		if _, known := r.models[model.AIProvider(providerName)]; known {
+			return r.Get(model.AIProvider(providerName), modelName)
+		}
+	}
+
+	for _, provider := range r.Providers() {
+		if info, ok := r.Get(provider, name); ok {
+			return info, true
+		}
+	}
+	return model.ModelInfo{}, false
+}
+
+// Match looks up a model like Get, then by the longest ID that prefixes the
+// name, so versioned IDs such as gpt-4-0613 inherit their base model's limits
+func (r *Registry) Match(provider model.AIProvider, modelName string) (model.ModelInfo, bool) {
+	if info, ok := r.Get(provider, modelName); ok {
+		return info, true
+	}
+
+	var best model.ModelInfo
+	found := false
+	for _, info := range r.models[provider] {
+		if strings.HasPrefix(modelName, info.ID) && len(info.ID) > len(best.ID) {
+			best = info
+			found = true
+		}
+	}
+	return best, found
+}
+
+// Validate checks that a provider serves the model, that it is not
+// deprecated and that maxTokens is within its output limit
+func (r *Registry) Validate(provider model.AIProvider, modelName string, maxTokens int, at time.Time) error {
+	info, ok := r.Get(provider, modelName)
+	if !ok {
+		return fmt.Errorf("Model '%s' is not valid for provider '%s'. Valid models: %v", modelName, provider, r.ModelIDs(provider, at))
+	}
+	if info.Deprecated(at) {
+		return fmt.Errorf("Model '%s' was deprecated on %s. Valid models: %v", modelName, info.DeprecatedAt, r.ModelIDs(provider, at))
+	}
+	if info.MaxOutputTokens > 0 && maxTokens > info.MaxOutputTokens {
+		return fmt.Errorf("max_tokens cannot exceed %d for model '%s'", info.MaxOutputTokens, modelName)
+	}
+	return nil
+}
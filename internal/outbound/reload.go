@@ -0,0 +1,348 @@
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"ai-service/internal/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Provider changes reported by a reload
+const (
+	ProviderAdded   = "added"
+	ProviderRemoved = "removed"
+	ProviderUpdated = "updated"
+	// ProviderDisabled is reported for configured providers switched off
+	// through providers.is_active
+	ProviderDisabled = "disabled"
+)
+
+// ProviderChange reports how a reload changed one provider
+type ProviderChange struct {
+	Provider model.AIProvider `json:"provider"`
+	// Change is ProviderAdded, ProviderRemoved, ProviderUpdated or
+	// ProviderDisabled
+	Change string `json:"change"`
+	// Settings names the settings that changed; their values are never
+	// reported as they may be secrets
+	Settings []string `json:"settings,omitempty"`
+	// Error is set when the provider could not be rebuilt, in which case
+	// the previous instance is kept
+	Error string `json:"error,omitempty"`
+}
+
+// ProviderReload reports the outcome of reloading provider configuration
+type ProviderReload struct {
+	Changes    []ProviderChange   `json:"changes"`
+	Providers  []model.AIProvider `json:"providers"`
+	ReloadedAt time.Time          `json:"reloaded_at"`
+}
+
+// SetProviderStore sets the providers table read by Reload
+func (m *Manager) SetProviderStore(store ProviderConfigStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.providerStore = store
+}
+
+// ProviderKeySource supplies decrypted provider API keys stored outside the
+// environment
+type ProviderKeySource interface {
+	ProviderKeys(ctx context.Context) (map[model.AIProvider]string, error)
+}
+
+// SetKeySource sets the stored API keys read by Reload, which override every
+// other source of a provider's api_key setting
+func (m *Manager) SetKeySource(source ProviderKeySource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keySource = source
+}
+
+// Reload reads provider configuration again and swaps in new provider
+// instances for those whose settings changed. Settings come from the
+// environment, overridden by the provider config file, then by the
+// providers.config column and then by stored API keys. Requests already running keep the instance they
+// started with. Providers whose row has is_active false are not built.
+// Nothing is changed when a source cannot be read.
+func (m *Manager) Reload(ctx context.Context) (*ProviderReload, error) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	m.mu.RLock()
+	store := m.providerStore
+	keySource := m.keySource
+	m.mu.RUnlock()
+
+	overrides, inactive, err := loadProviderSettings(ctx, m.config.AIProviders.ProviderConfigFile, store)
+	if err != nil {
+		return nil, err
+	}
+	if keySource != nil {
+		keys, err := keySource.ProviderKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for provider, key := range keys {
+			if overrides[provider] == nil {
+				overrides[provider] = ProviderSettings{}
+			}
+			overrides[provider][SettingAPIKey] = key
+		}
+	}
+	registry, err := LoadRegistry(ctx, m.config.AIProviders.ModelRegistryFile, store)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := m.applyProviders(overrides, inactive, registry)
+	for _, change := range changes {
+		if change.Error != "" {
+			log.Printf("Failed to reload provider %s, keeping the previous instance: %s", change.Provider, change.Error)
+		}
+	}
+
+	m.mu.RLock()
+	providers := make([]model.AIProvider, 0, len(m.providers))
+	for providerType := range m.providers {
+		providers = append(providers, providerType)
+	}
+	m.mu.RUnlock()
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i] < providers[j]
+	})
+
+	return &ProviderReload{
+		Changes:    changes,
+		Providers:  providers,
+		ReloadedAt: time.Now(),
+	}, nil
+}
+
+// WatchConfig reloads provider configuration every interval until ctx is
+// done, which picks up edits to the provider config file and the providers
+// table, logging any changes
+func (m *Manager) WatchConfig(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reload, err := m.Reload(ctx)
+			if err != nil {
+				log.Printf("Failed to reload provider configuration: %v", err)
+				continue
+			}
+			LogProviderReload(reload)
+		}
+	}
+}
+
+// LogProviderReload logs the providers a reload changed
+func LogProviderReload(reload *ProviderReload) {
+	for _, change := range reload.Changes {
+		if change.Error != "" {
+			continue
+		}
+		log.Printf("Provider %s %s %v", change.Provider, change.Change, change.Settings)
+	}
+}
+
+// applyProviders builds the active providers from their settings, with
+// overrides replacing the configured values, and swaps them in together with
+// registry. Providers whose settings are unchanged keep their instance and
+// circuit breaker. It returns the changes sorted by provider.
+func (m *Manager) applyProviders(overrides map[model.AIProvider]ProviderSettings, inactive map[model.AIProvider]bool, registry *Registry) []ProviderChange {
+	m.mu.RLock()
+	definitions := m.definitions
+	previous := m.providers
+	previousSettings := m.settings
+	m.mu.RUnlock()
+
+	retryPolicy := m.retryPolicy()
+	providers := make(map[model.AIProvider]Provider, len(definitions))
+	settings := make(map[model.AIProvider]ProviderSettings, len(definitions))
+	rebuilt := make(map[model.AIProvider]bool)
+	var changes []ProviderChange
+
+	for i := range definitions {
+		definition := &definitions[i]
+		current := definition.settings(m.config)
+		for _, field := range definition.ConfigSchema {
+			if value := overrides[definition.ID][field.Name]; value != "" {
+				current[field.Name] = value
+			}
+		}
+		settings[definition.ID] = current
+
+		existing, exists := previous[definition.ID]
+		if !definition.configured(current) {
+			if exists {
+				changes = append(changes, ProviderChange{Provider: definition.ID, Change: ProviderRemoved})
+			}
+			continue
+		}
+		if inactive[definition.ID] {
+			if exists {
+				changes = append(changes, ProviderChange{Provider: definition.ID, Change: ProviderDisabled})
+			}
+			continue
+		}
+
+		changed := changedSettings(definition, previousSettings[definition.ID], current)
+		if exists && len(changed) == 0 {
+			providers[definition.ID] = existing
+			continue
+		}
+
+		change := ProviderChange{Provider: definition.ID, Change: ProviderAdded}
+		if exists {
+			change.Change = ProviderUpdated
+			change.Settings = changed
+		}
+
+		provider, err := definition.New(current, retryPolicy)
+		if err != nil {
+			change.Error = err.Error()
+			changes = append(changes, change)
+			if exists {
+				providers[definition.ID] = existing
+				settings[definition.ID] = previousSettings[definition.ID]
+			}
+			continue
+		}
+		providers[definition.ID] = provider
+		rebuilt[definition.ID] = true
+		changes = append(changes, change)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	breakerSettings := m.breakerSettings()
+	breakers := make(map[model.AIProvider]*CircuitBreaker, len(providers))
+	for providerType := range providers {
+		if breaker, ok := m.breakers[providerType]; ok && !rebuilt[providerType] {
+			breakers[providerType] = breaker
+			continue
+		}
+		breakers[providerType] = NewCircuitBreaker(breakerSettings)
+	}
+	for _, change := range changes {
+		if change.Error == "" && change.Change != ProviderAdded {
+			delete(m.discovered, change.Provider)
+		}
+	}
+
+	m.providers = providers
+	m.settings = settings
+	m.inactive = inactive
+	m.breakers = breakers
+	m.registry = registry
+	m.catalog = m.buildCatalog()
+
+	// Release the resources of instances that were replaced or removed;
+	// requests already running finish on them first
+	for providerType, existing := range previous {
+		closer, ok := existing.(io.Closer)
+		if !ok || providers[providerType] == existing {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close the previous %s provider: %v", providerType, err)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Provider < changes[j].Provider
+	})
+	return changes
+}
+
+// changedSettings returns the names of the schema fields whose values differ
+func changedSettings(definition *ProviderDefinition, before, after ProviderSettings) []string {
+	var changed []string
+	for _, field := range definition.ConfigSchema {
+		if before[field.Name] != after[field.Name] {
+			changed = append(changed, field.Name)
+		}
+	}
+	return changed
+}
+
+// loadProviderSettings reads provider settings from the config file, when
+// set, and then from the providers table, whose values win. It also returns
+// the providers whose row is inactive.
+func loadProviderSettings(ctx context.Context, file string, store ProviderConfigStore) (map[model.AIProvider]ProviderSettings, map[model.AIProvider]bool, error) {
+	overrides := make(map[model.AIProvider]ProviderSettings)
+	inactive := make(map[model.AIProvider]bool)
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read provider config: %w", err)
+		}
+		var fileSettings map[string]map[string]interface{}
+		if err := yaml.Unmarshal(data, &fileSettings); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse provider config: %w", err)
+		}
+		for name, values := range fileSettings {
+			overrides[model.AIProvider(name)] = scalarSettings(values)
+		}
+	}
+
+	if store == nil {
+		return overrides, inactive, nil
+	}
+
+	records, err := store.List(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load provider config: %w", err)
+	}
+	for _, record := range records {
+		if !record.IsActive {
+			inactive[model.AIProvider(record.Name)] = true
+		}
+		if len(record.Config) == 0 {
+			continue
+		}
+		var values map[string]interface{}
+		if err := json.Unmarshal(record.Config, &values); err != nil {
+			return nil, nil, fmt.Errorf("invalid config for provider %s: %w", record.Name, err)
+		}
+
+		provider := model.AIProvider(record.Name)
+		if overrides[provider] == nil {
+			overrides[provider] = ProviderSettings{}
+		}
+		for name, value := range scalarSettings(values) {
+			overrides[provider][name] = value
+		}
+	}
+
+	return overrides, inactive, nil
+}
+
+// scalarSettings keeps the string, number and boolean values of a config
+// object, skipping lists such as models
+func scalarSettings(values map[string]interface{}) ProviderSettings {
+	settings := ProviderSettings{}
+	for name, value := range values {
+		switch value.(type) {
+		case string, bool, int, float64:
+			settings[name] = fmt.Sprint(value)
+		}
+	}
+	return settings
+}
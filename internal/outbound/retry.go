@@ -0,0 +1,205 @@
+package outbound
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy controls how provider HTTP calls are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is used by providers that are not given a policy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// retryTransport retries requests that fail with a retryable status code or
+// a network error, waiting with jittered exponential backoff or for as long
+// as the upstream asks through Retry-After and rate-limit reset headers
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+// NewRetryTransport wraps base, or http.DefaultTransport when base is nil,
+// with the given retry policy
+func NewRetryTransport(base http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{base: base, policy: policy}
+}
+
+// newHTTPClient creates a provider HTTP client that retries with policy
+func newHTTPClient(timeout time.Duration, policy RetryPolicy) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewRetryTransport(nil, policy),
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			// Each attempt needs a fresh copy of the body
+			if req.Body != nil && req.GetBody == nil {
+				return nil, errBodyNotReplayable
+			}
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+
+		if attempt >= t.policy.MaxAttempts || ctx.Err() != nil || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if delay, ok := retryDelay(resp, time.Now()); ok {
+				// The upstream asked for a longer pause than we are willing
+				// to hold the caller for, so surface the error instead
+				if t.policy.MaxBackoff > 0 && delay > t.policy.MaxBackoff {
+					return resp, nil
+				}
+				wait = delay
+			}
+
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if counter, ok := ctx.Value(retryCounterKey{}).(*atomic.Int32); ok {
+			counter.Add(1)
+		}
+	}
+}
+
+// backoff returns the jittered exponential delay before the next attempt
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.policy.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if t.policy.MaxBackoff > 0 && delay >= t.policy.MaxBackoff {
+			delay = t.policy.MaxBackoff
+			break
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Wait somewhere between half and the full delay
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// shouldRetry reports whether a round trip result is worth retrying
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return IsRetryable(err)
+	}
+	return isRetryableStatus(resp.StatusCode)
+}
+
+// retryDelay reads how long the upstream wants us to wait from Retry-After,
+// retry-after-ms or, for 429 responses, the provider rate-limit reset headers
+func retryDelay(resp *http.Response, now time.Time) (time.Duration, bool) {
+	header := resp.Header
+	if value := header.Get("retry-after-ms"); value != "" {
+		if ms, err := strconv.ParseFloat(value, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return clampDelay(date.Sub(now)), true
+		}
+	}
+
+	// Reset headers are sent on every response but only explain a 429
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	// OpenAI sends durations such as "1s" or "6m0s"
+	for _, key := range []string{"x-ratelimit-reset-requests", "x-ratelimit-reset-tokens"} {
+		if value := header.Get(key); value != "" {
+			if delay, err := time.ParseDuration(value); err == nil {
+				return clampDelay(delay), true
+			}
+		}
+	}
+
+	// Anthropic sends RFC 3339 timestamps
+	for _, key := range []string{"anthropic-ratelimit-requests-reset", "anthropic-ratelimit-tokens-reset"} {
+		if value := header.Get(key); value != "" {
+			if reset, err := time.Parse(time.RFC3339, value); err == nil {
+				return clampDelay(reset.Sub(now)), true
+			}
+		}
+	}
+
+	// IETF draft RateLimit-Reset is a number of seconds
+	if value := header.Get("RateLimit-Reset"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	return 0, false
+}
+
+func clampDelay(delay time.Duration) time.Duration {
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// retryCounterKey is the context key for the per-call retry counter
+type retryCounterKey struct{}
+
+// withRetryCounter returns a context that counts the retries made by
+// retryTransport for requests sent with it
+func withRetryCounter(ctx context.Context) (context.Context, *atomic.Int32) {
+	counter := &atomic.Int32{}
+	return context.WithValue(ctx, retryCounterKey{}, counter), counter
+}
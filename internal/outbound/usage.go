@@ -0,0 +1,23 @@
+package outbound
+
+import (
+	"ai-service/internal/model"
+)
+
+// charsPerToken is the rough ratio used when a provider reports no usage
+const charsPerToken = 4
+
+// estimateTokens approximates the token count of text
+func estimateTokens(text string) int {
+	return len(text) / charsPerToken
+}
+
+// estimateUsage approximates the usage of a request and its completion,
+// counting the system message and prior turns as prompt tokens
+func estimateUsage(req *model.GenerationRequest, completion string) model.TokenUsage {
+	promptTokens := estimateTokens(req.SystemMsg) + estimateTokens(req.Prompt)
+	for _, message := range req.Messages {
+		promptTokens += estimateTokens(message.Content)
+	}
+	return model.NewTokenUsage(promptTokens, estimateTokens(completion), true)
+}
@@ -1,13 +1,240 @@
 package repository
 
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository/generated"
+	"ai-service/internal/util/exception"
+)
+
+// AIProviderRepository defines the interface for provider configuration data access
 type AIProviderRepository interface {
-	// AI provider repository methods will be defined here
+	List(ctx context.Context) ([]*model.ProviderRecord, error)
+	GetByName(ctx context.Context, name string) (*model.ProviderRecord, error)
+	SetActive(ctx context.Context, name string, active bool) (*model.ProviderRecord, error)
+	UpdateConfig(ctx context.Context, name string, patch json.RawMessage) (*model.ProviderRecord, error)
+
+	// Encrypted provider API keys
+	ListKeys(ctx context.Context) ([]*model.ProviderKey, error)
+	GetKey(ctx context.Context, name string) (*model.ProviderKey, error)
+	SaveKey(ctx context.Context, key *model.ProviderKey, audit *model.ProviderKeyAudit) error
+	DeleteKey(ctx context.Context, name string, audit *model.ProviderKeyAudit) error
+	ListKeyAudit(ctx context.Context, name string, limit int) ([]*model.ProviderKeyAudit, error)
 }
 
+// aiProviderRepository implements AIProviderRepository
 type aiProviderRepository struct {
-	// dependencies will be injected
+	db      *sql.DB
+	queries *generated.Queries
 }
 
-func NewAIProviderRepository(redis interface{}) AIProviderRepository {
-	return &aiProviderRepository{}
+// NewAIProviderRepository creates a new provider repository
+func NewAIProviderRepository(db *sql.DB) AIProviderRepository {
+	return &aiProviderRepository{
+		db:      db,
+		queries: generated.New(db),
+	}
+}
+
+// List retrieves every provider row ordered by name
+func (r *aiProviderRepository) List(ctx context.Context) ([]*model.ProviderRecord, error) {
+	rows, err := r.queries.ListProviders(ctx)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	providers := []*model.ProviderRecord{}
+	for _, row := range rows {
+		providers = append(providers, toProviderRecord(row))
+	}
+
+	return providers, nil
+}
+
+// GetByName retrieves the row of a provider
+func (r *aiProviderRepository) GetByName(ctx context.Context, name string) (*model.ProviderRecord, error) {
+	row, err := r.queries.GetProviderByName(ctx, name)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return toProviderRecord(row), nil
+}
+
+// SetActive enables or disables a provider, creating its row if needed
+func (r *aiProviderRepository) SetActive(ctx context.Context, name string, active bool) (*model.ProviderRecord, error) {
+	row, err := r.queries.SetProviderActive(ctx, generated.SetProviderActiveParams{
+		Name:     name,
+		IsActive: sql.NullBool{Bool: active, Valid: true},
+	})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return toProviderRecord(row), nil
+}
+
+// UpdateConfig merges patch into the provider's config, creating its row if
+// needed. Top-level keys in patch replace the stored ones and null values
+// remove them.
+func (r *aiProviderRepository) UpdateConfig(ctx context.Context, name string, patch json.RawMessage) (*model.ProviderRecord, error) {
+	row, err := r.queries.UpdateProviderConfig(ctx, generated.UpdateProviderConfigParams{
+		Name:  name,
+		Patch: patch,
+	})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return toProviderRecord(row), nil
+}
+
+// toProviderRecord converts a providers row to its model. Rows without
+// is_active predate the column and count as active.
+func toProviderRecord(row generated.Provider) *model.ProviderRecord {
+	provider := &model.ProviderRecord{
+		ID:        row.ID.String(),
+		Name:      row.Name,
+		IsActive:  !row.IsActive.Valid || row.IsActive.Bool,
+		HasAPIKey: row.EncryptedApiKey != nil,
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+	if row.Config.Valid {
+		provider.Config = row.Config.RawMessage
+	}
+	if row.ApiKeyUpdatedAt.Valid {
+		provider.APIKeyUpdatedAt = &row.ApiKeyUpdatedAt.Time
+	}
+	return provider
+}
+
+// ListKeys retrieves every stored provider API key, still encrypted
+func (r *aiProviderRepository) ListKeys(ctx context.Context) ([]*model.ProviderKey, error) {
+	rows, err := r.queries.ListProviderKeys(ctx)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	keys := []*model.ProviderKey{}
+	for _, row := range rows {
+		keys = append(keys, &model.ProviderKey{
+			Provider:         row.Name,
+			EncryptedAPIKey:  row.EncryptedApiKey,
+			EncryptedDataKey: row.EncryptedDataKey,
+			UpdatedAt:        row.ApiKeyUpdatedAt.Time,
+		})
+	}
+
+	return keys, nil
+}
+
+// GetKey retrieves the stored API key of a provider, still encrypted
+func (r *aiProviderRepository) GetKey(ctx context.Context, name string) (*model.ProviderKey, error) {
+	row, err := r.queries.GetProviderKey(ctx, name)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return &model.ProviderKey{
+		Provider:         row.Name,
+		EncryptedAPIKey:  row.EncryptedApiKey,
+		EncryptedDataKey: row.EncryptedDataKey,
+		UpdatedAt:        row.ApiKeyUpdatedAt.Time,
+	}, nil
+}
+
+// SaveKey stores an encrypted provider API key, creating the provider's row
+// if needed, and records audit in the same transaction
+func (r *aiProviderRepository) SaveKey(ctx context.Context, key *model.ProviderKey, audit *model.ProviderKeyAudit) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	updatedAt, err := queries.SaveProviderKey(ctx, generated.SaveProviderKeyParams{
+		Name:             key.Provider,
+		EncryptedApiKey:  key.EncryptedAPIKey,
+		EncryptedDataKey: key.EncryptedDataKey,
+	})
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+	key.UpdatedAt = updatedAt.Time
+
+	if err := insertKeyAudit(ctx, queries, audit); err != nil {
+		return err
+	}
+
+	return exception.TranslateDatabaseError(ctx, tx.Commit())
+}
+
+// DeleteKey removes the stored API key of a provider and records audit in
+// the same transaction
+func (r *aiProviderRepository) DeleteKey(ctx context.Context, name string, audit *model.ProviderKeyAudit) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	affected, err := queries.DeleteProviderKey(ctx, name)
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+	if affected == 0 {
+		return exception.TranslateDatabaseError(ctx, sql.ErrNoRows)
+	}
+	if err := insertKeyAudit(ctx, queries, audit); err != nil {
+		return err
+	}
+
+	return exception.TranslateDatabaseError(ctx, tx.Commit())
+}
+
+// ListKeyAudit retrieves the most recent key changes of a provider, newest
+// first
+func (r *aiProviderRepository) ListKeyAudit(ctx context.Context, name string, limit int) ([]*model.ProviderKeyAudit, error) {
+	rows, err := r.queries.ListProviderKeyAudit(ctx, generated.ListProviderKeyAuditParams{
+		Provider: name,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	entries := []*model.ProviderKeyAudit{}
+	for _, row := range rows {
+		entries = append(entries, &model.ProviderKeyAudit{
+			ID:        row.ID.String(),
+			Provider:  row.Provider,
+			Action:    row.Action,
+			Actor:     row.Actor,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+
+	return entries, nil
+}
+
+// insertKeyAudit records a key change with queries bound to a transaction
+func insertKeyAudit(ctx context.Context, queries *generated.Queries, audit *model.ProviderKeyAudit) error {
+	row, err := queries.CreateProviderKeyAudit(ctx, generated.CreateProviderKeyAuditParams{
+		Provider: audit.Provider,
+		Action:   audit.Action,
+		Actor:    audit.Actor,
+	})
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+
+	audit.ID = row.ID.String()
+	audit.CreatedAt = row.CreatedAt.Time
+	return nil
 }
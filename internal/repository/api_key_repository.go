@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository/generated"
+	"ai-service/internal/util/exception"
+
+	"github.com/sqlc-dev/pqtype"
+)
+
+// APIKeyRepository defines the interface for client API key data access
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	GetByID(ctx context.Context, id string) (*model.APIKey, error)
+	GetActiveByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	List(ctx context.Context) ([]*model.APIKey, error)
+	Rotate(ctx context.Context, id, keyHash, keyPrefix string) error
+	Revoke(ctx context.Context, id string) error
+	UpdateQuota(ctx context.Context, id string, quota *model.QuotaLimits) error
+	RecordUsage(ctx context.Context, id string) error
+}
+
+// apiKeyRepository implements APIKeyRepository
+type apiKeyRepository struct {
+	queries *generated.Queries
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{
+		queries: generated.New(db),
+	}
+}
+
+// Create saves a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	quota, err := quotaJSON(key.Quota)
+	if err != nil {
+		return err
+	}
+
+	row, err := r.queries.CreateAPIKey(ctx, generated.CreateAPIKeyParams{
+		Name:             key.Name,
+		KeyPrefix:        key.KeyPrefix,
+		KeyHash:          key.KeyHash,
+		Role:             key.Role,
+		AllowedProviders: nonNil(key.AllowedProviders),
+		AllowedModels:    nonNil(key.AllowedModels),
+		Quota:            quota,
+	})
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+
+	key.ID = row.ID.String()
+	key.IsActive = row.IsActive.Bool
+	key.CreatedAt = row.CreatedAt.Time
+	key.UpdatedAt = row.UpdatedAt.Time
+	return nil
+}
+
+// GetByID retrieves an API key by ID, including revoked keys
+func (r *apiKeyRepository) GetByID(ctx context.Context, id string) (*model.APIKey, error) {
+	keyID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := r.queries.GetAPIKeyByID(ctx, keyID)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return toAPIKey(row)
+}
+
+// GetActiveByHash retrieves the active API key with the given hash
+func (r *apiKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	row, err := r.queries.GetActiveAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return toAPIKey(row)
+}
+
+// List retrieves every API key, newest first
+func (r *apiKeyRepository) List(ctx context.Context) ([]*model.APIKey, error) {
+	rows, err := r.queries.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	keys := []*model.APIKey{}
+	for _, row := range rows {
+		key, err := toAPIKey(row)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Rotate replaces the secret of an active key, invalidating the old one
+func (r *apiKeyRepository) Rotate(ctx context.Context, id, keyHash, keyPrefix string) error {
+	keyID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.queries.RotateAPIKey(ctx, generated.RotateAPIKeyParams{
+		ID:        keyID,
+		KeyHash:   keyHash,
+		KeyPrefix: keyPrefix,
+	})
+	return exception.TranslateDatabaseError(ctx, err)
+}
+
+// Revoke permanently deactivates a key
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string) error {
+	keyID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.queries.RevokeAPIKey(ctx, keyID)
+	return exception.TranslateDatabaseError(ctx, err)
+}
+
+// UpdateQuota replaces the key's quota; nil restores the default limits
+func (r *apiKeyRepository) UpdateQuota(ctx context.Context, id string, quota *model.QuotaLimits) error {
+	keyID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	quotaValue, err := quotaJSON(quota)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.queries.UpdateAPIKeyQuota(ctx, generated.UpdateAPIKeyQuotaParams{
+		ID:    keyID,
+		Quota: quotaValue,
+	})
+	return exception.TranslateDatabaseError(ctx, err)
+}
+
+// RecordUsage increments the key's usage counter
+func (r *apiKeyRepository) RecordUsage(ctx context.Context, id string) error {
+	keyID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	return exception.TranslateDatabaseError(ctx, r.queries.RecordAPIKeyUsage(ctx, keyID))
+}
+
+// toAPIKey converts an api_keys row to its model
+func toAPIKey(row generated.ApiKey) (*model.APIKey, error) {
+	key := &model.APIKey{
+		ID:               row.ID.String(),
+		Name:             row.Name,
+		KeyPrefix:        row.KeyPrefix,
+		KeyHash:          row.KeyHash,
+		Role:             row.Role,
+		AllowedProviders: row.AllowedProviders,
+		AllowedModels:    row.AllowedModels,
+		IsActive:         row.IsActive.Bool,
+		UsageCount:       row.UsageCount,
+		CreatedAt:        row.CreatedAt.Time,
+		UpdatedAt:        row.UpdatedAt.Time,
+	}
+
+	if row.Quota.Valid && len(row.Quota.RawMessage) > 0 {
+		if err := json.Unmarshal(row.Quota.RawMessage, &key.Quota); err != nil {
+			return nil, err
+		}
+	}
+	if row.LastUsedAt.Valid {
+		key.LastUsedAt = &row.LastUsedAt.Time
+	}
+	if row.RevokedAt.Valid {
+		key.RevokedAt = &row.RevokedAt.Time
+	}
+
+	return key, nil
+}
+
+// quotaJSON encodes a quota for the JSONB column, storing NULL when no
+// limit is set
+func quotaJSON(quota *model.QuotaLimits) (pqtype.NullRawMessage, error) {
+	if quota == nil || quota.IsZero() {
+		return pqtype.NullRawMessage{}, nil
+	}
+	data, err := json.Marshal(quota)
+	if err != nil {
+		return pqtype.NullRawMessage{}, err
+	}
+	return pqtype.NullRawMessage{RawMessage: data, Valid: true}, nil
+}
+
+// nonNil maps a nil slice to an empty one so NOT NULL array columns accept it
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository/generated"
+	"ai-service/internal/util/exception"
+)
+
+// ConversationRepository defines the interface for conversation data access
+type ConversationRepository interface {
+	Create(ctx context.Context, conversation *model.Conversation) error
+	GetByID(ctx context.Context, id string) (*model.Conversation, error)
+	List(ctx context.Context, limit, offset int) ([]*model.Conversation, error)
+	Update(ctx context.Context, conversation *model.Conversation) error
+	Delete(ctx context.Context, id string) error
+	AddMessages(ctx context.Context, conversationID string, messages ...*model.ConversationMessage) error
+	GetMessages(ctx context.Context, conversationID string) ([]*model.ConversationMessage, error)
+}
+
+// conversationRepository implements ConversationRepository
+type conversationRepository struct {
+	db      *sql.DB
+	queries *generated.Queries
+}
+
+// NewConversationRepository creates a new conversation repository
+func NewConversationRepository(db *sql.DB) ConversationRepository {
+	return &conversationRepository{
+		db:      db,
+		queries: generated.New(db),
+	}
+}
+
+// Create saves a new conversation
+func (r *conversationRepository) Create(ctx context.Context, conversation *model.Conversation) error {
+	row, err := r.queries.CreateConversation(ctx, generated.CreateConversationParams{
+		Title:         conversation.Title,
+		Provider:      conversation.Provider,
+		Model:         conversation.Model,
+		SystemMessage: conversation.SystemMsg,
+	})
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+
+	conversation.ID = row.ID.String()
+	conversation.CreatedAt = row.CreatedAt.Time
+	conversation.UpdatedAt = row.UpdatedAt.Time
+	return nil
+}
+
+// GetByID retrieves a conversation by ID without its messages
+func (r *conversationRepository) GetByID(ctx context.Context, id string) (*model.Conversation, error) {
+	conversationID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := r.queries.GetConversationByID(ctx, conversationID)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return toConversation(generated.ListConversationsRow(row)), nil
+}
+
+// List retrieves conversations ordered by most recent activity
+func (r *conversationRepository) List(ctx context.Context, limit, offset int) ([]*model.Conversation, error) {
+	rows, err := r.queries.ListConversations(ctx, generated.ListConversationsParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	conversations := []*model.Conversation{}
+	for _, row := range rows {
+		conversations = append(conversations, toConversation(row))
+	}
+
+	return conversations, nil
+}
+
+// Update saves the editable fields of a conversation
+func (r *conversationRepository) Update(ctx context.Context, conversation *model.Conversation) error {
+	conversationID, err := parseID(conversation.ID)
+	if err != nil {
+		return err
+	}
+
+	updatedAt, err := r.queries.UpdateConversation(ctx, generated.UpdateConversationParams{
+		ID:            conversationID,
+		Title:         conversation.Title,
+		Model:         conversation.Model,
+		SystemMessage: conversation.SystemMsg,
+	})
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+
+	conversation.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+// Delete removes a conversation and, by cascade, its messages
+func (r *conversationRepository) Delete(ctx context.Context, id string) error {
+	conversationID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	return exception.TranslateDatabaseError(ctx, r.queries.DeleteConversation(ctx, conversationID))
+}
+
+// AddMessages appends turns to a conversation in a single transaction
+func (r *conversationRepository) AddMessages(ctx context.Context, conversationID string, messages ...*model.ConversationMessage) error {
+	id, err := parseID(conversationID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	for _, message := range messages {
+		row, err := queries.CreateMessage(ctx, generated.CreateMessageParams{
+			ConversationID: id,
+			Role:           message.Role,
+			Content:        message.Content,
+			TokensUsed:     int32(message.TokensUsed),
+		})
+		if err != nil {
+			return exception.TranslateDatabaseError(ctx, err)
+		}
+		message.ID = row.ID.String()
+		message.ConversationID = conversationID
+		message.CreatedAt = row.CreatedAt.Time
+	}
+
+	if err := queries.TouchConversation(ctx, id); err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return exception.TranslateDatabaseError(ctx, tx.Commit())
+}
+
+// GetMessages retrieves every turn of a conversation in order
+func (r *conversationRepository) GetMessages(ctx context.Context, conversationID string) ([]*model.ConversationMessage, error) {
+	id, err := parseID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.queries.GetMessagesByConversation(ctx, id)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	messages := []*model.ConversationMessage{}
+	for _, row := range rows {
+		messages = append(messages, &model.ConversationMessage{
+			ID:             row.ID.String(),
+			ConversationID: row.ConversationID.String(),
+			Role:           row.Role,
+			Content:        row.Content,
+			TokensUsed:     int(row.TokensUsed),
+			CreatedAt:      row.CreatedAt.Time,
+		})
+	}
+
+	return messages, nil
+}
+
+// toConversation converts a conversations row with its message count to
+// its model
+func toConversation(row generated.ListConversationsRow) *model.Conversation {
+	return &model.Conversation{
+		ID:           row.ID.String(),
+		Title:        row.Title,
+		Provider:     row.Provider,
+		Model:        row.Model,
+		SystemMsg:    row.SystemMessage,
+		CreatedAt:    row.CreatedAt.Time,
+		UpdatedAt:    row.UpdatedAt.Time,
+		MessageCount: int(row.MessageCount),
+	}
+}
@@ -0,0 +1,223 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: api_keys.sql
+
+package generated
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
+)
+
+const CreateAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    name, key_prefix, key_hash, role, allowed_providers, allowed_models, quota
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, provider, key_hash, is_active, created_at, updated_at, name, key_prefix, role, allowed_providers, allowed_models, usage_count, last_used_at, revoked_at, quota
+`
+
+type CreateAPIKeyParams struct {
+	Name             string                `json:"name"`
+	KeyPrefix        string                `json:"key_prefix"`
+	KeyHash          string                `json:"key_hash"`
+	Role             string                `json:"role"`
+	AllowedProviders []string              `json:"allowed_providers"`
+	AllowedModels    []string              `json:"allowed_models"`
+	Quota            pqtype.NullRawMessage `json:"quota"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, CreateAPIKey,
+		arg.Name,
+		arg.KeyPrefix,
+		arg.KeyHash,
+		arg.Role,
+		pq.Array(arg.AllowedProviders),
+		pq.Array(arg.AllowedModels),
+		arg.Quota,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.KeyHash,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.KeyPrefix,
+		&i.Role,
+		pq.Array(&i.AllowedProviders),
+		pq.Array(&i.AllowedModels),
+		&i.UsageCount,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.Quota,
+	)
+	return i, err
+}
+
+const GetAPIKeyByID = `-- name: GetAPIKeyByID :one
+SELECT id, provider, key_hash, is_active, created_at, updated_at, name, key_prefix, role, allowed_providers, allowed_models, usage_count, last_used_at, revoked_at, quota FROM api_keys WHERE id = $1
+`
+
+func (q *Queries) GetAPIKeyByID(ctx context.Context, id uuid.UUID) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, GetAPIKeyByID, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.KeyHash,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.KeyPrefix,
+		&i.Role,
+		pq.Array(&i.AllowedProviders),
+		pq.Array(&i.AllowedModels),
+		&i.UsageCount,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.Quota,
+	)
+	return i, err
+}
+
+const GetActiveAPIKeyByHash = `-- name: GetActiveAPIKeyByHash :one
+SELECT id, provider, key_hash, is_active, created_at, updated_at, name, key_prefix, role, allowed_providers, allowed_models, usage_count, last_used_at, revoked_at, quota FROM api_keys WHERE key_hash = $1 AND is_active = true
+`
+
+func (q *Queries) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, GetActiveAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.KeyHash,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.KeyPrefix,
+		&i.Role,
+		pq.Array(&i.AllowedProviders),
+		pq.Array(&i.AllowedModels),
+		&i.UsageCount,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.Quota,
+	)
+	return i, err
+}
+
+const ListAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, provider, key_hash, is_active, created_at, updated_at, name, key_prefix, role, allowed_providers, allowed_models, usage_count, last_used_at, revoked_at, quota FROM api_keys ORDER BY created_at DESC
+`
+
+func (q *Queries) ListAPIKeys(ctx context.Context) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, ListAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKey{}
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.KeyHash,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.KeyPrefix,
+			&i.Role,
+			pq.Array(&i.AllowedProviders),
+			pq.Array(&i.AllowedModels),
+			&i.UsageCount,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.Quota,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const RecordAPIKeyUsage = `-- name: RecordAPIKeyUsage :exec
+UPDATE api_keys
+SET usage_count = usage_count + 1, last_used_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RecordAPIKeyUsage(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, RecordAPIKeyUsage, id)
+	return err
+}
+
+const RevokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET is_active = false, revoked_at = COALESCE(revoked_at, NOW()), updated_at = NOW()
+WHERE id = $1
+RETURNING id
+`
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, RevokeAPIKey, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const RotateAPIKey = `-- name: RotateAPIKey :one
+UPDATE api_keys
+SET key_hash = $2, key_prefix = $3, updated_at = NOW()
+WHERE id = $1 AND is_active = true
+RETURNING id
+`
+
+type RotateAPIKeyParams struct {
+	ID        uuid.UUID `json:"id"`
+	KeyHash   string    `json:"key_hash"`
+	KeyPrefix string    `json:"key_prefix"`
+}
+
+func (q *Queries) RotateAPIKey(ctx context.Context, arg RotateAPIKeyParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, RotateAPIKey, arg.ID, arg.KeyHash, arg.KeyPrefix)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const UpdateAPIKeyQuota = `-- name: UpdateAPIKeyQuota :one
+UPDATE api_keys
+SET quota = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id
+`
+
+type UpdateAPIKeyQuotaParams struct {
+	ID    uuid.UUID             `json:"id"`
+	Quota pqtype.NullRawMessage `json:"quota"`
+}
+
+func (q *Queries) UpdateAPIKeyQuota(ctx context.Context, arg UpdateAPIKeyQuotaParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, UpdateAPIKeyQuota, arg.ID, arg.Quota)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
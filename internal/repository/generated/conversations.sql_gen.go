@@ -0,0 +1,256 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: conversations.sql
+
+package generated
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const CreateConversation = `-- name: CreateConversation :one
+INSERT INTO conversations (
+    title, provider, model, system_message
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, title, provider, model, system_message, created_at, updated_at
+`
+
+type CreateConversationParams struct {
+	Title         string `json:"title"`
+	Provider      string `json:"provider"`
+	Model         string `json:"model"`
+	SystemMessage string `json:"system_message"`
+}
+
+func (q *Queries) CreateConversation(ctx context.Context, arg CreateConversationParams) (Conversation, error) {
+	row := q.db.QueryRowContext(ctx, CreateConversation,
+		arg.Title,
+		arg.Provider,
+		arg.Model,
+		arg.SystemMessage,
+	)
+	var i Conversation
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Provider,
+		&i.Model,
+		&i.SystemMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const CreateMessage = `-- name: CreateMessage :one
+INSERT INTO messages (
+    conversation_id, role, content, tokens_used
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, conversation_id, role, content, tokens_used, created_at
+`
+
+type CreateMessageParams struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	Role           string    `json:"role"`
+	Content        string    `json:"content"`
+	TokensUsed     int32     `json:"tokens_used"`
+}
+
+func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error) {
+	row := q.db.QueryRowContext(ctx, CreateMessage,
+		arg.ConversationID,
+		arg.Role,
+		arg.Content,
+		arg.TokensUsed,
+	)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.ConversationID,
+		&i.Role,
+		&i.Content,
+		&i.TokensUsed,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const DeleteConversation = `-- name: DeleteConversation :exec
+DELETE FROM conversations WHERE id = $1
+`
+
+func (q *Queries) DeleteConversation(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, DeleteConversation, id)
+	return err
+}
+
+const GetConversationByID = `-- name: GetConversationByID :one
+SELECT
+    c.id, c.title, c.provider, c.model, c.system_message, c.created_at, c.updated_at,
+    (SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id) as message_count
+FROM conversations c
+WHERE c.id = $1
+`
+
+type GetConversationByIDRow struct {
+	ID            uuid.UUID    `json:"id"`
+	Title         string       `json:"title"`
+	Provider      string       `json:"provider"`
+	Model         string       `json:"model"`
+	SystemMessage string       `json:"system_message"`
+	CreatedAt     sql.NullTime `json:"created_at"`
+	UpdatedAt     sql.NullTime `json:"updated_at"`
+	MessageCount  int64        `json:"message_count"`
+}
+
+func (q *Queries) GetConversationByID(ctx context.Context, id uuid.UUID) (GetConversationByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, GetConversationByID, id)
+	var i GetConversationByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Provider,
+		&i.Model,
+		&i.SystemMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MessageCount,
+	)
+	return i, err
+}
+
+const GetMessagesByConversation = `-- name: GetMessagesByConversation :many
+SELECT id, conversation_id, role, content, tokens_used, created_at FROM messages 
+WHERE conversation_id = $1 
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetMessagesByConversation(ctx context.Context, conversationID uuid.UUID) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, GetMessagesByConversation, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Message{}
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.Role,
+			&i.Content,
+			&i.TokensUsed,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListConversations = `-- name: ListConversations :many
+SELECT 
+    c.id, c.title, c.provider, c.model, c.system_message, c.created_at, c.updated_at,
+    (SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id) as message_count
+FROM conversations c
+ORDER BY c.updated_at DESC 
+LIMIT $1 OFFSET $2
+`
+
+type ListConversationsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListConversationsRow struct {
+	ID            uuid.UUID    `json:"id"`
+	Title         string       `json:"title"`
+	Provider      string       `json:"provider"`
+	Model         string       `json:"model"`
+	SystemMessage string       `json:"system_message"`
+	CreatedAt     sql.NullTime `json:"created_at"`
+	UpdatedAt     sql.NullTime `json:"updated_at"`
+	MessageCount  int64        `json:"message_count"`
+}
+
+func (q *Queries) ListConversations(ctx context.Context, arg ListConversationsParams) ([]ListConversationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListConversations, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListConversationsRow{}
+	for rows.Next() {
+		var i ListConversationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Provider,
+			&i.Model,
+			&i.SystemMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MessageCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const TouchConversation = `-- name: TouchConversation :exec
+UPDATE conversations 
+SET updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) TouchConversation(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, TouchConversation, id)
+	return err
+}
+
+const UpdateConversation = `-- name: UpdateConversation :one
+UPDATE conversations 
+SET title = $2, model = $3, system_message = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING updated_at
+`
+
+type UpdateConversationParams struct {
+	ID            uuid.UUID `json:"id"`
+	Title         string    `json:"title"`
+	Model         string    `json:"model"`
+	SystemMessage string    `json:"system_message"`
+}
+
+func (q *Queries) UpdateConversation(ctx context.Context, arg UpdateConversationParams) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, UpdateConversation,
+		arg.ID,
+		arg.Title,
+		arg.Model,
+		arg.SystemMessage,
+	)
+	var updated_at sql.NullTime
+	err := row.Scan(&updated_at)
+	return updated_at, err
+}
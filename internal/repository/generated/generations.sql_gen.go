@@ -0,0 +1,712 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: generations.sql
+
+package generated
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+const CreateGeneration = `-- name: CreateGeneration :one
+INSERT INTO generations (
+    provider, model, prompt, response, tokens_used, duration_ms, status, error_message, comparison_id,
+    requested_provider, failed_attempts, user_id, cost_usd,
+    prompt_tokens, completion_tokens, total_tokens, tokens_estimated
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+) RETURNING id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at, comparison_id, requested_provider, failed_attempts, user_id, cost_usd, prompt_tokens, completion_tokens, total_tokens, tokens_estimated
+`
+
+type CreateGenerationParams struct {
+	Provider          string                `json:"provider"`
+	Model             string                `json:"model"`
+	Prompt            string                `json:"prompt"`
+	Response          string                `json:"response"`
+	TokensUsed        int32                 `json:"tokens_used"`
+	DurationMs        int32                 `json:"duration_ms"`
+	Status            string                `json:"status"`
+	ErrorMessage      sql.NullString        `json:"error_message"`
+	ComparisonID      uuid.NullUUID         `json:"comparison_id"`
+	RequestedProvider sql.NullString        `json:"requested_provider"`
+	FailedAttempts    pqtype.NullRawMessage `json:"failed_attempts"`
+	UserID            sql.NullString        `json:"user_id"`
+	CostUsd           sql.NullFloat64       `json:"cost_usd"`
+	PromptTokens      int32                 `json:"prompt_tokens"`
+	CompletionTokens  int32                 `json:"completion_tokens"`
+	TotalTokens       int32                 `json:"total_tokens"`
+	TokensEstimated   bool                  `json:"tokens_estimated"`
+}
+
+func (q *Queries) CreateGeneration(ctx context.Context, arg CreateGenerationParams) (Generation, error) {
+	row := q.db.QueryRowContext(ctx, CreateGeneration,
+		arg.Provider,
+		arg.Model,
+		arg.Prompt,
+		arg.Response,
+		arg.TokensUsed,
+		arg.DurationMs,
+		arg.Status,
+		arg.ErrorMessage,
+		arg.ComparisonID,
+		arg.RequestedProvider,
+		arg.FailedAttempts,
+		arg.UserID,
+		arg.CostUsd,
+		arg.PromptTokens,
+		arg.CompletionTokens,
+		arg.TotalTokens,
+		arg.TokensEstimated,
+	)
+	var i Generation
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.Model,
+		&i.Prompt,
+		&i.Response,
+		&i.TokensUsed,
+		&i.DurationMs,
+		&i.Status,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ComparisonID,
+		&i.RequestedProvider,
+		&i.FailedAttempts,
+		&i.UserID,
+		&i.CostUsd,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.TotalTokens,
+		&i.TokensEstimated,
+	)
+	return i, err
+}
+
+const DeleteGeneration = `-- name: DeleteGeneration :exec
+DELETE FROM generations WHERE id = $1
+`
+
+func (q *Queries) DeleteGeneration(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, DeleteGeneration, id)
+	return err
+}
+
+const GetGenerationByID = `-- name: GetGenerationByID :one
+SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at, comparison_id, requested_provider, failed_attempts, user_id, cost_usd, prompt_tokens, completion_tokens, total_tokens, tokens_estimated FROM generations WHERE id = $1
+`
+
+func (q *Queries) GetGenerationByID(ctx context.Context, id uuid.UUID) (Generation, error) {
+	row := q.db.QueryRowContext(ctx, GetGenerationByID, id)
+	var i Generation
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.Model,
+		&i.Prompt,
+		&i.Response,
+		&i.TokensUsed,
+		&i.DurationMs,
+		&i.Status,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ComparisonID,
+		&i.RequestedProvider,
+		&i.FailedAttempts,
+		&i.UserID,
+		&i.CostUsd,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.TotalTokens,
+		&i.TokensEstimated,
+	)
+	return i, err
+}
+
+const GetGenerationStats = `-- name: GetGenerationStats :many
+SELECT 
+    provider,
+    COUNT(*) as total_generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as total_tokens,
+    COALESCE(AVG(duration_ms), 0)::float8 as avg_duration_ms,
+    COUNT(CASE WHEN status = 'error' THEN 1 END) as error_count,
+    COUNT(CASE WHEN failed_attempts IS NOT NULL THEN 1 END) as failover_count,
+    COALESCE(SUM(cost_usd), 0)::float8 as total_cost_usd
+FROM generations 
+WHERE created_at >= $1::timestamptz AND created_at <= $2::timestamptz
+GROUP BY provider
+ORDER BY total_generations DESC
+`
+
+type GetGenerationStatsParams struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type GetGenerationStatsRow struct {
+	Provider         string  `json:"provider"`
+	TotalGenerations int64   `json:"total_generations"`
+	TotalTokens      int64   `json:"total_tokens"`
+	AvgDurationMs    float64 `json:"avg_duration_ms"`
+	ErrorCount       int64   `json:"error_count"`
+	FailoverCount    int64   `json:"failover_count"`
+	TotalCostUsd     float64 `json:"total_cost_usd"`
+}
+
+func (q *Queries) GetGenerationStats(ctx context.Context, arg GetGenerationStatsParams) ([]GetGenerationStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, GetGenerationStats, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetGenerationStatsRow{}
+	for rows.Next() {
+		var i GetGenerationStatsRow
+		if err := rows.Scan(
+			&i.Provider,
+			&i.TotalGenerations,
+			&i.TotalTokens,
+			&i.AvgDurationMs,
+			&i.ErrorCount,
+			&i.FailoverCount,
+			&i.TotalCostUsd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetGenerationsByComparisonID = `-- name: GetGenerationsByComparisonID :many
+SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at, comparison_id, requested_provider, failed_attempts, user_id, cost_usd, prompt_tokens, completion_tokens, total_tokens, tokens_estimated FROM generations 
+WHERE comparison_id = $1 
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetGenerationsByComparisonID(ctx context.Context, comparisonID uuid.NullUUID) ([]Generation, error) {
+	rows, err := q.db.QueryContext(ctx, GetGenerationsByComparisonID, comparisonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Generation{}
+	for rows.Next() {
+		var i Generation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Model,
+			&i.Prompt,
+			&i.Response,
+			&i.TokensUsed,
+			&i.DurationMs,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ComparisonID,
+			&i.RequestedProvider,
+			&i.FailedAttempts,
+			&i.UserID,
+			&i.CostUsd,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.TotalTokens,
+			&i.TokensEstimated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetGenerationsByProvider = `-- name: GetGenerationsByProvider :many
+SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at, comparison_id, requested_provider, failed_attempts, user_id, cost_usd, prompt_tokens, completion_tokens, total_tokens, tokens_estimated FROM generations 
+WHERE provider = $1 
+ORDER BY created_at DESC 
+LIMIT $2 OFFSET $3
+`
+
+type GetGenerationsByProviderParams struct {
+	Provider string `json:"provider"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+}
+
+func (q *Queries) GetGenerationsByProvider(ctx context.Context, arg GetGenerationsByProviderParams) ([]Generation, error) {
+	rows, err := q.db.QueryContext(ctx, GetGenerationsByProvider, arg.Provider, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Generation{}
+	for rows.Next() {
+		var i Generation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Model,
+			&i.Prompt,
+			&i.Response,
+			&i.TokensUsed,
+			&i.DurationMs,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ComparisonID,
+			&i.RequestedProvider,
+			&i.FailedAttempts,
+			&i.UserID,
+			&i.CostUsd,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.TotalTokens,
+			&i.TokensEstimated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetGenerationsByStatus = `-- name: GetGenerationsByStatus :many
+SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at, comparison_id, requested_provider, failed_attempts, user_id, cost_usd, prompt_tokens, completion_tokens, total_tokens, tokens_estimated FROM generations 
+WHERE status = $1 
+ORDER BY created_at DESC 
+LIMIT $2 OFFSET $3
+`
+
+type GetGenerationsByStatusParams struct {
+	Status string `json:"status"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) GetGenerationsByStatus(ctx context.Context, arg GetGenerationsByStatusParams) ([]Generation, error) {
+	rows, err := q.db.QueryContext(ctx, GetGenerationsByStatus, arg.Status, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Generation{}
+	for rows.Next() {
+		var i Generation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Model,
+			&i.Prompt,
+			&i.Response,
+			&i.TokensUsed,
+			&i.DurationMs,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ComparisonID,
+			&i.RequestedProvider,
+			&i.FailedAttempts,
+			&i.UserID,
+			&i.CostUsd,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.TotalTokens,
+			&i.TokensEstimated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetProviderStats = `-- name: GetProviderStats :one
+SELECT 
+    COUNT(*) as total_generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as total_tokens,
+    COALESCE(AVG(duration_ms), 0)::float8 as avg_duration_ms,
+    COUNT(CASE WHEN status = 'error' THEN 1 END) as error_count,
+    COUNT(CASE WHEN failed_attempts IS NOT NULL THEN 1 END) as failover_count,
+    COALESCE(SUM(cost_usd), 0)::float8 as total_cost_usd
+FROM generations 
+WHERE provider = $1 AND created_at >= $2::timestamptz AND created_at <= $3::timestamptz
+`
+
+type GetProviderStatsParams struct {
+	Provider  string    `json:"provider"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type GetProviderStatsRow struct {
+	TotalGenerations int64   `json:"total_generations"`
+	TotalTokens      int64   `json:"total_tokens"`
+	AvgDurationMs    float64 `json:"avg_duration_ms"`
+	ErrorCount       int64   `json:"error_count"`
+	FailoverCount    int64   `json:"failover_count"`
+	TotalCostUsd     float64 `json:"total_cost_usd"`
+}
+
+func (q *Queries) GetProviderStats(ctx context.Context, arg GetProviderStatsParams) (GetProviderStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, GetProviderStats, arg.Provider, arg.StartDate, arg.EndDate)
+	var i GetProviderStatsRow
+	err := row.Scan(
+		&i.TotalGenerations,
+		&i.TotalTokens,
+		&i.AvgDurationMs,
+		&i.ErrorCount,
+		&i.FailoverCount,
+		&i.TotalCostUsd,
+	)
+	return i, err
+}
+
+const GetProviderUsage = `-- name: GetProviderUsage :one
+SELECT COALESCE(SUM(tokens_used), 0)::bigint as tokens, COUNT(*) as requests
+FROM generations
+WHERE provider = $1 AND created_at >= $2::timestamptz
+`
+
+type GetProviderUsageParams struct {
+	Provider string    `json:"provider"`
+	Since    time.Time `json:"since"`
+}
+
+type GetProviderUsageRow struct {
+	Tokens   int64 `json:"tokens"`
+	Requests int64 `json:"requests"`
+}
+
+func (q *Queries) GetProviderUsage(ctx context.Context, arg GetProviderUsageParams) (GetProviderUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, GetProviderUsage, arg.Provider, arg.Since)
+	var i GetProviderUsageRow
+	err := row.Scan(&i.Tokens, &i.Requests)
+	return i, err
+}
+
+const GetRecentGenerations = `-- name: GetRecentGenerations :many
+SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at, comparison_id, requested_provider, failed_attempts, user_id, cost_usd, prompt_tokens, completion_tokens, total_tokens, tokens_estimated FROM generations 
+ORDER BY created_at DESC 
+LIMIT $1 OFFSET $2
+`
+
+type GetRecentGenerationsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) GetRecentGenerations(ctx context.Context, arg GetRecentGenerationsParams) ([]Generation, error) {
+	rows, err := q.db.QueryContext(ctx, GetRecentGenerations, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Generation{}
+	for rows.Next() {
+		var i Generation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Model,
+			&i.Prompt,
+			&i.Response,
+			&i.TokensUsed,
+			&i.DurationMs,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ComparisonID,
+			&i.RequestedProvider,
+			&i.FailedAttempts,
+			&i.UserID,
+			&i.CostUsd,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.TotalTokens,
+			&i.TokensEstimated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetSpendByDay = `-- name: GetSpendByDay :many
+SELECT TO_CHAR(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') as key, COUNT(*) as generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as tokens, COALESCE(SUM(cost_usd), 0)::float8 as cost_usd
+FROM generations
+WHERE created_at >= $1::timestamptz AND created_at <= $2::timestamptz
+GROUP BY 1
+ORDER BY key
+`
+
+type GetSpendByDayParams struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type GetSpendByDayRow struct {
+	Key         string  `json:"key"`
+	Generations int64   `json:"generations"`
+	Tokens      int64   `json:"tokens"`
+	CostUsd     float64 `json:"cost_usd"`
+}
+
+func (q *Queries) GetSpendByDay(ctx context.Context, arg GetSpendByDayParams) ([]GetSpendByDayRow, error) {
+	rows, err := q.db.QueryContext(ctx, GetSpendByDay, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSpendByDayRow{}
+	for rows.Next() {
+		var i GetSpendByDayRow
+		if err := rows.Scan(
+			&i.Key,
+			&i.Generations,
+			&i.Tokens,
+			&i.CostUsd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetSpendByModel = `-- name: GetSpendByModel :many
+SELECT model as key, COUNT(*) as generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as tokens, COALESCE(SUM(cost_usd), 0)::float8 as cost_usd
+FROM generations
+WHERE created_at >= $1::timestamptz AND created_at <= $2::timestamptz
+GROUP BY 1
+ORDER BY cost_usd DESC, key
+`
+
+type GetSpendByModelParams struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type GetSpendByModelRow struct {
+	Key         string  `json:"key"`
+	Generations int64   `json:"generations"`
+	Tokens      int64   `json:"tokens"`
+	CostUsd     float64 `json:"cost_usd"`
+}
+
+func (q *Queries) GetSpendByModel(ctx context.Context, arg GetSpendByModelParams) ([]GetSpendByModelRow, error) {
+	rows, err := q.db.QueryContext(ctx, GetSpendByModel, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSpendByModelRow{}
+	for rows.Next() {
+		var i GetSpendByModelRow
+		if err := rows.Scan(
+			&i.Key,
+			&i.Generations,
+			&i.Tokens,
+			&i.CostUsd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetSpendByProvider = `-- name: GetSpendByProvider :many
+SELECT provider as key, COUNT(*) as generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as tokens, COALESCE(SUM(cost_usd), 0)::float8 as cost_usd
+FROM generations
+WHERE created_at >= $1::timestamptz AND created_at <= $2::timestamptz
+GROUP BY 1
+ORDER BY cost_usd DESC, key
+`
+
+type GetSpendByProviderParams struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type GetSpendByProviderRow struct {
+	Key         string  `json:"key"`
+	Generations int64   `json:"generations"`
+	Tokens      int64   `json:"tokens"`
+	CostUsd     float64 `json:"cost_usd"`
+}
+
+func (q *Queries) GetSpendByProvider(ctx context.Context, arg GetSpendByProviderParams) ([]GetSpendByProviderRow, error) {
+	rows, err := q.db.QueryContext(ctx, GetSpendByProvider, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSpendByProviderRow{}
+	for rows.Next() {
+		var i GetSpendByProviderRow
+		if err := rows.Scan(
+			&i.Key,
+			&i.Generations,
+			&i.Tokens,
+			&i.CostUsd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetSpendByUser = `-- name: GetSpendByUser :many
+SELECT COALESCE(user_id, '') as key, COUNT(*) as generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as tokens, COALESCE(SUM(cost_usd), 0)::float8 as cost_usd
+FROM generations
+WHERE created_at >= $1::timestamptz AND created_at <= $2::timestamptz
+GROUP BY 1
+ORDER BY cost_usd DESC, key
+`
+
+type GetSpendByUserParams struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type GetSpendByUserRow struct {
+	Key         string  `json:"key"`
+	Generations int64   `json:"generations"`
+	Tokens      int64   `json:"tokens"`
+	CostUsd     float64 `json:"cost_usd"`
+}
+
+func (q *Queries) GetSpendByUser(ctx context.Context, arg GetSpendByUserParams) ([]GetSpendByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, GetSpendByUser, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSpendByUserRow{}
+	for rows.Next() {
+		var i GetSpendByUserRow
+		if err := rows.Scan(
+			&i.Key,
+			&i.Generations,
+			&i.Tokens,
+			&i.CostUsd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetUserUsage = `-- name: GetUserUsage :one
+SELECT COALESCE(SUM(tokens_used), 0)::bigint as tokens, COUNT(*) as requests
+FROM generations
+WHERE user_id = $1::text AND created_at >= $2::timestamptz
+`
+
+type GetUserUsageParams struct {
+	UserID string    `json:"user_id"`
+	Since  time.Time `json:"since"`
+}
+
+type GetUserUsageRow struct {
+	Tokens   int64 `json:"tokens"`
+	Requests int64 `json:"requests"`
+}
+
+func (q *Queries) GetUserUsage(ctx context.Context, arg GetUserUsageParams) (GetUserUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, GetUserUsage, arg.UserID, arg.Since)
+	var i GetUserUsageRow
+	err := row.Scan(&i.Tokens, &i.Requests)
+	return i, err
+}
+
+const UpdateGenerationStatus = `-- name: UpdateGenerationStatus :exec
+UPDATE generations 
+SET status = $2, error_message = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateGenerationStatusParams struct {
+	ID           uuid.UUID      `json:"id"`
+	Status       string         `json:"status"`
+	ErrorMessage sql.NullString `json:"error_message"`
+}
+
+func (q *Queries) UpdateGenerationStatus(ctx context.Context, arg UpdateGenerationStatusParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateGenerationStatus, arg.ID, arg.Status, arg.ErrorMessage)
+	return err
+}
@@ -0,0 +1,115 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+
+package generated
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+type ApiKey struct {
+	ID               uuid.UUID             `json:"id"`
+	Provider         sql.NullString        `json:"provider"`
+	KeyHash          string                `json:"key_hash"`
+	IsActive         sql.NullBool          `json:"is_active"`
+	CreatedAt        sql.NullTime          `json:"created_at"`
+	UpdatedAt        sql.NullTime          `json:"updated_at"`
+	Name             string                `json:"name"`
+	KeyPrefix        string                `json:"key_prefix"`
+	Role             string                `json:"role"`
+	AllowedProviders []string              `json:"allowed_providers"`
+	AllowedModels    []string              `json:"allowed_models"`
+	UsageCount       int64                 `json:"usage_count"`
+	LastUsedAt       sql.NullTime          `json:"last_used_at"`
+	RevokedAt        sql.NullTime          `json:"revoked_at"`
+	Quota            pqtype.NullRawMessage `json:"quota"`
+}
+
+type Conversation struct {
+	ID            uuid.UUID    `json:"id"`
+	Title         string       `json:"title"`
+	Provider      string       `json:"provider"`
+	Model         string       `json:"model"`
+	SystemMessage string       `json:"system_message"`
+	CreatedAt     sql.NullTime `json:"created_at"`
+	UpdatedAt     sql.NullTime `json:"updated_at"`
+}
+
+type Generation struct {
+	ID                uuid.UUID             `json:"id"`
+	Provider          string                `json:"provider"`
+	Model             string                `json:"model"`
+	Prompt            string                `json:"prompt"`
+	Response          string                `json:"response"`
+	TokensUsed        int32                 `json:"tokens_used"`
+	DurationMs        int32                 `json:"duration_ms"`
+	Status            string                `json:"status"`
+	ErrorMessage      sql.NullString        `json:"error_message"`
+	CreatedAt         sql.NullTime          `json:"created_at"`
+	UpdatedAt         sql.NullTime          `json:"updated_at"`
+	ComparisonID      uuid.NullUUID         `json:"comparison_id"`
+	RequestedProvider sql.NullString        `json:"requested_provider"`
+	FailedAttempts    pqtype.NullRawMessage `json:"failed_attempts"`
+	UserID            sql.NullString        `json:"user_id"`
+	CostUsd           sql.NullFloat64       `json:"cost_usd"`
+	PromptTokens      int32                 `json:"prompt_tokens"`
+	CompletionTokens  int32                 `json:"completion_tokens"`
+	TotalTokens       int32                 `json:"total_tokens"`
+	TokensEstimated   bool                  `json:"tokens_estimated"`
+}
+
+type Message struct {
+	ID             uuid.UUID    `json:"id"`
+	ConversationID uuid.UUID    `json:"conversation_id"`
+	Role           string       `json:"role"`
+	Content        string       `json:"content"`
+	TokensUsed     int32        `json:"tokens_used"`
+	CreatedAt      sql.NullTime `json:"created_at"`
+}
+
+type ModelPricing struct {
+	ID                      uuid.UUID    `json:"id"`
+	Provider                string       `json:"provider"`
+	Model                   string       `json:"model"`
+	PromptUsdPerMillion     float64      `json:"prompt_usd_per_million"`
+	CompletionUsdPerMillion float64      `json:"completion_usd_per_million"`
+	EffectiveFrom           time.Time    `json:"effective_from"`
+	CreatedAt               sql.NullTime `json:"created_at"`
+}
+
+type Provider struct {
+	ID               uuid.UUID             `json:"id"`
+	Name             string                `json:"name"`
+	IsActive         sql.NullBool          `json:"is_active"`
+	Config           pqtype.NullRawMessage `json:"config"`
+	CreatedAt        sql.NullTime          `json:"created_at"`
+	UpdatedAt        sql.NullTime          `json:"updated_at"`
+	EncryptedApiKey  []byte                `json:"encrypted_api_key"`
+	EncryptedDataKey []byte                `json:"encrypted_data_key"`
+	ApiKeyUpdatedAt  sql.NullTime          `json:"api_key_updated_at"`
+}
+
+type ProviderKeyAudit struct {
+	ID        uuid.UUID    `json:"id"`
+	Provider  string       `json:"provider"`
+	Action    string       `json:"action"`
+	Actor     string       `json:"actor"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type Stat struct {
+	ID               uuid.UUID     `json:"id"`
+	Provider         string        `json:"provider"`
+	Date             time.Time     `json:"date"`
+	TotalGenerations sql.NullInt32 `json:"total_generations"`
+	TotalTokens      sql.NullInt32 `json:"total_tokens"`
+	AvgDurationMs    sql.NullInt32 `json:"avg_duration_ms"`
+	ErrorCount       sql.NullInt32 `json:"error_count"`
+	CreatedAt        sql.NullTime  `json:"created_at"`
+	UpdatedAt        sql.NullTime  `json:"updated_at"`
+}
@@ -0,0 +1,84 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: pricing.sql
+
+package generated
+
+import (
+	"context"
+	"database/sql"
+)
+
+const CreateModelPrice = `-- name: CreateModelPrice :one
+INSERT INTO model_pricing (
+    provider, model, prompt_usd_per_million, completion_usd_per_million, effective_from
+) VALUES (
+    $1, $2, $3, $4, COALESCE($5::timestamptz, NOW())
+) RETURNING id, provider, model, prompt_usd_per_million, completion_usd_per_million, effective_from, created_at
+`
+
+type CreateModelPriceParams struct {
+	Provider                string       `json:"provider"`
+	Model                   string       `json:"model"`
+	PromptUsdPerMillion     float64      `json:"prompt_usd_per_million"`
+	CompletionUsdPerMillion float64      `json:"completion_usd_per_million"`
+	EffectiveFrom           sql.NullTime `json:"effective_from"`
+}
+
+func (q *Queries) CreateModelPrice(ctx context.Context, arg CreateModelPriceParams) (ModelPricing, error) {
+	row := q.db.QueryRowContext(ctx, CreateModelPrice,
+		arg.Provider,
+		arg.Model,
+		arg.PromptUsdPerMillion,
+		arg.CompletionUsdPerMillion,
+		arg.EffectiveFrom,
+	)
+	var i ModelPricing
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.Model,
+		&i.PromptUsdPerMillion,
+		&i.CompletionUsdPerMillion,
+		&i.EffectiveFrom,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const ListModelPrices = `-- name: ListModelPrices :many
+SELECT id, provider, model, prompt_usd_per_million, completion_usd_per_million, effective_from, created_at FROM model_pricing
+ORDER BY provider, model, effective_from DESC
+`
+
+func (q *Queries) ListModelPrices(ctx context.Context) ([]ModelPricing, error) {
+	rows, err := q.db.QueryContext(ctx, ListModelPrices)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ModelPricing{}
+	for rows.Next() {
+		var i ModelPricing
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Model,
+			&i.PromptUsdPerMillion,
+			&i.CompletionUsdPerMillion,
+			&i.EffectiveFrom,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,301 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: providers.sql
+
+package generated
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const CreateProviderKeyAudit = `-- name: CreateProviderKeyAudit :one
+INSERT INTO provider_key_audit (provider, action, actor)
+VALUES ($1, $2, $3)
+RETURNING id, created_at
+`
+
+type CreateProviderKeyAuditParams struct {
+	Provider string `json:"provider"`
+	Action   string `json:"action"`
+	Actor    string `json:"actor"`
+}
+
+type CreateProviderKeyAuditRow struct {
+	ID        uuid.UUID    `json:"id"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) CreateProviderKeyAudit(ctx context.Context, arg CreateProviderKeyAuditParams) (CreateProviderKeyAuditRow, error) {
+	row := q.db.QueryRowContext(ctx, CreateProviderKeyAudit, arg.Provider, arg.Action, arg.Actor)
+	var i CreateProviderKeyAuditRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const DeleteProviderKey = `-- name: DeleteProviderKey :execrows
+UPDATE providers
+SET encrypted_api_key = NULL, encrypted_data_key = NULL, api_key_updated_at = NOW(), updated_at = NOW()
+WHERE name = $1 AND encrypted_api_key IS NOT NULL
+`
+
+func (q *Queries) DeleteProviderKey(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, DeleteProviderKey, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const GetProviderByName = `-- name: GetProviderByName :one
+SELECT id, name, is_active, config, created_at, updated_at, encrypted_api_key, encrypted_data_key, api_key_updated_at FROM providers WHERE name = $1
+`
+
+func (q *Queries) GetProviderByName(ctx context.Context, name string) (Provider, error) {
+	row := q.db.QueryRowContext(ctx, GetProviderByName, name)
+	var i Provider
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.IsActive,
+		&i.Config,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EncryptedApiKey,
+		&i.EncryptedDataKey,
+		&i.ApiKeyUpdatedAt,
+	)
+	return i, err
+}
+
+const GetProviderKey = `-- name: GetProviderKey :one
+SELECT name, encrypted_api_key, encrypted_data_key, api_key_updated_at FROM providers
+WHERE name = $1 AND encrypted_api_key IS NOT NULL
+`
+
+type GetProviderKeyRow struct {
+	Name             string       `json:"name"`
+	EncryptedApiKey  []byte       `json:"encrypted_api_key"`
+	EncryptedDataKey []byte       `json:"encrypted_data_key"`
+	ApiKeyUpdatedAt  sql.NullTime `json:"api_key_updated_at"`
+}
+
+func (q *Queries) GetProviderKey(ctx context.Context, name string) (GetProviderKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, GetProviderKey, name)
+	var i GetProviderKeyRow
+	err := row.Scan(
+		&i.Name,
+		&i.EncryptedApiKey,
+		&i.EncryptedDataKey,
+		&i.ApiKeyUpdatedAt,
+	)
+	return i, err
+}
+
+const ListProviderKeyAudit = `-- name: ListProviderKeyAudit :many
+SELECT id, provider, action, actor, created_at FROM provider_key_audit
+WHERE provider = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListProviderKeyAuditParams struct {
+	Provider string `json:"provider"`
+	Limit    int32  `json:"limit"`
+}
+
+func (q *Queries) ListProviderKeyAudit(ctx context.Context, arg ListProviderKeyAuditParams) ([]ProviderKeyAudit, error) {
+	rows, err := q.db.QueryContext(ctx, ListProviderKeyAudit, arg.Provider, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ProviderKeyAudit{}
+	for rows.Next() {
+		var i ProviderKeyAudit
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Action,
+			&i.Actor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListProviderKeys = `-- name: ListProviderKeys :many
+SELECT name, encrypted_api_key, encrypted_data_key, api_key_updated_at FROM providers
+WHERE encrypted_api_key IS NOT NULL
+ORDER BY name
+`
+
+type ListProviderKeysRow struct {
+	Name             string       `json:"name"`
+	EncryptedApiKey  []byte       `json:"encrypted_api_key"`
+	EncryptedDataKey []byte       `json:"encrypted_data_key"`
+	ApiKeyUpdatedAt  sql.NullTime `json:"api_key_updated_at"`
+}
+
+func (q *Queries) ListProviderKeys(ctx context.Context) ([]ListProviderKeysRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListProviderKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProviderKeysRow{}
+	for rows.Next() {
+		var i ListProviderKeysRow
+		if err := rows.Scan(
+			&i.Name,
+			&i.EncryptedApiKey,
+			&i.EncryptedDataKey,
+			&i.ApiKeyUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListProviders = `-- name: ListProviders :many
+SELECT id, name, is_active, config, created_at, updated_at, encrypted_api_key, encrypted_data_key, api_key_updated_at FROM providers
+ORDER BY name
+`
+
+func (q *Queries) ListProviders(ctx context.Context) ([]Provider, error) {
+	rows, err := q.db.QueryContext(ctx, ListProviders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Provider{}
+	for rows.Next() {
+		var i Provider
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.IsActive,
+			&i.Config,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EncryptedApiKey,
+			&i.EncryptedDataKey,
+			&i.ApiKeyUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SaveProviderKey = `-- name: SaveProviderKey :one
+INSERT INTO providers (name, config, encrypted_api_key, encrypted_data_key, api_key_updated_at)
+VALUES ($1, '{}', $2, $3, NOW())
+ON CONFLICT (name) DO UPDATE
+SET encrypted_api_key = EXCLUDED.encrypted_api_key,
+    encrypted_data_key = EXCLUDED.encrypted_data_key,
+    api_key_updated_at = EXCLUDED.api_key_updated_at,
+    updated_at = NOW()
+RETURNING api_key_updated_at
+`
+
+type SaveProviderKeyParams struct {
+	Name             string `json:"name"`
+	EncryptedApiKey  []byte `json:"encrypted_api_key"`
+	EncryptedDataKey []byte `json:"encrypted_data_key"`
+}
+
+func (q *Queries) SaveProviderKey(ctx context.Context, arg SaveProviderKeyParams) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, SaveProviderKey, arg.Name, arg.EncryptedApiKey, arg.EncryptedDataKey)
+	var api_key_updated_at sql.NullTime
+	err := row.Scan(&api_key_updated_at)
+	return api_key_updated_at, err
+}
+
+const SetProviderActive = `-- name: SetProviderActive :one
+INSERT INTO providers (name, is_active, config)
+VALUES ($1, $2, '{}')
+ON CONFLICT (name) DO UPDATE
+SET is_active = EXCLUDED.is_active, updated_at = NOW()
+RETURNING id, name, is_active, config, created_at, updated_at, encrypted_api_key, encrypted_data_key, api_key_updated_at
+`
+
+type SetProviderActiveParams struct {
+	Name     string       `json:"name"`
+	IsActive sql.NullBool `json:"is_active"`
+}
+
+func (q *Queries) SetProviderActive(ctx context.Context, arg SetProviderActiveParams) (Provider, error) {
+	row := q.db.QueryRowContext(ctx, SetProviderActive, arg.Name, arg.IsActive)
+	var i Provider
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.IsActive,
+		&i.Config,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EncryptedApiKey,
+		&i.EncryptedDataKey,
+		&i.ApiKeyUpdatedAt,
+	)
+	return i, err
+}
+
+const UpdateProviderConfig = `-- name: UpdateProviderConfig :one
+INSERT INTO providers (name, config)
+VALUES ($1, jsonb_strip_nulls($2::jsonb))
+ON CONFLICT (name) DO UPDATE
+SET config = jsonb_strip_nulls(COALESCE(providers.config, '{}') || $2::jsonb), updated_at = NOW()
+RETURNING id, name, is_active, config, created_at, updated_at, encrypted_api_key, encrypted_data_key, api_key_updated_at
+`
+
+type UpdateProviderConfigParams struct {
+	Name  string          `json:"name"`
+	Patch json.RawMessage `json:"patch"`
+}
+
+func (q *Queries) UpdateProviderConfig(ctx context.Context, arg UpdateProviderConfigParams) (Provider, error) {
+	row := q.db.QueryRowContext(ctx, UpdateProviderConfig, arg.Name, arg.Patch)
+	var i Provider
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.IsActive,
+		&i.Config,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EncryptedApiKey,
+		&i.EncryptedDataKey,
+		&i.ApiKeyUpdatedAt,
+	)
+	return i, err
+}
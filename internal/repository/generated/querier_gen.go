@@ -0,0 +1,70 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+
+package generated
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Querier interface {
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateConversation(ctx context.Context, arg CreateConversationParams) (Conversation, error)
+	CreateGeneration(ctx context.Context, arg CreateGenerationParams) (Generation, error)
+	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	CreateModelPrice(ctx context.Context, arg CreateModelPriceParams) (ModelPricing, error)
+	CreateProviderKeyAudit(ctx context.Context, arg CreateProviderKeyAuditParams) (CreateProviderKeyAuditRow, error)
+	CreateStats(ctx context.Context, arg CreateStatsParams) (Stat, error)
+	DeleteConversation(ctx context.Context, id uuid.UUID) error
+	DeleteGeneration(ctx context.Context, id uuid.UUID) error
+	DeleteProviderKey(ctx context.Context, name string) (int64, error)
+	DeleteStatsByDate(ctx context.Context, date time.Time) error
+	DeleteStatsByProvider(ctx context.Context, provider string) error
+	GetAPIKeyByID(ctx context.Context, id uuid.UUID) (ApiKey, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetConversationByID(ctx context.Context, id uuid.UUID) (GetConversationByIDRow, error)
+	GetDailyStats(ctx context.Context, arg GetDailyStatsParams) ([]GetDailyStatsRow, error)
+	GetGenerationByID(ctx context.Context, id uuid.UUID) (Generation, error)
+	GetGenerationStats(ctx context.Context, arg GetGenerationStatsParams) ([]GetGenerationStatsRow, error)
+	GetGenerationsByComparisonID(ctx context.Context, comparisonID uuid.NullUUID) ([]Generation, error)
+	GetGenerationsByProvider(ctx context.Context, arg GetGenerationsByProviderParams) ([]Generation, error)
+	GetGenerationsByStatus(ctx context.Context, arg GetGenerationsByStatusParams) ([]Generation, error)
+	GetMessagesByConversation(ctx context.Context, conversationID uuid.UUID) ([]Message, error)
+	GetProviderByName(ctx context.Context, name string) (Provider, error)
+	GetProviderDailyStats(ctx context.Context, arg GetProviderDailyStatsParams) ([]GetProviderDailyStatsRow, error)
+	GetProviderKey(ctx context.Context, name string) (GetProviderKeyRow, error)
+	GetProviderStats(ctx context.Context, arg GetProviderStatsParams) (GetProviderStatsRow, error)
+	GetProviderUsage(ctx context.Context, arg GetProviderUsageParams) (GetProviderUsageRow, error)
+	GetRecentGenerations(ctx context.Context, arg GetRecentGenerationsParams) ([]Generation, error)
+	GetSpendByDay(ctx context.Context, arg GetSpendByDayParams) ([]GetSpendByDayRow, error)
+	GetSpendByModel(ctx context.Context, arg GetSpendByModelParams) ([]GetSpendByModelRow, error)
+	GetSpendByProvider(ctx context.Context, arg GetSpendByProviderParams) ([]GetSpendByProviderRow, error)
+	GetSpendByUser(ctx context.Context, arg GetSpendByUserParams) ([]GetSpendByUserRow, error)
+	GetStatsByDateRange(ctx context.Context, arg GetStatsByDateRangeParams) ([]Stat, error)
+	GetStatsByProvider(ctx context.Context, arg GetStatsByProviderParams) ([]Stat, error)
+	GetTopProviders(ctx context.Context, arg GetTopProvidersParams) ([]GetTopProvidersRow, error)
+	GetUserUsage(ctx context.Context, arg GetUserUsageParams) (GetUserUsageRow, error)
+	ListAPIKeys(ctx context.Context) ([]ApiKey, error)
+	ListConversations(ctx context.Context, arg ListConversationsParams) ([]ListConversationsRow, error)
+	ListModelPrices(ctx context.Context) ([]ModelPricing, error)
+	ListProviderKeyAudit(ctx context.Context, arg ListProviderKeyAuditParams) ([]ProviderKeyAudit, error)
+	ListProviderKeys(ctx context.Context) ([]ListProviderKeysRow, error)
+	ListProviders(ctx context.Context) ([]Provider, error)
+	RecordAPIKeyUsage(ctx context.Context, id uuid.UUID) error
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	RotateAPIKey(ctx context.Context, arg RotateAPIKeyParams) (uuid.UUID, error)
+	SaveProviderKey(ctx context.Context, arg SaveProviderKeyParams) (sql.NullTime, error)
+	SetProviderActive(ctx context.Context, arg SetProviderActiveParams) (Provider, error)
+	TouchConversation(ctx context.Context, id uuid.UUID) error
+	UpdateAPIKeyQuota(ctx context.Context, arg UpdateAPIKeyQuotaParams) (uuid.UUID, error)
+	UpdateConversation(ctx context.Context, arg UpdateConversationParams) (sql.NullTime, error)
+	UpdateGenerationStatus(ctx context.Context, arg UpdateGenerationStatusParams) error
+	UpdateProviderConfig(ctx context.Context, arg UpdateProviderConfigParams) (Provider, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,338 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: stats.sql
+
+package generated
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const CreateStats = `-- name: CreateStats :one
+INSERT INTO stats (
+    provider, date, total_generations, total_tokens, avg_duration_ms, error_count
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) ON CONFLICT (provider, date) 
+DO UPDATE SET 
+    total_generations = stats.total_generations + EXCLUDED.total_generations,
+    total_tokens = stats.total_tokens + EXCLUDED.total_tokens,
+    avg_duration_ms = (stats.avg_duration_ms + EXCLUDED.avg_duration_ms) / 2,
+    error_count = stats.error_count + EXCLUDED.error_count,
+    updated_at = NOW()
+RETURNING id, provider, date, total_generations, total_tokens, avg_duration_ms, error_count, created_at, updated_at
+`
+
+type CreateStatsParams struct {
+	Provider         string        `json:"provider"`
+	Date             time.Time     `json:"date"`
+	TotalGenerations sql.NullInt32 `json:"total_generations"`
+	TotalTokens      sql.NullInt32 `json:"total_tokens"`
+	AvgDurationMs    sql.NullInt32 `json:"avg_duration_ms"`
+	ErrorCount       sql.NullInt32 `json:"error_count"`
+}
+
+func (q *Queries) CreateStats(ctx context.Context, arg CreateStatsParams) (Stat, error) {
+	row := q.db.QueryRowContext(ctx, CreateStats,
+		arg.Provider,
+		arg.Date,
+		arg.TotalGenerations,
+		arg.TotalTokens,
+		arg.AvgDurationMs,
+		arg.ErrorCount,
+	)
+	var i Stat
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.Date,
+		&i.TotalGenerations,
+		&i.TotalTokens,
+		&i.AvgDurationMs,
+		&i.ErrorCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const DeleteStatsByDate = `-- name: DeleteStatsByDate :exec
+DELETE FROM stats WHERE date = $1
+`
+
+func (q *Queries) DeleteStatsByDate(ctx context.Context, date time.Time) error {
+	_, err := q.db.ExecContext(ctx, DeleteStatsByDate, date)
+	return err
+}
+
+const DeleteStatsByProvider = `-- name: DeleteStatsByProvider :exec
+DELETE FROM stats WHERE provider = $1
+`
+
+func (q *Queries) DeleteStatsByProvider(ctx context.Context, provider string) error {
+	_, err := q.db.ExecContext(ctx, DeleteStatsByProvider, provider)
+	return err
+}
+
+const GetDailyStats = `-- name: GetDailyStats :many
+SELECT 
+    date,
+    SUM(total_generations) as total_generations,
+    SUM(total_tokens) as total_tokens,
+    AVG(avg_duration_ms) as avg_duration_ms,
+    SUM(error_count) as error_count
+FROM stats 
+WHERE date >= $1 AND date <= $2
+GROUP BY date
+ORDER BY date DESC
+`
+
+type GetDailyStatsParams struct {
+	Date   time.Time `json:"date"`
+	Date_2 time.Time `json:"date_2"`
+}
+
+type GetDailyStatsRow struct {
+	Date             time.Time `json:"date"`
+	TotalGenerations int64     `json:"total_generations"`
+	TotalTokens      int64     `json:"total_tokens"`
+	AvgDurationMs    float64   `json:"avg_duration_ms"`
+	ErrorCount       int64     `json:"error_count"`
+}
+
+func (q *Queries) GetDailyStats(ctx context.Context, arg GetDailyStatsParams) ([]GetDailyStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, GetDailyStats, arg.Date, arg.Date_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetDailyStatsRow{}
+	for rows.Next() {
+		var i GetDailyStatsRow
+		if err := rows.Scan(
+			&i.Date,
+			&i.TotalGenerations,
+			&i.TotalTokens,
+			&i.AvgDurationMs,
+			&i.ErrorCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetProviderDailyStats = `-- name: GetProviderDailyStats :many
+SELECT 
+    provider,
+    date,
+    total_generations,
+    total_tokens,
+    avg_duration_ms,
+    error_count
+FROM stats 
+WHERE provider = $1 AND date >= $2 AND date <= $3
+ORDER BY date DESC
+`
+
+type GetProviderDailyStatsParams struct {
+	Provider string    `json:"provider"`
+	Date     time.Time `json:"date"`
+	Date_2   time.Time `json:"date_2"`
+}
+
+type GetProviderDailyStatsRow struct {
+	Provider         string        `json:"provider"`
+	Date             time.Time     `json:"date"`
+	TotalGenerations sql.NullInt32 `json:"total_generations"`
+	TotalTokens      sql.NullInt32 `json:"total_tokens"`
+	AvgDurationMs    sql.NullInt32 `json:"avg_duration_ms"`
+	ErrorCount       sql.NullInt32 `json:"error_count"`
+}
+
+func (q *Queries) GetProviderDailyStats(ctx context.Context, arg GetProviderDailyStatsParams) ([]GetProviderDailyStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, GetProviderDailyStats, arg.Provider, arg.Date, arg.Date_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetProviderDailyStatsRow{}
+	for rows.Next() {
+		var i GetProviderDailyStatsRow
+		if err := rows.Scan(
+			&i.Provider,
+			&i.Date,
+			&i.TotalGenerations,
+			&i.TotalTokens,
+			&i.AvgDurationMs,
+			&i.ErrorCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetStatsByDateRange = `-- name: GetStatsByDateRange :many
+SELECT id, provider, date, total_generations, total_tokens, avg_duration_ms, error_count, created_at, updated_at FROM stats 
+WHERE date >= $1 AND date <= $2 
+ORDER BY date DESC, provider
+`
+
+type GetStatsByDateRangeParams struct {
+	Date   time.Time `json:"date"`
+	Date_2 time.Time `json:"date_2"`
+}
+
+func (q *Queries) GetStatsByDateRange(ctx context.Context, arg GetStatsByDateRangeParams) ([]Stat, error) {
+	rows, err := q.db.QueryContext(ctx, GetStatsByDateRange, arg.Date, arg.Date_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Stat{}
+	for rows.Next() {
+		var i Stat
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Date,
+			&i.TotalGenerations,
+			&i.TotalTokens,
+			&i.AvgDurationMs,
+			&i.ErrorCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetStatsByProvider = `-- name: GetStatsByProvider :many
+SELECT id, provider, date, total_generations, total_tokens, avg_duration_ms, error_count, created_at, updated_at FROM stats 
+WHERE provider = $1 
+ORDER BY date DESC 
+LIMIT $2 OFFSET $3
+`
+
+type GetStatsByProviderParams struct {
+	Provider string `json:"provider"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+}
+
+func (q *Queries) GetStatsByProvider(ctx context.Context, arg GetStatsByProviderParams) ([]Stat, error) {
+	rows, err := q.db.QueryContext(ctx, GetStatsByProvider, arg.Provider, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Stat{}
+	for rows.Next() {
+		var i Stat
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Date,
+			&i.TotalGenerations,
+			&i.TotalTokens,
+			&i.AvgDurationMs,
+			&i.ErrorCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetTopProviders = `-- name: GetTopProviders :many
+SELECT 
+    provider,
+    SUM(total_generations) as total_generations,
+    SUM(total_tokens) as total_tokens,
+    AVG(avg_duration_ms) as avg_duration_ms,
+    SUM(error_count) as error_count
+FROM stats 
+WHERE date >= $1 AND date <= $2
+GROUP BY provider
+ORDER BY total_generations DESC
+LIMIT $3
+`
+
+type GetTopProvidersParams struct {
+	Date   time.Time `json:"date"`
+	Date_2 time.Time `json:"date_2"`
+	Limit  int32     `json:"limit"`
+}
+
+type GetTopProvidersRow struct {
+	Provider         string  `json:"provider"`
+	TotalGenerations int64   `json:"total_generations"`
+	TotalTokens      int64   `json:"total_tokens"`
+	AvgDurationMs    float64 `json:"avg_duration_ms"`
+	ErrorCount       int64   `json:"error_count"`
+}
+
+func (q *Queries) GetTopProviders(ctx context.Context, arg GetTopProvidersParams) ([]GetTopProvidersRow, error) {
+	rows, err := q.db.QueryContext(ctx, GetTopProviders, arg.Date, arg.Date_2, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTopProvidersRow{}
+	for rows.Next() {
+		var i GetTopProvidersRow
+		if err := rows.Scan(
+			&i.Provider,
+			&i.TotalGenerations,
+			&i.TotalTokens,
+			&i.AvgDurationMs,
+			&i.ErrorCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
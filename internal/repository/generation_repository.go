@@ -3,270 +3,193 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"ai-service/internal/model"
+	"ai-service/internal/repository/generated"
 	"ai-service/internal/util/exception"
+	"ai-service/internal/util/exceptioncode"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
 )
 
 // GenerationRepository defines the interface for generation data access
 type GenerationRepository interface {
 	Create(ctx context.Context, generation *model.GenerationHistory) error
 	GetByID(ctx context.Context, id string) (*model.GenerationHistory, error)
+	GetByComparisonID(ctx context.Context, comparisonID string) ([]*model.GenerationHistory, error)
 	GetByProvider(ctx context.Context, provider string, limit, offset int) ([]*model.GenerationHistory, error)
 	GetRecent(ctx context.Context, limit, offset int) ([]*model.GenerationHistory, error)
 	GetByStatus(ctx context.Context, status string, limit, offset int) ([]*model.GenerationHistory, error)
 	GetStats(ctx context.Context, startDate, endDate time.Time) ([]*model.ProviderStats, error)
 	GetProviderStats(ctx context.Context, provider string, startDate, endDate time.Time) (*model.ProviderStats, error)
+	GetSpend(ctx context.Context, startDate, endDate time.Time) (*model.SpendReport, error)
+	GetUserUsage(ctx context.Context, userID string, since time.Time) (*model.Usage, error)
+	GetProviderUsage(ctx context.Context, provider string, since time.Time) (*model.Usage, error)
 	UpdateStatus(ctx context.Context, id string, status string, errorMessage string) error
 	Delete(ctx context.Context, id string) error
 }
 
 // generationRepository implements GenerationRepository
 type generationRepository struct {
-	db *sql.DB
-	// TODO: Add SQLC generated querier when available
+	queries *generated.Queries
 }
 
 // NewGenerationRepository creates a new generation repository
 func NewGenerationRepository(db *sql.DB) GenerationRepository {
 	return &generationRepository{
-		db: db,
+		queries: generated.New(db),
 	}
 }
 
 // Create saves a new generation record
 func (r *generationRepository) Create(ctx context.Context, generation *model.GenerationHistory) error {
-	query := `
-		INSERT INTO generations (
-			provider, model, prompt, response, tokens_used, duration_ms, status, error_message
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
-		) RETURNING id, created_at, updated_at
-	`
-
-	var id string
-	var createdAt, updatedAt time.Time
-	err := r.db.QueryRowContext(ctx, query,
-		generation.Provider,
-		generation.Model,
-		generation.Prompt,
-		generation.Response,
-		generation.TokensUsed,
-		generation.Duration,
-		generation.Status,
-		generation.ErrorMessage,
-	).Scan(&id, &createdAt, &updatedAt)
+	comparisonID, err := nullUUID(generation.ComparisonID)
+	if err != nil {
+		return err
+	}
+
+	// Only generations that failed over carry attempts; others store NULL
+	var failedAttempts pqtype.NullRawMessage
+	if len(generation.FailedAttempts) > 0 {
+		failedAttempts.RawMessage, err = json.Marshal(generation.FailedAttempts)
+		if err != nil {
+			return err
+		}
+		failedAttempts.Valid = true
+	}
 
+	var costUSD sql.NullFloat64
+	if generation.CostUSD != nil {
+		costUSD = sql.NullFloat64{Float64: *generation.CostUSD, Valid: true}
+	}
+
+	row, err := r.queries.CreateGeneration(ctx, generated.CreateGenerationParams{
+		Provider:          generation.Provider,
+		Model:             generation.Model,
+		Prompt:            generation.Prompt,
+		Response:          generation.Response,
+		TokensUsed:        int32(generation.TokensUsed),
+		DurationMs:        int32(generation.Duration),
+		Status:            generation.Status,
+		ErrorMessage:      nullString(generation.ErrorMessage),
+		ComparisonID:      comparisonID,
+		RequestedProvider: nullString(generation.RequestedProvider),
+		FailedAttempts:    failedAttempts,
+		UserID:            nullString(generation.UserID),
+		CostUsd:           costUSD,
+		PromptTokens:      int32(generation.PromptTokens),
+		CompletionTokens:  int32(generation.CompletionTokens),
+		TotalTokens:       int32(generation.TotalTokens),
+		TokensEstimated:   generation.Estimated,
+	})
 	if err != nil {
 		return exception.TranslateDatabaseError(ctx, err)
 	}
 
 	// Set the generated values
-	generation.ID = id
-	generation.CreatedAt = createdAt
-	generation.UpdatedAt = updatedAt
+	generation.ID = row.ID.String()
+	generation.CreatedAt = row.CreatedAt.Time
+	generation.UpdatedAt = row.UpdatedAt.Time
 
 	return nil
 }
 
 // GetByID retrieves a generation by ID
 func (r *generationRepository) GetByID(ctx context.Context, id string) (*model.GenerationHistory, error) {
-	query := `SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at FROM generations WHERE id = $1`
-
-	var generation model.GenerationHistory
-	var errorMessage sql.NullString
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&generation.ID,
-		&generation.Provider,
-		&generation.Model,
-		&generation.Prompt,
-		&generation.Response,
-		&generation.TokensUsed,
-		&generation.Duration,
-		&generation.Status,
-		&errorMessage,
-		&generation.CreatedAt,
-		&generation.UpdatedAt,
-	)
+	generationID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
 
+	row, err := r.queries.GetGenerationByID(ctx, generationID)
 	if err != nil {
 		return nil, exception.TranslateDatabaseError(ctx, err)
 	}
 
-	if errorMessage.Valid {
-		generation.ErrorMessage = errorMessage.String
+	return toGeneration(row)
+}
+
+// GetByComparisonID retrieves every leg of a provider comparison
+func (r *generationRepository) GetByComparisonID(ctx context.Context, comparisonID string) ([]*model.GenerationHistory, error) {
+	id, err := uuid.Parse(comparisonID)
+	if err != nil {
+		// No comparison can have a malformed ID
+		return nil, nil
+	}
+
+	rows, err := r.queries.GetGenerationsByComparisonID(ctx, uuid.NullUUID{UUID: id, Valid: true})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
 	}
 
-	return &generation, nil
+	return toGenerations(rows)
 }
 
 // GetByProvider retrieves generations by provider with pagination
 func (r *generationRepository) GetByProvider(ctx context.Context, provider string, limit, offset int) ([]*model.GenerationHistory, error) {
-	query := `
-		SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at FROM generations 
-		WHERE provider = $1 
-		ORDER BY created_at DESC 
-		LIMIT $2 OFFSET $3
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, provider, limit, offset)
+	rows, err := r.queries.GetGenerationsByProvider(ctx, generated.GetGenerationsByProviderParams{
+		Provider: provider,
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	})
 	if err != nil {
 		return nil, exception.TranslateDatabaseError(ctx, err)
 	}
-	defer rows.Close()
-
-	var generations []*model.GenerationHistory
-	for rows.Next() {
-		var generation model.GenerationHistory
-		var errorMessage sql.NullString
-		err := rows.Scan(
-			&generation.ID,
-			&generation.Provider,
-			&generation.Model,
-			&generation.Prompt,
-			&generation.Response,
-			&generation.TokensUsed,
-			&generation.Duration,
-			&generation.Status,
-			&errorMessage,
-			&generation.CreatedAt,
-			&generation.UpdatedAt,
-		)
-		if err != nil {
-			return nil, exception.TranslateDatabaseError(ctx, err)
-		}
-		if errorMessage.Valid {
-			generation.ErrorMessage = errorMessage.String
-		}
-		generations = append(generations, &generation)
-	}
 
-	return generations, nil
+	return toGenerations(rows)
 }
 
 // GetRecent retrieves recent generations with pagination
 func (r *generationRepository) GetRecent(ctx context.Context, limit, offset int) ([]*model.GenerationHistory, error) {
-	query := `
-		SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at FROM generations 
-		ORDER BY created_at DESC 
-		LIMIT $1 OFFSET $2
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.queries.GetRecentGenerations(ctx, generated.GetRecentGenerationsParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
 	if err != nil {
 		return nil, exception.TranslateDatabaseError(ctx, err)
 	}
-	defer rows.Close()
-
-	var generations []*model.GenerationHistory
-	for rows.Next() {
-		var generation model.GenerationHistory
-		var errorMessage sql.NullString
-		err := rows.Scan(
-			&generation.ID,
-			&generation.Provider,
-			&generation.Model,
-			&generation.Prompt,
-			&generation.Response,
-			&generation.TokensUsed,
-			&generation.Duration,
-			&generation.Status,
-			&errorMessage,
-			&generation.CreatedAt,
-			&generation.UpdatedAt,
-		)
-		if err != nil {
-			return nil, exception.TranslateDatabaseError(ctx, err)
-		}
-		if errorMessage.Valid {
-			generation.ErrorMessage = errorMessage.String
-		}
-		generations = append(generations, &generation)
-	}
 
-	return generations, nil
+	return toGenerations(rows)
 }
 
 // GetByStatus retrieves generations by status with pagination
 func (r *generationRepository) GetByStatus(ctx context.Context, status string, limit, offset int) ([]*model.GenerationHistory, error) {
-	query := `
-		SELECT id, provider, model, prompt, response, tokens_used, duration_ms, status, error_message, created_at, updated_at FROM generations 
-		WHERE status = $1 
-		ORDER BY created_at DESC 
-		LIMIT $2 OFFSET $3
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	rows, err := r.queries.GetGenerationsByStatus(ctx, generated.GetGenerationsByStatusParams{
+		Status: status,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
 	if err != nil {
 		return nil, exception.TranslateDatabaseError(ctx, err)
 	}
-	defer rows.Close()
-
-	var generations []*model.GenerationHistory
-	for rows.Next() {
-		var generation model.GenerationHistory
-		var errorMessage sql.NullString
-		err := rows.Scan(
-			&generation.ID,
-			&generation.Provider,
-			&generation.Model,
-			&generation.Prompt,
-			&generation.Response,
-			&generation.TokensUsed,
-			&generation.Duration,
-			&generation.Status,
-			&errorMessage,
-			&generation.CreatedAt,
-			&generation.UpdatedAt,
-		)
-		if err != nil {
-			return nil, exception.TranslateDatabaseError(ctx, err)
-		}
-		if errorMessage.Valid {
-			generation.ErrorMessage = errorMessage.String
-		}
-		generations = append(generations, &generation)
-	}
 
-	return generations, nil
+	return toGenerations(rows)
 }
 
 // GetStats retrieves generation statistics for all providers
 func (r *generationRepository) GetStats(ctx context.Context, startDate, endDate time.Time) ([]*model.ProviderStats, error) {
-	query := `
-		SELECT 
-			provider,
-			COUNT(*) as total_generations,
-			SUM(tokens_used) as total_tokens,
-			AVG(duration_ms) as avg_duration_ms,
-			COUNT(CASE WHEN status = 'error' THEN 1 END) as error_count
-		FROM generations 
-		WHERE created_at >= $1 AND created_at <= $2
-		GROUP BY provider
-		ORDER BY total_generations DESC
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	rows, err := r.queries.GetGenerationStats(ctx, generated.GetGenerationStatsParams{
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
 	if err != nil {
 		return nil, exception.TranslateDatabaseError(ctx, err)
 	}
-	defer rows.Close()
 
 	var stats []*model.ProviderStats
-	for rows.Next() {
-		var stat model.ProviderStats
-		err := rows.Scan(
-			&stat.Provider,
-			&stat.TotalGenerations,
-			&stat.TotalTokens,
-			&stat.AvgDuration,
-			&stat.ErrorCount,
-		)
-		if err != nil {
-			return nil, exception.TranslateDatabaseError(ctx, err)
-		}
-		stats = append(stats, &stat)
+	for _, row := range rows {
+		stats = append(stats, &model.ProviderStats{
+			Provider:         row.Provider,
+			TotalGenerations: int(row.TotalGenerations),
+			TotalTokens:      int(row.TotalTokens),
+			AvgDuration:      row.AvgDurationMs,
+			ErrorCount:       int(row.ErrorCount),
+			FailoverCount:    int(row.FailoverCount),
+			TotalCostUSD:     row.TotalCostUsd,
+		})
 	}
 
 	return stats, nil
@@ -274,48 +197,198 @@ func (r *generationRepository) GetStats(ctx context.Context, startDate, endDate
 
 // GetProviderStats retrieves statistics for a specific provider
 func (r *generationRepository) GetProviderStats(ctx context.Context, provider string, startDate, endDate time.Time) (*model.ProviderStats, error) {
-	query := `
-		SELECT 
-			COUNT(*) as total_generations,
-			SUM(tokens_used) as total_tokens,
-			AVG(duration_ms) as avg_duration_ms,
-			COUNT(CASE WHEN status = 'error' THEN 1 END) as error_count
-		FROM generations 
-		WHERE provider = $1 AND created_at >= $2 AND created_at <= $3
-	`
-
-	var stat model.ProviderStats
-	stat.Provider = provider
-
-	err := r.db.QueryRowContext(ctx, query, provider, startDate, endDate).Scan(
-		&stat.TotalGenerations,
-		&stat.TotalTokens,
-		&stat.AvgDuration,
-		&stat.ErrorCount,
-	)
+	row, err := r.queries.GetProviderStats(ctx, generated.GetProviderStatsParams{
+		Provider:  provider,
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return &model.ProviderStats{
+		Provider:         provider,
+		TotalGenerations: int(row.TotalGenerations),
+		TotalTokens:      int(row.TotalTokens),
+		AvgDuration:      row.AvgDurationMs,
+		ErrorCount:       int(row.ErrorCount),
+		FailoverCount:    int(row.FailoverCount),
+		TotalCostUSD:     row.TotalCostUsd,
+	}, nil
+}
+
+// GetSpend retrieves generation cost between two dates by provider, model, user and UTC day
+func (r *generationRepository) GetSpend(ctx context.Context, startDate, endDate time.Time) (*model.SpendReport, error) {
+	report := &model.SpendReport{}
+
+	byProvider, err := r.queries.GetSpendByProvider(ctx, generated.GetSpendByProviderParams{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+	report.ByProvider = spendLines(byProvider)
+
+	byModel, err := r.queries.GetSpendByModel(ctx, generated.GetSpendByModelParams{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+	report.ByModel = spendLines(byModel)
 
+	byUser, err := r.queries.GetSpendByUser(ctx, generated.GetSpendByUserParams{StartDate: startDate, EndDate: endDate})
 	if err != nil {
 		return nil, exception.TranslateDatabaseError(ctx, err)
 	}
+	report.ByUser = spendLines(byUser)
 
-	return &stat, nil
+	byDay, err := r.queries.GetSpendByDay(ctx, generated.GetSpendByDayParams{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+	report.ByDay = spendLines(byDay)
+
+	for _, line := range report.ByProvider {
+		report.TotalCostUSD += line.CostUSD
+	}
+
+	return report, nil
+}
+
+// GetUserUsage sums the tokens and generations recorded for a user since the given time
+func (r *generationRepository) GetUserUsage(ctx context.Context, userID string, since time.Time) (*model.Usage, error) {
+	row, err := r.queries.GetUserUsage(ctx, generated.GetUserUsageParams{UserID: userID, Since: since})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return &model.Usage{Tokens: int(row.Tokens), Requests: int(row.Requests)}, nil
+}
+
+// GetProviderUsage sums the tokens and generations recorded for a provider since the given time
+func (r *generationRepository) GetProviderUsage(ctx context.Context, provider string, since time.Time) (*model.Usage, error) {
+	row, err := r.queries.GetProviderUsage(ctx, generated.GetProviderUsageParams{Provider: provider, Since: since})
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	return &model.Usage{Tokens: int(row.Tokens), Requests: int(row.Requests)}, nil
 }
 
 // UpdateStatus updates the status of a generation
 func (r *generationRepository) UpdateStatus(ctx context.Context, id string, status string, errorMessage string) error {
-	query := `
-		UPDATE generations 
-		SET status = $1, error_message = $2, updated_at = NOW()
-		WHERE id = $3
-	`
+	generationID, err := parseID(id)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, query, status, errorMessage, id)
+	err = r.queries.UpdateGenerationStatus(ctx, generated.UpdateGenerationStatusParams{
+		ID:           generationID,
+		Status:       status,
+		ErrorMessage: sql.NullString{String: errorMessage, Valid: true},
+	})
 	return exception.TranslateDatabaseError(ctx, err)
 }
 
 // Delete removes a generation record
 func (r *generationRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM generations WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return exception.TranslateDatabaseError(ctx, err)
+	generationID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	return exception.TranslateDatabaseError(ctx, r.queries.DeleteGeneration(ctx, generationID))
+}
+
+// toGeneration converts a generations row to its model
+func toGeneration(row generated.Generation) (*model.GenerationHistory, error) {
+	generation := &model.GenerationHistory{
+		ID:                row.ID.String(),
+		Provider:          row.Provider,
+		Model:             row.Model,
+		Prompt:            row.Prompt,
+		Response:          row.Response,
+		TokensUsed:        int(row.TokensUsed),
+		Duration:          int64(row.DurationMs),
+		Status:            row.Status,
+		ErrorMessage:      row.ErrorMessage.String,
+		RequestedProvider: row.RequestedProvider.String,
+		UserID:            row.UserID.String,
+		CreatedAt:         row.CreatedAt.Time,
+		UpdatedAt:         row.UpdatedAt.Time,
+		TokenUsage: model.TokenUsage{
+			PromptTokens:     int(row.PromptTokens),
+			CompletionTokens: int(row.CompletionTokens),
+			TotalTokens:      int(row.TotalTokens),
+			Estimated:        row.TokensEstimated,
+		},
+	}
+	if row.ComparisonID.Valid {
+		generation.ComparisonID = row.ComparisonID.UUID.String()
+	}
+	if row.CostUsd.Valid {
+		generation.CostUSD = &row.CostUsd.Float64
+	}
+
+	if row.FailedAttempts.Valid && len(row.FailedAttempts.RawMessage) > 0 {
+		if err := json.Unmarshal(row.FailedAttempts.RawMessage, &generation.FailedAttempts); err != nil {
+			return nil, err
+		}
+	}
+
+	return generation, nil
+}
+
+func toGenerations(rows []generated.Generation) ([]*model.GenerationHistory, error) {
+	var generations []*model.GenerationHistory
+	for _, row := range rows {
+		generation, err := toGeneration(row)
+		if err != nil {
+			return nil, err
+		}
+		generations = append(generations, generation)
+	}
+	return generations, nil
+}
+
+// spendRow is any of the per-grouping spend rows, which share one shape
+type spendRow interface {
+	generated.GetSpendByProviderRow | generated.GetSpendByModelRow | generated.GetSpendByUserRow | generated.GetSpendByDayRow
+}
+
+func spendLines[T spendRow](rows []T) []model.SpendLine {
+	lines := []model.SpendLine{}
+	for _, row := range rows {
+		line := generated.GetSpendByProviderRow(row)
+		lines = append(lines, model.SpendLine{
+			Key:         line.Key,
+			Generations: int(line.Generations),
+			Tokens:      int(line.Tokens),
+			CostUSD:     line.CostUsd,
+		})
+	}
+	return lines
+}
+
+// parseID parses a UUID primary key; no row can match a malformed one
+func parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, exceptioncode.ErrEmptyResult
+	}
+	return parsed, nil
+}
+
+// nullUUID maps an empty string to SQL NULL
+func nullUUID(value string) (uuid.NullUUID, error) {
+	if value == "" {
+		return uuid.NullUUID{}, nil
+	}
+	parsed, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: parsed, Valid: true}, nil
+}
+
+// nullString maps an empty string to SQL NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
 }
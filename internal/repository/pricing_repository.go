@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository/generated"
+	"ai-service/internal/util/exception"
+)
+
+// PricingRepository defines the interface for model price data access
+type PricingRepository interface {
+	Create(ctx context.Context, price *model.ModelPrice) error
+	List(ctx context.Context) ([]*model.ModelPrice, error)
+}
+
+// pricingRepository implements PricingRepository
+type pricingRepository struct {
+	queries *generated.Queries
+}
+
+// NewPricingRepository creates a new pricing repository
+func NewPricingRepository(db *sql.DB) PricingRepository {
+	return &pricingRepository{
+		queries: generated.New(db),
+	}
+}
+
+// Create saves a new price version. A zero EffectiveFrom means now.
+func (r *pricingRepository) Create(ctx context.Context, price *model.ModelPrice) error {
+	var effectiveFrom sql.NullTime
+	if !price.EffectiveFrom.IsZero() {
+		effectiveFrom = sql.NullTime{Time: price.EffectiveFrom, Valid: true}
+	}
+
+	row, err := r.queries.CreateModelPrice(ctx, generated.CreateModelPriceParams{
+		Provider:                price.Provider,
+		Model:                   price.Model,
+		PromptUsdPerMillion:     price.PromptPerMillion,
+		CompletionUsdPerMillion: price.CompletionPerMillion,
+		EffectiveFrom:           effectiveFrom,
+	})
+	if err != nil {
+		return exception.TranslateDatabaseError(ctx, err)
+	}
+
+	price.ID = row.ID.String()
+	price.EffectiveFrom = row.EffectiveFrom
+	price.CreatedAt = row.CreatedAt.Time
+	return nil
+}
+
+// List retrieves every price version, newest first within each model
+func (r *pricingRepository) List(ctx context.Context) ([]*model.ModelPrice, error) {
+	rows, err := r.queries.ListModelPrices(ctx)
+	if err != nil {
+		return nil, exception.TranslateDatabaseError(ctx, err)
+	}
+
+	prices := []*model.ModelPrice{}
+	for _, row := range rows {
+		prices = append(prices, &model.ModelPrice{
+			ID:                   row.ID.String(),
+			Provider:             row.Provider,
+			Model:                row.Model,
+			PromptPerMillion:     row.PromptUsdPerMillion,
+			CompletionPerMillion: row.CompletionUsdPerMillion,
+			EffectiveFrom:        row.EffectiveFrom,
+			CreatedAt:            row.CreatedAt.Time,
+		})
+	}
+
+	return prices, nil
+}
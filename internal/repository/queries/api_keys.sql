@@ -0,0 +1,38 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    name, key_prefix, key_hash, role, allowed_providers, allowed_models, quota
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetAPIKeyByID :one
+SELECT * FROM api_keys WHERE id = $1;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT * FROM api_keys WHERE key_hash = $1 AND is_active = true;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys ORDER BY created_at DESC;
+
+-- name: RotateAPIKey :one
+UPDATE api_keys
+SET key_hash = $2, key_prefix = $3, updated_at = NOW()
+WHERE id = $1 AND is_active = true
+RETURNING id;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET is_active = false, revoked_at = COALESCE(revoked_at, NOW()), updated_at = NOW()
+WHERE id = $1
+RETURNING id;
+
+-- name: UpdateAPIKeyQuota :one
+UPDATE api_keys
+SET quota = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id;
+
+-- name: RecordAPIKeyUsage :exec
+UPDATE api_keys
+SET usage_count = usage_count + 1, last_used_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,47 @@
+-- name: CreateConversation :one
+INSERT INTO conversations (
+    title, provider, model, system_message
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetConversationByID :one
+SELECT
+    c.*,
+    (SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id) as message_count
+FROM conversations c
+WHERE c.id = $1;
+
+-- name: ListConversations :many
+SELECT 
+    c.*,
+    (SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id) as message_count
+FROM conversations c
+ORDER BY c.updated_at DESC 
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateConversation :one
+UPDATE conversations 
+SET title = $2, model = $3, system_message = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING updated_at;
+
+-- name: TouchConversation :exec
+UPDATE conversations 
+SET updated_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteConversation :exec
+DELETE FROM conversations WHERE id = $1;
+
+-- name: CreateMessage :one
+INSERT INTO messages (
+    conversation_id, role, content, tokens_used
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetMessagesByConversation :many
+SELECT * FROM messages 
+WHERE conversation_id = $1 
+ORDER BY created_at ASC;
@@ -1,13 +1,20 @@
 -- name: CreateGeneration :one
 INSERT INTO generations (
-    provider, model, prompt, response, tokens_used, duration_ms, status, error_message
+    provider, model, prompt, response, tokens_used, duration_ms, status, error_message, comparison_id,
+    requested_provider, failed_attempts, user_id, cost_usd,
+    prompt_tokens, completion_tokens, total_tokens, tokens_estimated
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
 ) RETURNING *;
 
 -- name: GetGenerationByID :one
 SELECT * FROM generations WHERE id = $1;
 
+-- name: GetGenerationsByComparisonID :many
+SELECT * FROM generations 
+WHERE comparison_id = $1 
+ORDER BY created_at ASC;
+
 -- name: GetGenerationsByProvider :many
 SELECT * FROM generations 
 WHERE provider = $1 
@@ -29,22 +36,68 @@ LIMIT $2 OFFSET $3;
 SELECT 
     provider,
     COUNT(*) as total_generations,
-    SUM(tokens_used) as total_tokens,
-    AVG(duration_ms) as avg_duration_ms,
-    COUNT(CASE WHEN status = 'error' THEN 1 END) as error_count
+    COALESCE(SUM(tokens_used), 0)::bigint as total_tokens,
+    COALESCE(AVG(duration_ms), 0)::float8 as avg_duration_ms,
+    COUNT(CASE WHEN status = 'error' THEN 1 END) as error_count,
+    COUNT(CASE WHEN failed_attempts IS NOT NULL THEN 1 END) as failover_count,
+    COALESCE(SUM(cost_usd), 0)::float8 as total_cost_usd
 FROM generations 
-WHERE created_at >= $1 AND created_at <= $2
+WHERE created_at >= sqlc.arg(start_date)::timestamptz AND created_at <= sqlc.arg(end_date)::timestamptz
 GROUP BY provider
 ORDER BY total_generations DESC;
 
 -- name: GetProviderStats :one
 SELECT 
     COUNT(*) as total_generations,
-    SUM(tokens_used) as total_tokens,
-    AVG(duration_ms) as avg_duration_ms,
-    COUNT(CASE WHEN status = 'error' THEN 1 END) as error_count
+    COALESCE(SUM(tokens_used), 0)::bigint as total_tokens,
+    COALESCE(AVG(duration_ms), 0)::float8 as avg_duration_ms,
+    COUNT(CASE WHEN status = 'error' THEN 1 END) as error_count,
+    COUNT(CASE WHEN failed_attempts IS NOT NULL THEN 1 END) as failover_count,
+    COALESCE(SUM(cost_usd), 0)::float8 as total_cost_usd
 FROM generations 
-WHERE provider = $1 AND created_at >= $2 AND created_at <= $3;
+WHERE provider = sqlc.arg(provider) AND created_at >= sqlc.arg(start_date)::timestamptz AND created_at <= sqlc.arg(end_date)::timestamptz;
+
+-- name: GetSpendByProvider :many
+SELECT provider as key, COUNT(*) as generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as tokens, COALESCE(SUM(cost_usd), 0)::float8 as cost_usd
+FROM generations
+WHERE created_at >= sqlc.arg(start_date)::timestamptz AND created_at <= sqlc.arg(end_date)::timestamptz
+GROUP BY 1
+ORDER BY cost_usd DESC, key;
+
+-- name: GetSpendByModel :many
+SELECT model as key, COUNT(*) as generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as tokens, COALESCE(SUM(cost_usd), 0)::float8 as cost_usd
+FROM generations
+WHERE created_at >= sqlc.arg(start_date)::timestamptz AND created_at <= sqlc.arg(end_date)::timestamptz
+GROUP BY 1
+ORDER BY cost_usd DESC, key;
+
+-- name: GetSpendByUser :many
+SELECT COALESCE(user_id, '') as key, COUNT(*) as generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as tokens, COALESCE(SUM(cost_usd), 0)::float8 as cost_usd
+FROM generations
+WHERE created_at >= sqlc.arg(start_date)::timestamptz AND created_at <= sqlc.arg(end_date)::timestamptz
+GROUP BY 1
+ORDER BY cost_usd DESC, key;
+
+-- name: GetSpendByDay :many
+SELECT TO_CHAR(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') as key, COUNT(*) as generations,
+    COALESCE(SUM(tokens_used), 0)::bigint as tokens, COALESCE(SUM(cost_usd), 0)::float8 as cost_usd
+FROM generations
+WHERE created_at >= sqlc.arg(start_date)::timestamptz AND created_at <= sqlc.arg(end_date)::timestamptz
+GROUP BY 1
+ORDER BY key;
+
+-- name: GetUserUsage :one
+SELECT COALESCE(SUM(tokens_used), 0)::bigint as tokens, COUNT(*) as requests
+FROM generations
+WHERE user_id = sqlc.arg(user_id)::text AND created_at >= sqlc.arg(since)::timestamptz;
+
+-- name: GetProviderUsage :one
+SELECT COALESCE(SUM(tokens_used), 0)::bigint as tokens, COUNT(*) as requests
+FROM generations
+WHERE provider = sqlc.arg(provider) AND created_at >= sqlc.arg(since)::timestamptz;
 
 -- name: DeleteGeneration :exec
 DELETE FROM generations WHERE id = $1;
@@ -0,0 +1,10 @@
+-- name: CreateModelPrice :one
+INSERT INTO model_pricing (
+    provider, model, prompt_usd_per_million, completion_usd_per_million, effective_from
+) VALUES (
+    $1, $2, $3, $4, COALESCE(sqlc.narg(effective_from)::timestamptz, NOW())
+) RETURNING *;
+
+-- name: ListModelPrices :many
+SELECT * FROM model_pricing
+ORDER BY provider, model, effective_from DESC;
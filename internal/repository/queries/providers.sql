@@ -1,50 +1,55 @@
--- name: CreateProvider :one
-INSERT INTO providers (
-    name, api_key_hash, is_active, config
-) VALUES (
-    $1, $2, $3, $4
-) RETURNING *;
-
--- name: GetProviderByID :one
-SELECT * FROM providers WHERE id = $1;
+-- name: ListProviders :many
+SELECT * FROM providers
+ORDER BY name;
 
 -- name: GetProviderByName :one
 SELECT * FROM providers WHERE name = $1;
 
--- name: GetActiveProviders :many
-SELECT * FROM providers WHERE is_active = true ORDER BY name;
-
--- name: GetAllProviders :many
-SELECT * FROM providers ORDER BY name;
-
--- name: UpdateProvider :exec
-UPDATE providers 
-SET name = $2, api_key_hash = $3, is_active = $4, config = $5, updated_at = NOW()
-WHERE id = $1;
-
--- name: UpdateProviderConfig :exec
-UPDATE providers 
-SET config = $2, updated_at = NOW()
-WHERE id = $1;
-
--- name: UpdateProviderStatus :exec
-UPDATE providers 
-SET is_active = $2, updated_at = NOW()
-WHERE id = $1;
-
--- name: DeleteProvider :exec
-DELETE FROM providers WHERE id = $1;
-
--- name: GetProviderStats :many
-SELECT 
-    p.name,
-    p.is_active,
-    COUNT(g.id) as total_generations,
-    COALESCE(SUM(g.tokens_used), 0) as total_tokens,
-    COALESCE(AVG(g.duration_ms), 0) as avg_duration_ms,
-    COUNT(CASE WHEN g.status = 'error' THEN 1 END) as error_count
-FROM providers p
-LEFT JOIN generations g ON p.name = g.provider
-WHERE g.created_at >= $1 AND g.created_at <= $2 OR g.created_at IS NULL
-GROUP BY p.id, p.name, p.is_active
-ORDER BY total_generations DESC; 
\ No newline at end of file
+-- name: SetProviderActive :one
+INSERT INTO providers (name, is_active, config)
+VALUES ($1, $2, '{}')
+ON CONFLICT (name) DO UPDATE
+SET is_active = EXCLUDED.is_active, updated_at = NOW()
+RETURNING *;
+
+-- name: UpdateProviderConfig :one
+INSERT INTO providers (name, config)
+VALUES (sqlc.arg(name), jsonb_strip_nulls(sqlc.arg(patch)::jsonb))
+ON CONFLICT (name) DO UPDATE
+SET config = jsonb_strip_nulls(COALESCE(providers.config, '{}') || sqlc.arg(patch)::jsonb), updated_at = NOW()
+RETURNING *;
+
+-- name: ListProviderKeys :many
+SELECT name, encrypted_api_key, encrypted_data_key, api_key_updated_at FROM providers
+WHERE encrypted_api_key IS NOT NULL
+ORDER BY name;
+
+-- name: GetProviderKey :one
+SELECT name, encrypted_api_key, encrypted_data_key, api_key_updated_at FROM providers
+WHERE name = $1 AND encrypted_api_key IS NOT NULL;
+
+-- name: SaveProviderKey :one
+INSERT INTO providers (name, config, encrypted_api_key, encrypted_data_key, api_key_updated_at)
+VALUES ($1, '{}', $2, $3, NOW())
+ON CONFLICT (name) DO UPDATE
+SET encrypted_api_key = EXCLUDED.encrypted_api_key,
+    encrypted_data_key = EXCLUDED.encrypted_data_key,
+    api_key_updated_at = EXCLUDED.api_key_updated_at,
+    updated_at = NOW()
+RETURNING api_key_updated_at;
+
+-- name: DeleteProviderKey :execrows
+UPDATE providers
+SET encrypted_api_key = NULL, encrypted_data_key = NULL, api_key_updated_at = NOW(), updated_at = NOW()
+WHERE name = $1 AND encrypted_api_key IS NOT NULL;
+
+-- name: CreateProviderKeyAudit :one
+INSERT INTO provider_key_audit (provider, action, actor)
+VALUES ($1, $2, $3)
+RETURNING id, created_at;
+
+-- name: ListProviderKeyAudit :many
+SELECT id, provider, action, actor, created_at FROM provider_key_audit
+WHERE provider = $1
+ORDER BY created_at DESC
+LIMIT $2;
@@ -1,33 +1,91 @@
 package routes
 
 import (
+	"log"
+
+	"ai-service/cmd/config"
 	"ai-service/internal/app/middleware"
 	"ai-service/internal/controller"
 	"ai-service/internal/outbound"
 	"ai-service/internal/repository"
+	"ai-service/internal/service"
+	"ai-service/internal/util/authentication"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouters(aiManager *outbound.Manager, generationRepo repository.GenerationRepository) *gin.Engine {
+func NewRouters(cfg *config.Config, aiManager *outbound.Manager, generationRepo repository.GenerationRepository, conversationRepo repository.ConversationRepository, apiKeyRepo repository.APIKeyRepository, pricingRepo repository.PricingRepository, providerRepo repository.AIProviderRepository, providerKeyService service.ProviderKeyService) *gin.Engine {
 	// Initialize controllers with AI manager and repository
-	aiController := controller.NewAIController(aiManager, generationRepo)
-	webController := controller.NewWebController(generationRepo)
-	healthController := controller.NewHealthController()
+	quotaService := service.NewQuotaService(cfg.Quota, generationRepo)
+	pricingService := service.NewPricingService(pricingRepo)
+
+	aiController := controller.NewAIController(aiManager, generationRepo, quotaService, pricingService)
+	conversationController := controller.NewConversationController(aiManager, conversationRepo, generationRepo, quotaService, pricingService)
+	webController := controller.NewWebController(aiManager, generationRepo)
+	healthController := controller.NewHealthController(aiManager)
+	authController := controller.NewAuthController(cfg.Security.AuthClients)
+	apiKeyController := controller.NewAPIKeyController(aiManager, apiKeyRepo)
+	quotaController := controller.NewQuotaController(quotaService)
+	pricingController := controller.NewPricingController(aiManager, pricingService)
+	tokenController := controller.NewTokenController(aiManager)
+	openAIController := controller.NewOpenAIController(aiManager, generationRepo, quotaService, pricingService)
+	providerController := controller.NewProviderController(aiManager, providerRepo, providerKeyService)
+
+	// Rate limit buckets are per instance until a shared store is plugged in
+	rateLimitStore := middleware.NewMemoryRateLimitStore()
+	rateLimiter := middleware.RateLimiter(cfg.RateLimit, rateLimitStore)
+	failedAuthLimiter := middleware.FailedAuthLimiter(cfg.RateLimit, rateLimitStore)
+
+	// API keys are accepted alongside JWTs on every protected route
+	authenticate := middleware.Authenticate(cfg.Security.AuthEnabled, apiKeyRepo)
+
+	// Admin routes manage provider keys and client keys, so they always
+	// require an authenticated admin even when AUTH_ENABLED is false. Tokens
+	// are rejected while JWT_SECRET is unset or a placeholder, leaving admin
+	// API keys as the only way in until a private secret is configured.
+	authenticateAdmin := middleware.Authenticate(true, apiKeyRepo)
 
 	router := router(
+		cfg.Security.AuthEnabled,
+		cfg.Server.TrustedProxies,
+		rateLimiter,
+		failedAuthLimiter,
+		authenticate,
+		authenticateAdmin,
 		aiController,
+		conversationController,
 		webController,
 		healthController,
+		authController,
+		apiKeyController,
+		quotaController,
+		pricingController,
+		tokenController,
+		openAIController,
+		providerController,
 	)
 
 	return router
 }
 
 func router(
+	authEnabled bool,
+	trustedProxies []string,
+	rateLimiter gin.HandlerFunc,
+	failedAuthLimiter gin.HandlerFunc,
+	authenticate gin.HandlerFunc,
+	authenticateAdmin gin.HandlerFunc,
 	aiController controller.AIController,
+	conversationController controller.ConversationController,
 	webController controller.WebController,
 	healthController controller.HealthController,
+	authController controller.AuthController,
+	apiKeyController controller.APIKeyController,
+	quotaController controller.QuotaController,
+	pricingController controller.PricingController,
+	tokenController controller.TokenController,
+	openAIController controller.OpenAIController,
+	providerController controller.ProviderController,
 ) *gin.Engine {
 	// set gin mode
 	gin.SetMode(gin.ReleaseMode)
@@ -35,25 +93,99 @@ func router(
 
 	router := gin.New()
 
+	// Only believe X-Forwarded-For from configured proxies, so clients cannot
+	// pick the IP they are rate limited by
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Printf("Failed to set trusted proxies: %v", err)
+	}
+
 	// global middleware
 	router.Use(gin.Recovery())
 	router.Use(middleware.Logger())
 	router.Use(middleware.CORSMiddleware())
 
 	router.HandleMethodNotAllowed = true
+	// Let handlers pass *gin.Context where a context.Context is expected
+	// without losing values stored on the request context
+	router.ContextWithFallback = true
+
+	// Health check is registered outside the rate-limited group so probes
+	// are never throttled
+	router.GET("/api/health", healthController.GetHealthCheck)
 
-	// API routes
+	// API routes. The rate limiter runs after authenticate so callers only
+	// get their own bucket once their credentials are validated; anonymous
+	// requests are limited by client IP, and rejected credentials by the
+	// failed auth limiter in front of authenticate.
 	api := router.Group("/api")
+
+	// Authentication endpoints, limited by client IP against brute force
+	anonymous := api.Group("", failedAuthLimiter, rateLimiter)
+	{
+		anonymous.POST("/auth/token", authController.IssueToken)
+		anonymous.POST("/auth/refresh", authController.RefreshToken)
+	}
+
+	// Endpoints available to any authenticated caller
+	user := api.Group("", failedAuthLimiter, authenticate, rateLimiter, middleware.RequireRole(authEnabled, authentication.RoleUser, authentication.RoleAdmin))
 	{
 		// AI Generation endpoints
-		api.POST("/generate", aiController.GenerateContent)
-		api.POST("/compare", aiController.CompareProviders)
-		api.GET("/providers", aiController.GetProviders)
-		api.GET("/history", aiController.GetHistory)
-		api.GET("/stats", aiController.GetStats)
-
-		// Health check
-		api.GET("/health", healthController.GetHealthCheck)
+		user.POST("/generate", aiController.GenerateContent)
+		user.POST("/generate/stream", aiController.GenerateContentStream)
+		user.POST("/compare", aiController.CompareProviders)
+		user.GET("/compare/:id", aiController.GetComparison)
+		user.GET("/providers", aiController.GetProviders)
+		user.GET("/quota", quotaController.GetQuota)
+		user.GET("/pricing", pricingController.ListPrices)
+		user.POST("/tokens/count", tokenController.CountTokens)
+
+		// Conversation endpoints
+		user.POST("/conversations", conversationController.CreateConversation)
+		user.GET("/conversations", conversationController.ListConversations)
+		user.GET("/conversations/:id", conversationController.GetConversation)
+		user.PUT("/conversations/:id", conversationController.UpdateConversation)
+		user.DELETE("/conversations/:id", conversationController.DeleteConversation)
+		user.POST("/conversations/:id/messages", conversationController.SendMessage)
+	}
+
+	// Endpoints exposing every caller's data, never open to anonymous callers
+	admin := api.Group("", failedAuthLimiter, authenticateAdmin, rateLimiter, middleware.RequireRole(true, authentication.RoleAdmin))
+	{
+		admin.GET("/history", aiController.GetHistory)
+		admin.GET("/stats", aiController.GetStats)
+
+		// API key management endpoints
+		admin.POST("/keys", apiKeyController.CreateAPIKey)
+		admin.GET("/keys", apiKeyController.ListAPIKeys)
+		admin.POST("/keys/:id/rotate", apiKeyController.RotateAPIKey)
+		admin.DELETE("/keys/:id", apiKeyController.RevokeAPIKey)
+		admin.PUT("/keys/:id/quota", apiKeyController.UpdateAPIKeyQuota)
+
+		// Pricing catalogue endpoints
+		admin.POST("/pricing", pricingController.CreatePrice)
+
+		// Model discovery endpoints
+		admin.POST("/models/refresh", aiController.RefreshModels)
+
+		// Provider configuration endpoints
+		admin.POST("/providers/reload", aiController.ReloadProviders)
+
+		// Provider management endpoints
+		admin.GET("/admin/providers", providerController.ListProviders)
+		admin.POST("/admin/providers/:name/enable", providerController.EnableProvider)
+		admin.POST("/admin/providers/:name/disable", providerController.DisableProvider)
+		admin.PATCH("/admin/providers/:name/config", providerController.UpdateProviderConfig)
+		admin.PUT("/admin/providers/:name/key", providerController.SetProviderKey)
+		admin.POST("/admin/providers/:name/key/rotate", providerController.RotateProviderKey)
+		admin.DELETE("/admin/providers/:name/key", providerController.DeleteProviderKey)
+		admin.GET("/admin/providers/:name/key/audit", providerController.GetProviderKeyAudit)
+	}
+
+	// OpenAI-compatible gateway, routed to providers by model name
+	v1 := router.Group("/v1", failedAuthLimiter, authenticate, rateLimiter, middleware.RequireRole(authEnabled, authentication.RoleUser, authentication.RoleAdmin))
+	{
+		v1.POST("/chat/completions", openAIController.ChatCompletions)
+		v1.GET("/models", openAIController.ListModels)
 	}
 
 	// Web UI routes
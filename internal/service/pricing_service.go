@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository"
+)
+
+// pricingCacheTTL is how long the catalogue is kept before it is reloaded
+const pricingCacheTTL = 5 * time.Minute
+
+type PricingService interface {
+	// Price returns the price of a model in effect at the given time, or nil
+	// when the catalogue has none
+	Price(ctx context.Context, provider, modelName string, at time.Time) (*model.ModelPrice, error)
+	// List returns every price version
+	List(ctx context.Context) ([]*model.ModelPrice, error)
+	// Create adds a new price version
+	Create(ctx context.Context, price *model.ModelPrice) error
+}
+
+type pricingService struct {
+	pricingRepo repository.PricingRepository
+
+	mu       sync.Mutex
+	prices   []*model.ModelPrice
+	loadedAt time.Time
+}
+
+// NewPricingService creates a pricing service backed by the model_pricing table
+func NewPricingService(pricingRepo repository.PricingRepository) PricingService {
+	return &pricingService{
+		pricingRepo: pricingRepo,
+	}
+}
+
+func (s *pricingService) Price(ctx context.Context, provider, modelName string, at time.Time) (*model.ModelPrice, error) {
+	prices, err := s.catalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return FindPrice(prices, provider, modelName, at), nil
+}
+
+func (s *pricingService) List(ctx context.Context) ([]*model.ModelPrice, error) {
+	return s.pricingRepo.List(ctx)
+}
+
+func (s *pricingService) Create(ctx context.Context, price *model.ModelPrice) error {
+	if err := s.pricingRepo.Create(ctx, price); err != nil {
+		return err
+	}
+
+	// Reload on next lookup so the new version is used straight away
+	s.mu.Lock()
+	s.prices = nil
+	s.mu.Unlock()
+
+	return nil
+}
+
+// catalogue returns the cached price list, reloading it once it is stale
+func (s *pricingService) catalogue(ctx context.Context) ([]*model.ModelPrice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prices != nil && time.Since(s.loadedAt) < pricingCacheTTL {
+		return s.prices, nil
+	}
+
+	prices, err := s.pricingRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.prices = prices
+	s.loadedAt = time.Now()
+
+	return prices, nil
+}
+
+// FindPrice picks the price for a model at the given time. A catalogue entry
+// matches the model exactly or as a prefix of a versioned name, so
+// "claude-3-sonnet" prices "claude-3-sonnet-20240229"; the longest matching
+// entry wins, then the newest version that is already in effect.
+func FindPrice(prices []*model.ModelPrice, provider, modelName string, at time.Time) *model.ModelPrice {
+	var best *model.ModelPrice
+	for _, price := range prices {
+		if price.Provider != provider || price.EffectiveFrom.After(at) {
+			continue
+		}
+		if price.Model != modelName && !strings.HasPrefix(modelName, price.Model+"-") {
+			continue
+		}
+
+		if best == nil ||
+			len(price.Model) > len(best.Model) ||
+			(len(price.Model) == len(best.Model) && price.EffectiveFrom.After(best.EffectiveFrom)) {
+			best = price
+		}
+	}
+	return best
+}
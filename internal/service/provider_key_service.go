@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository"
+	"ai-service/internal/util/encryption"
+)
+
+// providerKeyAuditLimit is how many audit entries are returned per provider
+const providerKeyAuditLimit = 100
+
+type ProviderKeyService interface {
+	// SetKey encrypts and stores a provider's API key, replacing any stored one
+	SetKey(ctx context.Context, provider, apiKey, actor string) (*model.ProviderKey, error)
+	// RotateKey encrypts a stored key again under a new data key and the
+	// current master key
+	RotateKey(ctx context.Context, provider, actor string) (*model.ProviderKey, error)
+	// DeleteKey removes a stored key so the provider falls back to its
+	// environment
+	DeleteKey(ctx context.Context, provider, actor string) error
+	// Audit returns the recent key changes of a provider, newest first
+	Audit(ctx context.Context, provider string) ([]*model.ProviderKeyAudit, error)
+	// ProviderKeys decrypts every stored key
+	ProviderKeys(ctx context.Context) (map[model.AIProvider]string, error)
+}
+
+type providerKeyService struct {
+	providerRepo repository.AIProviderRepository
+	cipher       *encryption.Cipher
+}
+
+// NewProviderKeyService creates a service storing provider API keys in the
+// providers table, encrypted with cipher
+func NewProviderKeyService(providerRepo repository.AIProviderRepository, cipher *encryption.Cipher) ProviderKeyService {
+	return &providerKeyService{
+		providerRepo: providerRepo,
+		cipher:       cipher,
+	}
+}
+
+func (s *providerKeyService) SetKey(ctx context.Context, provider, apiKey, actor string) (*model.ProviderKey, error) {
+	envelope, err := s.cipher.Seal([]byte(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt API key: %w", err)
+	}
+	return s.save(ctx, provider, envelope, model.ProviderKeySet, actor)
+}
+
+func (s *providerKeyService) RotateKey(ctx context.Context, provider, actor string) (*model.ProviderKey, error) {
+	stored, err := s.providerRepo.GetKey(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := s.cipher.Reseal(envelopeOf(stored))
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate API key for %s: %w", provider, err)
+	}
+	return s.save(ctx, provider, envelope, model.ProviderKeyRotated, actor)
+}
+
+func (s *providerKeyService) DeleteKey(ctx context.Context, provider, actor string) error {
+	audit := &model.ProviderKeyAudit{Provider: provider, Action: model.ProviderKeyDeleted, Actor: actor}
+	if err := s.providerRepo.DeleteKey(ctx, provider, audit); err != nil {
+		return err
+	}
+	logKeyChange(audit)
+	return nil
+}
+
+func (s *providerKeyService) Audit(ctx context.Context, provider string) ([]*model.ProviderKeyAudit, error) {
+	return s.providerRepo.ListKeyAudit(ctx, provider, providerKeyAuditLimit)
+}
+
+func (s *providerKeyService) ProviderKeys(ctx context.Context) (map[model.AIProvider]string, error) {
+	stored, err := s.providerRepo.ListKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load provider keys: %w", err)
+	}
+
+	keys := make(map[model.AIProvider]string, len(stored))
+	for _, key := range stored {
+		apiKey, err := s.cipher.Open(envelopeOf(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt API key for %s: %w", key.Provider, err)
+		}
+		keys[model.AIProvider(key.Provider)] = string(apiKey)
+	}
+	return keys, nil
+}
+
+// save stores an encrypted key and its audit entry
+func (s *providerKeyService) save(ctx context.Context, provider string, envelope *encryption.Envelope, action, actor string) (*model.ProviderKey, error) {
+	key := &model.ProviderKey{
+		Provider:         provider,
+		EncryptedAPIKey:  envelope.Ciphertext,
+		EncryptedDataKey: envelope.DataKey,
+	}
+	audit := &model.ProviderKeyAudit{Provider: provider, Action: action, Actor: actor}
+	if err := s.providerRepo.SaveKey(ctx, key, audit); err != nil {
+		return nil, err
+	}
+	logKeyChange(audit)
+	return key, nil
+}
+
+func envelopeOf(key *model.ProviderKey) *encryption.Envelope {
+	return &encryption.Envelope{Ciphertext: key.EncryptedAPIKey, DataKey: key.EncryptedDataKey}
+}
+
+// logKeyChange logs who changed which key, never the key itself
+func logKeyChange(audit *model.ProviderKeyAudit) {
+	log.Printf("Provider %s API key %s by %s", audit.Provider, audit.Action, audit.Actor)
+}
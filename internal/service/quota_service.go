@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+)
+
+// Quota scopes, windows and metrics reported in model.QuotaStatus
+const (
+	QuotaScopeUser     = "user"
+	QuotaScopeAPIKey   = "api_key"
+	QuotaScopeProvider = "provider"
+
+	QuotaWindowDaily   = "daily"
+	QuotaWindowMonthly = "monthly"
+
+	QuotaMetricTokens   = "tokens"
+	QuotaMetricRequests = "requests"
+)
+
+// UsageReader reads the token usage recorded in the generations table
+type UsageReader interface {
+	GetUserUsage(ctx context.Context, userID string, since time.Time) (*model.Usage, error)
+	GetProviderUsage(ctx context.Context, provider string, since time.Time) (*model.Usage, error)
+}
+
+type QuotaService interface {
+	// Enabled reports whether quotas are enforced
+	Enabled() bool
+	// Check evaluates the caller's and the providers' quotas for a request
+	// making one generation per provider of up to tokens each. Any status
+	// with Exceeded set means the request must be rejected.
+	Check(ctx context.Context, userID string, apiKey *model.APIKey, providers []model.AIProvider, tokens int) ([]model.QuotaStatus, error)
+	// Status reports current usage against the caller's quotas and every
+	// provider quota
+	Status(ctx context.Context, userID string, apiKey *model.APIKey) ([]model.QuotaStatus, error)
+}
+
+type quotaService struct {
+	config config.QuotaConfig
+	usage  UsageReader
+	now    func() time.Time
+}
+
+// NewQuotaService creates a quota service that reads usage from usage
+func NewQuotaService(cfg config.QuotaConfig, usage UsageReader) QuotaService {
+	return &quotaService{
+		config: cfg,
+		usage:  usage,
+		now:    time.Now,
+	}
+}
+
+func (s *quotaService) Enabled() bool {
+	return s.config.Enabled
+}
+
+func (s *quotaService) Check(ctx context.Context, userID string, apiKey *model.APIKey, providers []model.AIProvider, tokens int) ([]model.QuotaStatus, error) {
+	if !s.config.Enabled {
+		return nil, nil
+	}
+	return s.evaluate(ctx, userID, apiKey, providers, tokens, 1)
+}
+
+func (s *quotaService) Status(ctx context.Context, userID string, apiKey *model.APIKey) ([]model.QuotaStatus, error) {
+	if !s.config.Enabled {
+		return nil, nil
+	}
+
+	providers := make([]model.AIProvider, 0, len(s.config.Providers))
+	for provider := range s.config.Providers {
+		providers = append(providers, model.AIProvider(provider))
+	}
+	return s.evaluate(ctx, userID, apiKey, providers, 0, 0)
+}
+
+// evaluate builds the statuses for the caller and each provider, counting
+// requests and tokens as already spent by the incoming request
+func (s *quotaService) evaluate(ctx context.Context, userID string, apiKey *model.APIKey, providers []model.AIProvider, tokens, requests int) ([]model.QuotaStatus, error) {
+	now := s.now().UTC()
+	var statuses []model.QuotaStatus
+
+	// Callers are only metered when they are identified
+	if userID != "" {
+		scope, limits := QuotaScopeUser, s.config.Caller
+		if apiKey != nil {
+			scope = QuotaScopeAPIKey
+			if apiKey.Quota != nil {
+				limits = *apiKey.Quota
+			}
+		}
+
+		callerStatuses, err := s.windowStatuses(now, scope, userID, limits, tokens*len(providers), requests*len(providers), func(since time.Time) (*model.Usage, error) {
+			return s.usage.GetUserUsage(ctx, userID, since)
+		})
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, callerStatuses...)
+	}
+
+	for _, provider := range providers {
+		limits, ok := s.config.Providers[string(provider)]
+		if !ok {
+			continue
+		}
+
+		providerStatuses, err := s.windowStatuses(now, QuotaScopeProvider, string(provider), limits, tokens, requests, func(since time.Time) (*model.Usage, error) {
+			return s.usage.GetProviderUsage(ctx, string(provider), since)
+		})
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, providerStatuses...)
+	}
+
+	return statuses, nil
+}
+
+// windowStatuses loads usage for the daily and monthly windows that have a
+// limit and compares it with the limits
+func (s *quotaService) windowStatuses(now time.Time, scope, subject string, limits model.QuotaLimits, tokens, requests int, load func(since time.Time) (*model.Usage, error)) ([]model.QuotaStatus, error) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	windows := []struct {
+		name          string
+		start, reset  time.Time
+		tokenLimit    int
+		requestsLimit int
+	}{
+		{QuotaWindowDaily, dayStart, dayStart.AddDate(0, 0, 1), limits.DailyTokens, limits.DailyRequests},
+		{QuotaWindowMonthly, monthStart, monthStart.AddDate(0, 1, 0), limits.MonthlyTokens, limits.MonthlyRequests},
+	}
+
+	var statuses []model.QuotaStatus
+	for _, window := range windows {
+		if window.tokenLimit <= 0 && window.requestsLimit <= 0 {
+			continue
+		}
+
+		usage, err := load(window.start)
+		if err != nil {
+			return nil, err
+		}
+
+		base := model.QuotaStatus{
+			Scope:   scope,
+			Subject: subject,
+			Window:  window.name,
+			ResetAt: window.reset,
+		}
+		if window.tokenLimit > 0 {
+			statuses = append(statuses, s.status(base, QuotaMetricTokens, usage.Tokens, window.tokenLimit, tokens))
+		}
+		if window.requestsLimit > 0 {
+			statuses = append(statuses, s.status(base, QuotaMetricRequests, usage.Requests, window.requestsLimit, requests))
+		}
+	}
+
+	return statuses, nil
+}
+
+// status compares usage with a limit. A limit is exceeded once it is used up
+// or when the incoming amount would take usage past it.
+func (s *quotaService) status(status model.QuotaStatus, metric string, used, limit, incoming int) model.QuotaStatus {
+	status.Metric = metric
+	status.Used = used
+	status.Limit = limit
+	status.Remaining = limit - used
+	if status.Remaining < 0 {
+		status.Remaining = 0
+	}
+	status.Exceeded = used >= limit || used+incoming > limit
+	status.Warning = s.config.SoftLimitPercent > 0 && used*100 >= limit*s.config.SoftLimitPercent
+	return status
+}
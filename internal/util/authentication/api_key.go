@@ -0,0 +1,52 @@
+package authentication
+
+import (
+	"ai-service/internal/model"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// apiKeyPrefix marks secrets issued by this service so they are easy to spot
+const apiKeyPrefix = "ais_"
+
+// GenerateAPIKey creates a new random API key and returns it together with
+// the short prefix shown in listings and the hash to store
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	key = apiKeyPrefix + hex.EncodeToString(secret)
+	return key, key[:len(apiKeyPrefix)+8], HashAPIKey(key), nil
+}
+
+// IsAPIKey reports whether a credential looks like an API key issued by this
+// service rather than a JWT
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyPrefix)
+}
+
+// HashAPIKey returns the stored form of an API key. Keys are long random
+// strings, so a fast unsalted hash is enough to make lookups possible without
+// keeping the secret.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a copy of ctx carrying the API key used to authenticate
+func WithAPIKey(ctx context.Context, key *model.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the API key stored by WithAPIKey
+func APIKeyFromContext(ctx context.Context) (*model.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(*model.APIKey)
+	return key, ok && key != nil
+}
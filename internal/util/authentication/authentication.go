@@ -4,6 +4,7 @@ import (
 	"ai-service/internal/util/exceptioncode"
 	"ai-service/internal/util/logger"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -19,22 +20,78 @@ type JWTClaim struct {
 	jwt.RegisteredClaims
 }
 
+// Roles carried in JWTClaim.Role
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
 var jwtSecret string
 
+// ErrSecretNotSet is returned when tokens are generated without a usable
+// JWT secret
+var ErrSecretNotSet = errors.New("JWT_SECRET is not set")
+
+// placeholderSecrets are the example secrets shipped in the docs and
+// .env.example, which anyone can sign tokens with
+var placeholderSecrets = map[string]bool{
+	"your-secret-key": true,
+	"your-secret-key-change-this-in-production": true,
+	"your_jwt_secret_key":                       true,
+}
+
+// SecretUsable reports whether secret may sign tokens: it must be set and
+// not one of the published placeholders
+func SecretUsable(secret string) bool {
+	return strings.TrimSpace(secret) != "" && !placeholderSecrets[secret]
+}
+
+var jwtExpiration = 24 * time.Hour
+
 // Init initializes the JWT secret
 func Init(secret string) {
 	jwtSecret = secret
 }
 
+// SetExpiration sets how long newly generated tokens stay valid
+func SetExpiration(expiration time.Duration) {
+	if expiration > 0 {
+		jwtExpiration = expiration
+	}
+}
+
+// Expiration returns how long newly generated tokens stay valid
+func Expiration() time.Duration {
+	return jwtExpiration
+}
+
+type claimsContextKey struct{}
+
+// WithClaims returns a copy of ctx carrying the authenticated claims
+func WithClaims(ctx context.Context, claims *JWTClaim) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the authenticated claims stored by WithClaims
+func ClaimsFromContext(ctx context.Context) (*JWTClaim, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*JWTClaim)
+	return claims, ok && claims != nil
+}
+
 // GenerateToken generates a JWT token
 func GenerateToken(userID, username, email, role string) (string, error) {
+	if !SecretUsable(jwtSecret) {
+		return "", ErrSecretNotSet
+	}
+
 	claims := JWTClaim{
 		UserID:   userID,
 		Username: username,
 		Email:    email,
 		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(jwtExpiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
@@ -44,8 +101,13 @@ func GenerateToken(userID, username, email, role string) (string, error) {
 	return token.SignedString([]byte(jwtSecret))
 }
 
-// ValidateToken validates a JWT token
+// ValidateToken validates a JWT token. Every token is rejected while the
+// secret is unset or a placeholder, since anyone could have signed it.
 func ValidateToken(tokenString string) (*JWTClaim, error) {
+	if !SecretUsable(jwtSecret) {
+		return nil, exceptioncode.ErrTokenInvalid
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaim{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -54,6 +116,9 @@ func ValidateToken(tokenString string) (*JWTClaim, error) {
 	})
 
 	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, exceptioncode.ErrTokenExpired
+		}
 		logger.Error(context.Background(), "token validation failed", err)
 		return nil, exceptioncode.ErrTokenInvalid
 	}
@@ -0,0 +1,161 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeySize is the length in bytes of master and data keys (AES-256)
+const KeySize = 32
+
+// ErrDecrypt is returned when an envelope cannot be opened with any master key
+var ErrDecrypt = errors.New("encryption: envelope cannot be decrypted with the configured master keys")
+
+// Envelope is a secret encrypted under its own data key, with the data key
+// encrypted under a master key. Both are stored; neither is usable without
+// the master key.
+type Envelope struct {
+	Ciphertext []byte
+	DataKey    []byte
+}
+
+// Cipher seals secrets under the current master key and opens envelopes
+// sealed under it or any previous master key
+type Cipher struct {
+	current  cipher.AEAD
+	previous []cipher.AEAD
+}
+
+// NewCipher creates a Cipher from a current master key and the previous ones
+// still accepted for decryption, each KeySize bytes
+func NewCipher(masterKey []byte, previousKeys ...[]byte) (*Cipher, error) {
+	current, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cipher{current: current}
+	for _, key := range previousKeys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		c.previous = append(c.previous, aead)
+	}
+	return c, nil
+}
+
+// Seal encrypts plaintext under a new random data key
+func (c *Cipher) Seal(plaintext []byte) (*Envelope, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := seal(aead, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(c.current, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{Ciphertext: ciphertext, DataKey: wrapped}, nil
+}
+
+// Open decrypts an envelope, trying the current master key first
+func (c *Cipher) Open(envelope *Envelope) ([]byte, error) {
+	for _, master := range append([]cipher.AEAD{c.current}, c.previous...) {
+		dataKey, err := open(master, envelope.DataKey)
+		if err != nil {
+			continue
+		}
+		aead, err := newAEAD(dataKey)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := open(aead, envelope.Ciphertext)
+		if err != nil {
+			return nil, ErrDecrypt
+		}
+		return plaintext, nil
+	}
+	return nil, ErrDecrypt
+}
+
+// Reseal opens an envelope and seals its secret again under a new data key
+// and the current master key
+func (c *Cipher) Reseal(envelope *Envelope) (*Envelope, error) {
+	plaintext, err := c.Open(envelope)
+	if err != nil {
+		return nil, err
+	}
+	return c.Seal(plaintext)
+}
+
+// LoadMasterKey decodes a base64 master key, read from file when key is
+// empty. It returns nil when neither is set.
+func LoadMasterKey(key, file string) ([]byte, error) {
+	if key == "" && file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read master key: %w", err)
+		}
+		key = string(data)
+	}
+	if key == "" {
+		return nil, nil
+	}
+	return DecodeKey(key)
+}
+
+// DecodeKey decodes a base64 key of KeySize bytes, as printed by
+// `openssl rand -base64 32`
+func DecodeKey(key string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("master key must be base64: %w", err)
+	}
+	if len(decoded) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", KeySize, len(decoded))
+	}
+	return decoded, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption: key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts with a random nonce, which prefixes the result
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
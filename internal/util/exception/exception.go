@@ -1,6 +1,7 @@
 package exception
 
 import (
+	"ai-service/internal/util/exceptioncode"
 	"ai-service/internal/util/logger"
 	"context"
 	"database/sql"
@@ -26,7 +27,7 @@ func PanicOnErrorContext(ctx context.Context, err error) {
 func TranslateDatabaseError(ctx context.Context, err error) error {
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return errors.New("empty result")
+			return exceptioncode.ErrEmptyResult
 		}
 
 		// Handle PostgreSQL errors
@@ -34,9 +35,9 @@ func TranslateDatabaseError(ctx context.Context, err error) error {
 		if errors.As(err, &pgErr) {
 			switch pgErr.Code {
 			case "23505": // unique_violation
-				return errors.New("duplicate key value")
+				return exceptioncode.ErrDupeKey
 			case "23503": // foreign_key_violation
-				return errors.New("foreign key violation")
+				return exceptioncode.ErrForeignKeyViolation
 			case "23502": // not_null_violation
 				return errors.New("required field is missing")
 			case "42P01": // undefined_table
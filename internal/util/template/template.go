@@ -49,6 +49,9 @@ func Init() {
 			}
 			return a / b
 		},
+		"usd": func(amount float64) string {
+			return fmt.Sprintf("$%.2f", amount)
+		},
 		"safe": func(s string) template.HTML {
 			return template.HTML(s)
 		},
@@ -0,0 +1,163 @@
+// Package tokenizer estimates token counts offline so request sizes can be
+// checked against a model's context window before a provider is called. Counts approximate each model
+// family's BPE vocabulary and are not exact.
+package tokenizer
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"ai-service/internal/model"
+)
+
+// Family groups models that share a tokenizer
+type Family string
+
+const (
+	FamilyGPT    Family = "gpt"
+	FamilyClaude Family = "claude"
+	FamilyGemini Family = "gemini"
+)
+
+// encoding describes how a family splits text and frames chat messages
+type encoding struct {
+	// Words up to this length are usually a single vocabulary entry
+	wordLength int
+	// Average characters per token for the rest of a longer word
+	charsPerToken float64
+	// Average digits per token for runs of digits
+	digitsPerToken float64
+	// Tokens added around each chat message for role markers
+	messageOverhead int
+	// Tokens that prime the assistant's reply
+	replyOverhead int
+}
+
+var encodings = map[Family]encoding{
+	FamilyGPT:    {wordLength: 7, charsPerToken: 4, digitsPerToken: 3, messageOverhead: 3, replyOverhead: 3},
+	FamilyClaude: {wordLength: 6, charsPerToken: 3, digitsPerToken: 1, messageOverhead: 4, replyOverhead: 1},
+	FamilyGemini: {wordLength: 7, charsPerToken: 4, digitsPerToken: 1, messageOverhead: 2, replyOverhead: 0},
+}
+
+// FamilyOf returns the tokenizer family of a model, defaulting to GPT
+func FamilyOf(modelName string) Family {
+	name := strings.ToLower(modelName)
+	switch {
+	case strings.HasPrefix(name, "claude"):
+		return FamilyClaude
+	case strings.HasPrefix(name, "gemini"):
+		return FamilyGemini
+	}
+	return FamilyGPT
+}
+
+// Count estimates the number of tokens in text for a model
+func Count(modelName, text string) int {
+	enc := encodings[FamilyOf(modelName)]
+
+	tokens := 0
+	var run []rune
+	runKind := runNone
+
+	flush := func() {
+		switch runKind {
+		case runLetters:
+			tokens++
+			if len(run) > enc.wordLength {
+				tokens += ceilDiv(len(run)-enc.wordLength, enc.charsPerToken)
+			}
+		case runDigits:
+			tokens += ceilDiv(len(run), enc.digitsPerToken)
+		case runSpaces:
+			// A single space is merged into the following word
+			if len(run) > 1 {
+				tokens++
+			}
+		}
+		run = run[:0]
+		runKind = runNone
+	}
+
+	for _, r := range text {
+		kind := kindOf(r)
+		if kind == runSymbol {
+			// Punctuation and non-Latin characters cost a token each
+			flush()
+			tokens++
+			continue
+		}
+		if kind != runKind {
+			flush()
+			runKind = kind
+		}
+		run = append(run, r)
+	}
+	flush()
+
+	return tokens
+}
+
+// CountRequest estimates the prompt tokens of a generation request: the
+// system message, every prior turn and the new prompt, with chat framing
+func CountRequest(modelName string, req *model.GenerationRequest) int {
+	enc := encodings[FamilyOf(modelName)]
+
+	tokens := enc.replyOverhead
+	if req.SystemMsg != "" {
+		tokens += enc.messageOverhead + Count(modelName, req.SystemMsg)
+	}
+	for _, message := range req.Messages {
+		tokens += enc.messageOverhead + Count(modelName, message.Content)
+	}
+	tokens += enc.messageOverhead + Count(modelName, req.Prompt)
+
+	return tokens
+}
+
+// Truncate returns the longest prefix of text that fits in maxTokens
+func Truncate(modelName, text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if Count(modelName, text) <= maxTokens {
+		return text
+	}
+
+	// Token counts grow with length, so binary search the cut point
+	runes := []rune(text)
+	cut := sort.Search(len(runes)+1, func(i int) bool {
+		return Count(modelName, string(runes[:i])) > maxTokens
+	})
+	return string(runes[:cut-1])
+}
+
+// runKind classifies the characters of a run that is counted as a unit
+type runKind int
+
+const (
+	runNone runKind = iota
+	runLetters
+	runDigits
+	runSpaces
+	runSymbol
+)
+
+// kindOf classifies a character for Count
+func kindOf(r rune) runKind {
+	switch {
+	case unicode.IsSpace(r):
+		return runSpaces
+	case unicode.IsDigit(r):
+		return runDigits
+	case r < unicode.MaxLatin1 && unicode.IsLetter(r):
+		return runLetters
+	}
+	return runSymbol
+}
+
+// ceilDiv divides a run length by a per-token ratio, rounding up
+func ceilDiv(length int, perToken float64) int {
+	return int(math.Ceil(float64(length) / perToken))
+}
@@ -0,0 +1,6 @@
+DROP TABLE api_keys;
+DROP TABLE stats;
+DROP TABLE providers;
+DROP TABLE generations;
+
+DROP FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+DROP INDEX idx_providers_config_gin;
+DROP INDEX idx_generations_prompt_gin;
+DROP INDEX idx_stats_date_range;
+DROP INDEX idx_api_keys_active;
+DROP INDEX idx_providers_active;
+DROP INDEX idx_generations_status_created_at;
+DROP INDEX idx_generations_provider_created_at;
@@ -0,0 +1,3 @@
+DROP INDEX idx_generations_comparison_id;
+
+ALTER TABLE generations DROP COLUMN comparison_id;
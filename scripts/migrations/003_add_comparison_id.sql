@@ -0,0 +1,4 @@
+-- Group the legs of a provider comparison under a shared ID
+ALTER TABLE generations ADD COLUMN comparison_id UUID;
+
+CREATE INDEX idx_generations_comparison_id ON generations(comparison_id) WHERE comparison_id IS NOT NULL;
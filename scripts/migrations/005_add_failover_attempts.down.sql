@@ -0,0 +1,4 @@
+DROP INDEX idx_generations_failed_attempts;
+
+ALTER TABLE generations DROP COLUMN failed_attempts;
+ALTER TABLE generations DROP COLUMN requested_provider;
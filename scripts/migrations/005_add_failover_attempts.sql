@@ -0,0 +1,5 @@
+-- Record cross-provider failover on each generation
+ALTER TABLE generations ADD COLUMN requested_provider VARCHAR(50);
+ALTER TABLE generations ADD COLUMN failed_attempts JSONB;
+
+CREATE INDEX idx_generations_failed_attempts ON generations(provider) WHERE failed_attempts IS NOT NULL;
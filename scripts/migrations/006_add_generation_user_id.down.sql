@@ -0,0 +1,3 @@
+DROP INDEX idx_generations_user_id;
+
+ALTER TABLE generations DROP COLUMN user_id;
@@ -0,0 +1,4 @@
+-- Attribute each generation to the authenticated caller
+ALTER TABLE generations ADD COLUMN user_id VARCHAR(255);
+
+CREATE INDEX idx_generations_user_id ON generations(user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,14 @@
+-- Client API keys have no provider and cannot exist in the previous schema
+DROP INDEX idx_api_keys_key_hash;
+
+DELETE FROM api_keys WHERE provider IS NULL;
+
+ALTER TABLE api_keys DROP COLUMN revoked_at;
+ALTER TABLE api_keys DROP COLUMN last_used_at;
+ALTER TABLE api_keys DROP COLUMN usage_count;
+ALTER TABLE api_keys DROP COLUMN allowed_models;
+ALTER TABLE api_keys DROP COLUMN allowed_providers;
+ALTER TABLE api_keys DROP COLUMN role;
+ALTER TABLE api_keys DROP COLUMN key_prefix;
+ALTER TABLE api_keys DROP COLUMN name;
+ALTER TABLE api_keys ALTER COLUMN provider SET NOT NULL;
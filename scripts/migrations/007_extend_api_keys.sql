@@ -0,0 +1,13 @@
+-- Turn api_keys into client API keys for service-to-service callers.
+-- Keys are shown once on creation; only their SHA-256 hash is stored.
+ALTER TABLE api_keys ALTER COLUMN provider DROP NOT NULL;
+ALTER TABLE api_keys ADD COLUMN name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN key_prefix VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE api_keys ADD COLUMN allowed_providers TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN allowed_models TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN usage_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE api_keys ADD COLUMN revoked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys(key_hash);
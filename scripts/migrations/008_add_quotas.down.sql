@@ -0,0 +1,3 @@
+DROP INDEX idx_generations_user_id_created_at;
+
+ALTER TABLE api_keys DROP COLUMN quota;
@@ -0,0 +1,5 @@
+-- Per-key token and request budgets, and an index for per-caller usage lookups.
+-- A NULL quota means the key uses the default limits from QUOTA_* settings.
+ALTER TABLE api_keys ADD COLUMN quota JSONB;
+
+CREATE INDEX idx_generations_user_id_created_at ON generations(user_id, created_at DESC) WHERE user_id IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE generations DROP COLUMN cost_usd;
+
+DROP TABLE model_pricing;
@@ -0,0 +1,27 @@
+-- Versioned per-model pricing in USD per million tokens, and the cost of each
+-- generation computed from the price in effect when it was recorded.
+CREATE TABLE model_pricing (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider VARCHAR(50) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    prompt_usd_per_million NUMERIC(12, 6) NOT NULL,
+    completion_usd_per_million NUMERIC(12, 6) NOT NULL,
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (provider, model, effective_from)
+);
+
+CREATE INDEX idx_model_pricing_lookup ON model_pricing(provider, model, effective_from DESC);
+
+INSERT INTO model_pricing (provider, model, prompt_usd_per_million, completion_usd_per_million, effective_from) VALUES
+    ('openai', 'gpt-3.5-turbo', 0.50, 1.50, '2024-01-01'),
+    ('openai', 'gpt-4', 30.00, 60.00, '2024-01-01'),
+    ('openai', 'gpt-4-turbo', 10.00, 30.00, '2024-01-01'),
+    ('gemini', 'gemini-1.5-flash', 0.075, 0.30, '2024-01-01'),
+    ('gemini', 'gemini-1.5-pro', 1.25, 5.00, '2024-01-01'),
+    ('gemini', 'gemini-2.0-flash', 0.10, 0.40, '2024-01-01'),
+    ('anthropic', 'claude-3-opus', 15.00, 75.00, '2024-01-01'),
+    ('anthropic', 'claude-3-sonnet', 3.00, 15.00, '2024-01-01'),
+    ('anthropic', 'claude-3-haiku', 0.25, 1.25, '2024-01-01');
+
+ALTER TABLE generations ADD COLUMN cost_usd NUMERIC(12, 6);
@@ -0,0 +1,4 @@
+ALTER TABLE generations DROP COLUMN tokens_estimated;
+ALTER TABLE generations DROP COLUMN total_tokens;
+ALTER TABLE generations DROP COLUMN completion_tokens;
+ALTER TABLE generations DROP COLUMN prompt_tokens;
@@ -0,0 +1,9 @@
+-- Prompt and completion token counts reported by the provider.
+-- tokens_estimated marks rows whose counts were approximated locally;
+-- existing rows only have a total, and Gemini's was always estimated.
+ALTER TABLE generations ADD COLUMN prompt_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE generations ADD COLUMN completion_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE generations ADD COLUMN total_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE generations ADD COLUMN tokens_estimated BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE generations SET total_tokens = tokens_used, tokens_estimated = (provider = 'gemini');
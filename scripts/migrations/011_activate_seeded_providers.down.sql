@@ -0,0 +1,2 @@
+-- Nothing to revert: the activated row cannot be told apart from one an
+-- admin enabled, and the previous code ignored is_active anyway.
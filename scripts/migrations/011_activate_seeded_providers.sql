@@ -0,0 +1,4 @@
+-- The Manager now honours providers.is_active. Anthropic was seeded
+-- inactive but served requests whenever its key was set, so activate the
+-- seeded row unless an admin has edited it since.
+UPDATE providers SET is_active = true WHERE name = 'anthropic' AND updated_at = created_at;
@@ -0,0 +1,7 @@
+-- Stored provider keys are lost; providers fall back to their environment
+DROP TABLE provider_key_audit;
+
+ALTER TABLE providers DROP COLUMN api_key_updated_at;
+ALTER TABLE providers DROP COLUMN encrypted_data_key;
+ALTER TABLE providers DROP COLUMN encrypted_api_key;
+ALTER TABLE providers ADD COLUMN api_key_hash VARCHAR(255);
@@ -0,0 +1,18 @@
+-- Provider API keys stored with envelope encryption: each key is encrypted
+-- under its own data key, which is encrypted under the master key held
+-- outside the database. api_key_hash could not be used to call a provider.
+ALTER TABLE providers DROP COLUMN api_key_hash;
+ALTER TABLE providers ADD COLUMN encrypted_api_key BYTEA;
+ALTER TABLE providers ADD COLUMN encrypted_data_key BYTEA;
+ALTER TABLE providers ADD COLUMN api_key_updated_at TIMESTAMP WITH TIME ZONE;
+
+-- Who changed which provider key and when; never the key itself
+CREATE TABLE provider_key_audit (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider VARCHAR(50) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_provider_key_audit_provider ON provider_key_audit(provider, created_at DESC);
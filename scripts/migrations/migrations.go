@@ -0,0 +1,11 @@
+// Package migrations embeds the database migrations so the binary can apply
+// them without the source tree.
+package migrations
+
+import "embed"
+
+// FS holds every migration: NNN_name.sql applies version NNN and
+// NNN_name.down.sql reverts it
+//
+//go:embed *.sql
+var FS embed.FS
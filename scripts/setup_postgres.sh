@@ -39,19 +39,13 @@ createdb -U $DB_USER $DB_NAME 2>/dev/null || echo "   Database already exists"
 
 # Run migrations
 echo "📝 Running migrations..."
-if [ -f "scripts/migrations/001_initial_schema.sql" ]; then
-    psql -U $DB_USER -d $DB_NAME -f scripts/migrations/001_initial_schema.sql
-    echo "   ✅ Initial schema applied"
-else
-    echo "   ⚠️  Migration file not found: scripts/migrations/001_initial_schema.sql"
-fi
-
-if [ -f "scripts/migrations/002_add_performance_indexes.sql" ]; then
-    psql -U $DB_USER -d $DB_NAME -f scripts/migrations/002_add_performance_indexes.sql
-    echo "   ✅ Performance indexes applied"
-else
-    echo "   ⚠️  Migration file not found: scripts/migrations/002_add_performance_indexes.sql"
+if ! DB_NAME=$DB_NAME DB_USER=$DB_USER go run cmd/main/main.go migrate up; then
+    echo "   ❌ Migrations failed"
+    echo "   💡 A database set up by an earlier version of this script has no schema_migrations;"
+    echo "      record its migrations with: go run cmd/main/main.go migrate baseline <version>"
+    exit 1
 fi
+echo "   ✅ Migrations applied"
 
 # Create test database
 echo "🧪 Creating test database..."
@@ -0,0 +1,217 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+func TestAnthropicProvider_Generate(t *testing.T) {
+	// Setup
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.AssertEqual(t, "/messages", r.URL.Path, "Request path should be /messages")
+		utils.AssertEqual(t, "test-anthropic-key", r.Header.Get("x-api-key"), "API key header should be set")
+		utils.AssertEqual(t, "2023-06-01", r.Header.Get("anthropic-version"), "Version header should be set")
+
+		err := json.NewDecoder(r.Body).Decode(&received)
+		utils.AssertNoError(t, err, "Failed to decode request body")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "msg_123",
+			"type": "message",
+			"role": "assistant",
+			"model": "claude-3-haiku-20240307",
+			"content": [{"type": "text", "text": "Hello from Claude"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 12, "output_tokens": 5}
+		}`))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewAnthropicProvider("test-anthropic-key", server.URL, "")
+	ctx := utils.TestContext(t)
+
+	// Execute
+	resp, err := provider.Generate(ctx, &model.GenerationRequest{
+		Provider:  model.Anthropic,
+		Model:     "claude-3-haiku",
+		Prompt:    "Say hello",
+		SystemMsg: "You are terse",
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to generate content")
+	utils.AssertEqual(t, "Hello from Claude", resp.Content, "Content should match")
+	utils.AssertEqual(t, model.Anthropic, resp.Provider, "Provider should be anthropic")
+	utils.AssertEqual(t, "claude-3-haiku-20240307", resp.Model, "Model should match")
+	utils.AssertEqual(t, 17, resp.TokensUsed, "Tokens should be input plus output")
+	utils.AssertEqual(t, 12, resp.PromptTokens, "Prompt tokens should be input tokens")
+	utils.AssertEqual(t, 5, resp.CompletionTokens, "Completion tokens should be output tokens")
+	utils.AssertEqual(t, false, resp.Estimated, "Reported usage should not be an estimate")
+
+	utils.AssertEqual(t, "You are terse", received["system"], "System prompt should be a top-level field")
+	utils.AssertEqual(t, "claude-3-haiku-20240307", received["model"], "Model alias should be resolved")
+	utils.AssertEqual(t, float64(1024), received["max_tokens"], "Default max_tokens should be sent")
+
+	messages := received["messages"].([]interface{})
+	utils.AssertEqual(t, 1, len(messages), "Only the user turn should be sent as a message")
+	utils.AssertEqual(t, "user", messages[0].(map[string]interface{})["role"], "Message role should be user")
+}
+
+func TestAnthropicProvider_GenerateWithHistory(t *testing.T) {
+	// Setup
+	var received struct {
+		Messages []model.Message `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&received)
+		utils.AssertNoError(t, err, "Failed to decode request body")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Blue"}], "usage": {"input_tokens": 20, "output_tokens": 1}}`))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewAnthropicProvider("test-anthropic-key", server.URL, "")
+	ctx := utils.TestContext(t)
+
+	// Execute
+	_, err := provider.Generate(ctx, &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Prompt:   "And the sea?",
+		Messages: []model.Message{
+			{Role: model.RoleUser, Content: "What colour is the sky?"},
+			{Role: model.RoleAssistant, Content: "Blue"},
+		},
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to generate content")
+	utils.AssertEqual(t, 3, len(received.Messages), "History and new prompt should be sent")
+	utils.AssertEqual(t, model.RoleAssistant, received.Messages[1].Role, "Assistant turn should be replayed")
+	utils.AssertEqual(t, "And the sea?", received.Messages[2].Content, "New prompt should be last")
+}
+
+func TestAnthropicProvider_GenerateError(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type": "error", "error": {"type": "invalid_request_error", "message": "bad model"}}`))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewAnthropicProvider("test-anthropic-key", server.URL, "")
+	ctx := utils.TestContext(t)
+
+	// Execute
+	_, err := provider.Generate(ctx, &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertError(t, err, "Generate should fail on API error")
+}
+
+func TestAnthropicProvider_ValidateRequest(t *testing.T) {
+	provider := outbound.NewAnthropicProvider("test-anthropic-key", "", "")
+
+	utils.AssertError(t, provider.ValidateRequest(&model.GenerationRequest{}), "Empty prompt should be rejected")
+	utils.AssertError(t, provider.ValidateRequest(&model.GenerationRequest{Prompt: "hi", MaxTokens: 9000}), "Too many tokens should be rejected")
+	utils.AssertError(t, provider.ValidateRequest(&model.GenerationRequest{Prompt: "hi", Temperature: 1.5}), "Temperature above 1 should be rejected")
+	utils.AssertNoError(t, provider.ValidateRequest(&model.GenerationRequest{Prompt: "hi", MaxTokens: 1000, Temperature: 0.5}), "Valid request should pass")
+}
+
+func TestAnthropicProvider_GenerateStream(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		utils.AssertNoError(t, err, "Failed to decode request body")
+		utils.AssertEqual(t, true, body["stream"], "Stream option should be set")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: message_start\n" +
+			`data: {"type": "message_start", "message": {"model": "claude-3-haiku-20240307", "usage": {"input_tokens": 9}}}` + "\n\n" +
+			"event: content_block_delta\n" +
+			`data: {"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Hel"}}` + "\n\n" +
+			"event: ping\n" +
+			`data: {"type": "ping"}` + "\n\n" +
+			"event: content_block_delta\n" +
+			`data: {"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "lo"}}` + "\n\n" +
+			"event: message_delta\n" +
+			`data: {"type": "message_delta", "usage": {"output_tokens": 3}}` + "\n\n" +
+			"event: message_stop\n" +
+			`data: {"type": "message_stop"}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewAnthropicProvider("test-anthropic-key", server.URL, "")
+	ctx := utils.TestContext(t)
+
+	// Execute
+	var chunks []string
+	resp, err := provider.GenerateStream(ctx, &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Prompt:   "Say hello",
+	}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to stream content")
+	utils.AssertEqual(t, 2, len(chunks), "Should receive two text chunks")
+	utils.AssertEqual(t, "Hello", resp.Content, "Assembled content should match")
+	utils.AssertEqual(t, "claude-3-haiku-20240307", resp.Model, "Model should come from message_start")
+	utils.AssertEqual(t, 12, resp.TokensUsed, "Tokens should be input plus output")
+	utils.AssertEqual(t, 9, resp.PromptTokens, "Prompt tokens should come from message_start")
+	utils.AssertEqual(t, 3, resp.CompletionTokens, "Completion tokens should come from message_delta")
+}
+
+func TestAnthropicProvider_FactoryUsesDefaultModel(t *testing.T) {
+	// Setup
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&received)
+		utils.AssertNoError(t, err, "Failed to decode request body")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"model": "claude-3-haiku-20240307",
+			"content": [{"type": "text", "text": "Hi"}],
+			"usage": {"input_tokens": 3, "output_tokens": 1}
+		}`))
+	}))
+	defer server.Close()
+
+	var definition outbound.ProviderDefinition
+	for _, registered := range outbound.RegisteredProviders() {
+		if registered.ID == model.Anthropic {
+			definition = registered
+		}
+	}
+	provider, err := definition.New(outbound.ProviderSettings{
+		outbound.SettingAPIKey:       "test-anthropic-key",
+		outbound.SettingBaseURL:      server.URL,
+		outbound.SettingDefaultModel: "claude-3-haiku",
+	}, outbound.DefaultRetryPolicy())
+	utils.AssertNoError(t, err, "Factory should build the provider")
+
+	// Execute
+	_, err = provider.Generate(utils.TestContext(t), &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Prompt:   "Say hi",
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to generate content")
+	utils.AssertEqual(t, "claude-3-haiku-20240307", received["model"], "Configured default model should be sent")
+}
@@ -0,0 +1,135 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-service/internal/app/middleware"
+	"ai-service/internal/model"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"ai-service/tests/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAPIKeyStore serves keys from memory and counts recorded uses
+type fakeAPIKeyStore struct {
+	keys  map[string]*model.APIKey
+	usage map[string]int
+}
+
+func (s *fakeAPIKeyStore) GetActiveByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	key, ok := s.keys[keyHash]
+	if !ok {
+		return nil, exceptioncode.ErrEmptyResult
+	}
+	return key, nil
+}
+
+func (s *fakeAPIKeyStore) RecordUsage(ctx context.Context, id string) error {
+	s.usage[id]++
+	return nil
+}
+
+func newAPIKeyRouter(store middleware.APIKeyStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/protected", middleware.Authenticate(true, store), middleware.RequireRole(true, authentication.RoleUser), func(c *gin.Context) {
+		claims, _ := authentication.ClaimsFromContext(c.Request.Context())
+		key, _ := authentication.APIKeyFromContext(c.Request.Context())
+		c.JSON(200, gin.H{"user_id": claims.UserID, "key_name": key.Name})
+	})
+	return router
+}
+
+func sendWithAPIKey(router *gin.Engine, apiKey string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("X-API-Key", apiKey)
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestGenerateAPIKey_ReturnsPrefixAndHash(t *testing.T) {
+	// Execute
+	key, prefix, hash, err := authentication.GenerateAPIKey()
+	otherKey, _, _, _ := authentication.GenerateAPIKey()
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to generate API key")
+	utils.AssertEqual(t, 68, len(key), "Key should be the prefix plus 64 hex characters")
+	utils.AssertEqual(t, key[:12], prefix, "Prefix should be the start of the key")
+	utils.AssertEqual(t, authentication.HashAPIKey(key), hash, "Hash should match HashAPIKey")
+	utils.AssertEqual(t, true, key != otherKey, "Keys should be random")
+}
+
+func TestAPIKey_Allows(t *testing.T) {
+	// Setup
+	unrestricted := &model.APIKey{}
+	scoped := &model.APIKey{
+		AllowedProviders: []string{"anthropic"},
+		AllowedModels:    []string{"claude-3-haiku"},
+	}
+
+	// Execute & Assert
+	utils.AssertEqual(t, true, unrestricted.Allows("openai", "gpt-4"), "Empty scopes should allow everything")
+	utils.AssertEqual(t, true, scoped.Allows("anthropic", "claude-3-haiku"), "Listed provider and model should be allowed")
+	utils.AssertEqual(t, false, scoped.Allows("openai", "claude-3-haiku"), "Unlisted provider should be rejected")
+	utils.AssertEqual(t, false, scoped.Allows("anthropic", "claude-3-opus"), "Unlisted model should be rejected")
+}
+
+func TestAuthenticate_AcceptsValidAPIKey(t *testing.T) {
+	// Setup
+	key, _, hash, err := authentication.GenerateAPIKey()
+	utils.AssertNoError(t, err, "Failed to generate API key")
+	store := &fakeAPIKeyStore{
+		keys:  map[string]*model.APIKey{hash: {ID: "key-1", Name: "billing-service", Role: authentication.RoleUser}},
+		usage: map[string]int{},
+	}
+	router := newAPIKeyRouter(store)
+
+	// Execute
+	recorder := sendWithAPIKey(router, key)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusOK, recorder.Code, "Valid API key should be accepted")
+	utils.AssertEqual(t, `{"key_name":"billing-service","user_id":"api_key:key-1"}`, recorder.Body.String(), "Key should be in the request context")
+	utils.AssertEqual(t, 1, store.usage["key-1"], "Usage should be recorded")
+}
+
+func TestAuthenticate_AcceptsAPIKeyAsBearerToken(t *testing.T) {
+	// Setup
+	key, _, hash, err := authentication.GenerateAPIKey()
+	utils.AssertNoError(t, err, "Failed to generate API key")
+	store := &fakeAPIKeyStore{
+		keys:  map[string]*model.APIKey{hash: {ID: "key-1", Name: "openai-client", Role: authentication.RoleUser}},
+		usage: map[string]int{},
+	}
+	router := newAPIKeyRouter(store)
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	// Execute
+	router.ServeHTTP(recorder, req)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusOK, recorder.Code, "API key sent as a Bearer token should be accepted")
+	utils.AssertEqual(t, 1, store.usage["key-1"], "Usage should be recorded")
+}
+
+func TestAuthenticate_RejectsUnknownAPIKey(t *testing.T) {
+	// Setup
+	store := &fakeAPIKeyStore{keys: map[string]*model.APIKey{}, usage: map[string]int{}}
+	router := newAPIKeyRouter(store)
+
+	// Execute
+	recorder := sendWithAPIKey(router, "ais_unknown")
+
+	// Assert
+	utils.AssertEqual(t, http.StatusUnauthorized, recorder.Code, "Unknown API key should be rejected")
+	utils.AssertEqual(t, 0, len(store.usage), "Rejected keys should not be counted")
+}
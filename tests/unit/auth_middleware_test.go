@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/app/middleware"
+	"ai-service/internal/util/authentication"
+	"ai-service/tests/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newAuthRouter(roles ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/protected", middleware.Authenticate(true, nil), middleware.RequireRole(true, roles...), func(c *gin.Context) {
+		claims, _ := authentication.ClaimsFromContext(c.Request.Context())
+		c.JSON(200, gin.H{"user_id": claims.UserID})
+	})
+	return router
+}
+
+func sendWithToken(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAuthenticate_AcceptsValidToken(t *testing.T) {
+	// Setup
+	authentication.Init("test-secret")
+	router := newAuthRouter(authentication.RoleUser, authentication.RoleAdmin)
+	token, err := authentication.GenerateToken("user-1", "user-1", "", authentication.RoleUser)
+	utils.AssertNoError(t, err, "Failed to generate token")
+
+	// Execute
+	recorder := sendWithToken(router, token)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusOK, recorder.Code, "Valid token should be accepted")
+	utils.AssertEqual(t, `{"user_id":"user-1"}`, recorder.Body.String(), "Claims should be in the request context")
+}
+
+func TestAuthenticate_RejectsMissingOrInvalidToken(t *testing.T) {
+	// Setup
+	authentication.Init("test-secret")
+	router := newAuthRouter(authentication.RoleUser)
+
+	// Execute & Assert
+	utils.AssertEqual(t, http.StatusUnauthorized, sendWithToken(router, "").Code, "Missing token should be rejected")
+	utils.AssertEqual(t, http.StatusUnauthorized, sendWithToken(router, "not-a-token").Code, "Invalid token should be rejected")
+}
+
+func TestRequireRole_RejectsWrongRole(t *testing.T) {
+	// Setup
+	authentication.Init("test-secret")
+	router := newAuthRouter(authentication.RoleAdmin)
+	token, err := authentication.GenerateToken("user-1", "user-1", "", authentication.RoleUser)
+	utils.AssertNoError(t, err, "Failed to generate token")
+
+	// Execute
+	recorder := sendWithToken(router, token)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusForbidden, recorder.Code, "User role should not reach admin routes")
+}
+
+func TestAuthenticate_RejectsTokensSignedWithPlaceholderSecret(t *testing.T) {
+	// Setup
+	authentication.Init("your-secret-key")
+	defer authentication.Init("test-secret")
+	router := newAuthRouter(authentication.RoleAdmin)
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, authentication.JWTClaim{
+		UserID: "attacker",
+		Role:   authentication.RoleAdmin,
+	}).SignedString([]byte("your-secret-key"))
+	utils.AssertNoError(t, err, "Failed to sign token")
+
+	// Execute
+	recorder := sendWithToken(router, forged)
+	_, generateErr := authentication.GenerateToken("user-1", "user-1", "", authentication.RoleAdmin)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusUnauthorized, recorder.Code, "Tokens signed with the placeholder secret should be rejected")
+	utils.AssertError(t, generateErr, "Tokens should not be issued with the placeholder secret")
+}
+
+func TestConfig_RejectsPlaceholderJWTSecretWhenAuthEnabled(t *testing.T) {
+	// Setup
+	cfg := &config.Config{}
+	cfg.Server.Port = "8080"
+	cfg.Database.Driver = "postgres"
+	cfg.Preflight.Mode = config.PreflightReject
+	cfg.Security.AuthEnabled = true
+	cfg.Security.JWTSecret = "your-secret-key"
+
+	// Execute
+	err := cfg.Validate()
+
+	// Assert
+	utils.AssertError(t, err, "Placeholder secret should be rejected outside production too")
+}
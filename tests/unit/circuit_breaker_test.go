@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	// Setup
+	breaker := outbound.NewCircuitBreaker(outbound.BreakerSettings{
+		FailureThreshold:    2,
+		OpenTimeout:         20 * time.Millisecond,
+		HalfOpenMaxRequests: 1,
+	})
+	unavailable := &outbound.ProviderError{StatusCode: http.StatusServiceUnavailable}
+
+	// Execute & Assert
+	for i := 0; i < 2; i++ {
+		utils.AssertNoError(t, breaker.Allow(), "Closed breaker should allow calls")
+		breaker.Record(unavailable)
+	}
+	utils.AssertEqual(t, outbound.BreakerOpen, breaker.Status().State, "Breaker should open at the threshold")
+	utils.AssertEqual(t, true, errors.Is(breaker.Allow(), outbound.ErrCircuitOpen), "Open breaker should reject calls")
+
+	time.Sleep(30 * time.Millisecond)
+	utils.AssertEqual(t, outbound.BreakerHalfOpen, breaker.Status().State, "Breaker should be half-open after the timeout")
+	utils.AssertNoError(t, breaker.Allow(), "Half-open breaker should allow a trial call")
+	utils.AssertError(t, breaker.Allow(), "Half-open breaker should limit concurrent trial calls")
+
+	breaker.Record(nil)
+	utils.AssertEqual(t, outbound.BreakerClosed, breaker.Status().State, "Successful trial should close the breaker")
+	utils.AssertEqual(t, 0, breaker.Status().ConsecutiveFailures, "Failures should reset on success")
+}
+
+func TestCircuitBreaker_IgnoresFatalErrors(t *testing.T) {
+	// Setup
+	breaker := outbound.NewCircuitBreaker(outbound.BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenMaxRequests: 1})
+
+	// Execute
+	utils.AssertNoError(t, breaker.Allow(), "Closed breaker should allow calls")
+	breaker.Record(&outbound.ProviderError{StatusCode: http.StatusBadRequest})
+
+	// Assert
+	utils.AssertEqual(t, outbound.BreakerClosed, breaker.Status().State, "Bad requests should not open the breaker")
+}
+
+func TestManager_GenerateFailsFastWhenCircuitOpen(t *testing.T) {
+	// Setup
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = server.URL
+	cfg.Retry.MaxAttempts = 1
+	cfg.CircuitBreaker.FailureThreshold = 2
+	cfg.CircuitBreaker.OpenTimeout = time.Minute
+
+	manager := outbound.NewManager(cfg)
+	ctx := utils.TestContext(t)
+	req := &model.GenerationRequest{Provider: model.Anthropic, Prompt: "Say hello"}
+
+	// Execute
+	for i := 0; i < 3; i++ {
+		_, err := manager.Generate(ctx, req)
+		utils.AssertError(t, err, "Generate should fail while the upstream is down")
+	}
+	_, err := manager.Generate(ctx, req)
+
+	// Assert
+	utils.AssertEqual(t, 2, requests, "Calls after the breaker opens should not reach the upstream")
+	utils.AssertEqual(t, true, errors.Is(err, outbound.ErrCircuitOpen), "Error should report the open circuit")
+	utils.AssertEqual(t, outbound.BreakerOpen, manager.ProviderHealth()[model.Anthropic].State, "Health should report the open breaker")
+}
@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"ai-service/internal/util/encryption"
+	"ai-service/tests/utils"
+)
+
+func newMasterKey(t *testing.T) []byte {
+	key := make([]byte, encryption.KeySize)
+	_, err := rand.Read(key)
+	utils.AssertNoError(t, err, "Failed to generate master key")
+	return key
+}
+
+func TestEnvelope_SealAndOpen(t *testing.T) {
+	// Setup
+	cipher, err := encryption.NewCipher(newMasterKey(t))
+	utils.AssertNoError(t, err, "Failed to create cipher")
+
+	// Execute
+	first, err := cipher.Seal([]byte("sk-secret"))
+	utils.AssertNoError(t, err, "Failed to seal")
+	second, err := cipher.Seal([]byte("sk-secret"))
+	utils.AssertNoError(t, err, "Failed to seal")
+	plaintext, err := cipher.Open(first)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to open")
+	utils.AssertEqual(t, "sk-secret", string(plaintext), "Opened secret should match")
+	utils.AssertEqual(t, false, string(first.DataKey) == string(second.DataKey), "Each secret should get its own data key")
+
+	first.Ciphertext[len(first.Ciphertext)-1] ^= 1
+	_, err = cipher.Open(first)
+	utils.AssertError(t, err, "Tampered envelopes should not open")
+}
+
+func TestEnvelope_RotatesMasterKey(t *testing.T) {
+	// Setup
+	oldKey, newKey := newMasterKey(t), newMasterKey(t)
+	oldCipher, err := encryption.NewCipher(oldKey)
+	utils.AssertNoError(t, err, "Failed to create cipher")
+	sealed, err := oldCipher.Seal([]byte("sk-secret"))
+	utils.AssertNoError(t, err, "Failed to seal")
+
+	rotating, err := encryption.NewCipher(newKey, oldKey)
+	utils.AssertNoError(t, err, "Failed to create cipher")
+	newCipher, err := encryption.NewCipher(newKey)
+	utils.AssertNoError(t, err, "Failed to create cipher")
+
+	// Execute
+	resealed, err := rotating.Reseal(sealed)
+
+	// Assert
+	utils.AssertNoError(t, err, "Previous master keys should still open envelopes")
+	plaintext, err := newCipher.Open(resealed)
+	utils.AssertNoError(t, err, "Resealed envelope should open with the new master key alone")
+	utils.AssertEqual(t, "sk-secret", string(plaintext), "Resealing should keep the secret")
+	_, err = newCipher.Open(sealed)
+	utils.AssertError(t, err, "Envelopes sealed under another master key should not open")
+}
+
+func TestEnvelope_DecodeKeyRequires32Bytes(t *testing.T) {
+	// Execute
+	key, err := encryption.DecodeKey(base64.StdEncoding.EncodeToString(make([]byte, encryption.KeySize)) + "\n")
+	_, shortErr := encryption.DecodeKey(base64.StdEncoding.EncodeToString(make([]byte, 16)))
+	_, invalidErr := encryption.DecodeKey("not base64!")
+
+	// Assert
+	utils.AssertNoError(t, err, "32-byte base64 keys should decode")
+	utils.AssertEqual(t, encryption.KeySize, len(key), "Decoded key length should match")
+	utils.AssertError(t, shortErr, "Short keys should be rejected")
+	utils.AssertError(t, invalidErr, "Invalid base64 should be rejected")
+}
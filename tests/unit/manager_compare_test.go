@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+func TestManager_Compare(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"model": "claude-3-haiku-20240307",
+			"content": [{"type": "text", "text": "Compared"}],
+			"usage": {"input_tokens": 4, "output_tokens": 2}
+		}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = server.URL
+	cfg.AIProviders.OpenAI.DefaultModel = "gpt-3.5-turbo"
+
+	manager := outbound.NewManager(cfg)
+	ctx := utils.TestContext(t)
+
+	// Execute
+	resp, err := manager.Compare(ctx, &model.ComparisonRequest{
+		Prompt:    "Compare me",
+		Providers: []model.AIProvider{model.OpenAI, model.Anthropic},
+		Models:    map[model.AIProvider]string{model.Anthropic: "claude-3-haiku"},
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Compare should not fail when one provider succeeds")
+	utils.AssertEqual(t, 2, len(resp.Results), "Should return one result per provider")
+	utils.AssertEqual(t, true, resp.ID != "", "Comparison ID should be set")
+
+	openaiResult := resp.Results[0]
+	utils.AssertEqual(t, model.OpenAI, openaiResult.Provider, "Results should keep request order")
+	utils.AssertEqual(t, "error", openaiResult.Status, "Unconfigured provider should fail")
+	utils.AssertEqual(t, "gpt-3.5-turbo", openaiResult.Model, "Failed leg should report the default model")
+	utils.AssertEqual(t, true, openaiResult.Error != "", "Failed leg should carry its error")
+
+	anthropicResult := resp.Results[1]
+	utils.AssertEqual(t, "success", anthropicResult.Status, "Configured provider should succeed")
+	utils.AssertEqual(t, "Compared", anthropicResult.Content, "Content should match")
+	utils.AssertEqual(t, 6, anthropicResult.TokensUsed, "Tokens should match")
+}
@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"rate limited", &outbound.ProviderError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &outbound.ProviderError{StatusCode: http.StatusInternalServerError}, true},
+		{"overloaded", &outbound.ProviderError{StatusCode: 529}, true},
+		{"bad request", &outbound.ProviderError{StatusCode: http.StatusBadRequest}, false},
+		{"unauthorized", &outbound.ProviderError{StatusCode: http.StatusUnauthorized}, false},
+		{"wrapped server error", fmt.Errorf("stream: %w", &outbound.ProviderError{StatusCode: http.StatusBadGateway}), true},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"caller cancelled", context.Canceled, false},
+		{"validation error", errors.New("validation error: prompt is required"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utils.AssertEqual(t, tt.expected, outbound.IsRetryable(tt.err), "Retryable classification should match")
+		})
+	}
+}
+
+func TestManager_GenerateRetryableErrorWithoutFallback(t *testing.T) {
+	// Setup
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(529)
+		w.Write([]byte(`{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = server.URL
+	cfg.Failover.Enabled = true
+	cfg.Failover.Chain = []string{"anthropic", "openai", "gemini"}
+	cfg.Retry.MaxAttempts = 1
+
+	manager := outbound.NewManager(cfg)
+	ctx := utils.TestContext(t)
+
+	// Execute
+	_, err := manager.Generate(ctx, &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Model:    "claude-3-haiku",
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertError(t, err, "Generate should fail when no fallback provider is configured")
+	utils.AssertEqual(t, 1, requests, "Requested provider should be tried once")
+	utils.AssertEqual(t, true, outbound.IsRetryable(err), "Overloaded error should be retryable")
+
+	var failoverErr *outbound.FailoverError
+	utils.AssertEqual(t, false, errors.As(err, &failoverErr), "Unconfigured fallbacks should be skipped, not recorded")
+}
+
+func TestManager_GenerateSkipsFallbackRejectedByProviderCheck(t *testing.T) {
+	// Setup
+	anthropic := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(529)
+		w.Write([]byte(`{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`))
+	}))
+	defer anthropic.Close()
+	openAIRequests := 0
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		openAIRequests++
+		w.Write([]byte(openAICompletion))
+	}))
+	defer openAI.Close()
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = anthropic.URL
+	cfg.AIProviders.OpenAI.APIKey = "test-openai-key"
+	cfg.AIProviders.OpenAI.BaseURL = openAI.URL
+	cfg.Failover.Enabled = true
+	cfg.Failover.Chain = []string{"anthropic", "openai"}
+	cfg.Retry.MaxAttempts = 1
+
+	manager := outbound.NewManager(cfg)
+	var checked []model.AIProvider
+	ctx := outbound.WithProviderCheck(utils.TestContext(t), func(ctx context.Context, provider model.AIProvider) error {
+		checked = append(checked, provider)
+		return errors.New("quota exceeded")
+	})
+
+	// Execute
+	_, err := manager.Generate(ctx, &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Model:    "claude-3-haiku",
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertError(t, err, "Generate should fail when the only fallback is rejected")
+	utils.AssertEqual(t, 1, len(checked), "The fallback provider should be checked")
+	utils.AssertEqual(t, model.OpenAI, checked[0], "The check should receive the fallback provider")
+	utils.AssertEqual(t, 0, openAIRequests, "A rejected fallback should not be called")
+}
@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"ai-service/internal/app/database"
+	"ai-service/scripts/migrations"
+	"ai-service/tests/utils"
+)
+
+func TestMigrator_ReadsMigrationsInOrder(t *testing.T) {
+	// Setup
+	fsys := fstest.MapFS{
+		"010_add_column.sql":      {Data: []byte("ALTER TABLE t ADD COLUMN c INTEGER;")},
+		"010_add_column.down.sql": {Data: []byte("ALTER TABLE t DROP COLUMN c;")},
+		"002_create_table.sql":    {Data: []byte("CREATE TABLE t (id INTEGER);")},
+		"README.md":               {Data: []byte("not a migration")},
+	}
+
+	// Execute
+	migrator, err := database.NewMigrator(nil, fsys)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to read migrations")
+	list := migrator.Migrations()
+	utils.AssertEqual(t, 2, len(list), "Only .sql up scripts should be migrations")
+	utils.AssertEqual(t, 2, list[0].Version, "Migrations should be ordered by version")
+	utils.AssertEqual(t, "create_table", list[0].Name, "Name should follow the version")
+	utils.AssertEqual(t, false, list[0].Reversible(), "Migration without a down script should not be reversible")
+	utils.AssertEqual(t, true, list[1].Reversible(), "Down script should be attached to its migration")
+	utils.AssertEqual(t, 64, len(list[1].Checksum), "Checksum should be a hex SHA-256")
+	utils.AssertEqual(t, 10, migrator.Latest(), "Latest should be the highest version")
+}
+
+func TestMigrator_RejectsInvalidMigrations(t *testing.T) {
+	// Setup
+	cases := map[string]fstest.MapFS{
+		"duplicate version": {
+			"001_first.sql":  {Data: []byte("SELECT 1;")},
+			"001_second.sql": {Data: []byte("SELECT 2;")},
+		},
+		"down without up": {
+			"001_first.sql":       {Data: []byte("SELECT 1;")},
+			"002_second.down.sql": {Data: []byte("SELECT 2;")},
+		},
+	}
+
+	for name, fsys := range cases {
+		// Execute
+		_, err := database.NewMigrator(nil, fsys)
+
+		// Assert
+		utils.AssertError(t, err, name+" should be rejected")
+	}
+}
+
+func TestMigrator_EmbeddedMigrationsAreReversible(t *testing.T) {
+	// Execute
+	migrator, err := database.NewMigrator(nil, migrations.FS)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to read embedded migrations")
+	for i, migration := range migrator.Migrations() {
+		utils.AssertEqual(t, i+1, migration.Version, "Migration versions should have no gaps")
+		utils.AssertEqual(t, true, migration.Reversible(), migration.Name+" should have a down script")
+	}
+}
+
+func TestMigrator_DownAndUpRoundTrip(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	migrator, err := database.NewMigrator(testDB.DB, migrations.FS)
+	utils.AssertNoError(t, err, "Failed to read migrations")
+	ctx := context.Background()
+	utils.AssertNoError(t, migrator.Verify(ctx), "Migrated database should match")
+
+	// Execute
+	reverted, err := migrator.Down(ctx)
+	utils.AssertNoError(t, err, "Failed to revert latest migration")
+	pendingErr := migrator.Verify(ctx)
+	_, err = migrator.To(ctx, 0)
+	utils.AssertNoError(t, err, "Failed to revert every migration")
+	applied, err := migrator.Up(ctx)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to apply every migration")
+	utils.AssertEqual(t, migrator.Latest(), reverted.Version, "Down should revert the latest migration")
+	utils.AssertEqual(t, true, errors.Is(pendingErr, database.ErrSchemaMismatch), "Pending migration should not match")
+	utils.AssertEqual(t, len(migrator.Migrations()), len(applied), "Up should apply every migration")
+	utils.AssertNoError(t, migrator.Verify(ctx), "Re-migrated database should match")
+}
+
+func TestMigrator_VerifyDetectsChangedMigration(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	migrator, err := database.NewMigrator(testDB.DB, migrations.FS)
+	utils.AssertNoError(t, err, "Failed to read migrations")
+	ctx := context.Background()
+
+	var checksum string
+	err = testDB.QueryRow(`SELECT checksum FROM schema_migrations WHERE version = 1`).Scan(&checksum)
+	utils.AssertNoError(t, err, "Failed to read checksum")
+	_, err = testDB.Exec(`UPDATE schema_migrations SET checksum = $1 WHERE version = 1`, "0000000000000000000000000000000000000000000000000000000000000000")
+	utils.AssertNoError(t, err, "Failed to change checksum")
+	defer testDB.Exec(`UPDATE schema_migrations SET checksum = $1 WHERE version = 1`, checksum)
+
+	// Execute
+	verifyErr := migrator.Verify(ctx)
+	_, upErr := migrator.Up(ctx)
+
+	// Assert
+	utils.AssertEqual(t, true, errors.Is(verifyErr, database.ErrSchemaMismatch), "Changed migration should not match")
+	utils.AssertEqual(t, true, errors.Is(upErr, database.ErrSchemaMismatch), "Up should refuse a changed history")
+}
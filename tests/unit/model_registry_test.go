@@ -0,0 +1,174 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+// fakeProviderConfigStore returns fixed provider rows
+type fakeProviderConfigStore struct {
+	records []*model.ProviderRecord
+}
+
+func (s *fakeProviderConfigStore) List(ctx context.Context) ([]*model.ProviderRecord, error) {
+	return s.records, nil
+}
+
+func TestDefaultRegistry_CoversProviderModels(t *testing.T) {
+	// Setup
+	registry := outbound.DefaultRegistry()
+	now := time.Now()
+
+	// Assert
+	for _, id := range []string{"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo"} {
+		utils.AssertNoError(t, registry.Validate(model.OpenAI, id, 0, now), "OpenAI model should be valid")
+	}
+	for _, id := range []string{"gemini-1.5-flash", "gemini-1.5-pro", "gemini-2.0-flash"} {
+		utils.AssertNoError(t, registry.Validate(model.Gemini, id, 0, now), "Gemini model should be valid")
+	}
+	for _, id := range []string{"claude-3-sonnet", "claude-3-opus", "claude-3-haiku"} {
+		utils.AssertNoError(t, registry.Validate(model.Anthropic, id, 0, now), "Anthropic model should be valid")
+	}
+}
+
+func TestRegistry_ParseYAML(t *testing.T) {
+	// Setup
+	data := []byte(`
+models:
+  - id: gpt-4
+    provider: openai
+    context_window: 8192
+    max_output_tokens: 4096
+    capabilities: [chat, streaming]
+    pricing: {prompt_usd_per_million: 30, completion_usd_per_million: 60}
+  - id: claude-3-sonnet
+    provider: anthropic
+    aliases: [claude-3-sonnet-20240229]
+    context_window: 200000
+    deprecated_at: 2030-01-01
+`)
+
+	// Execute
+	registry, err := outbound.ParseRegistry(data)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to parse registry")
+	info, ok := registry.Get(model.OpenAI, "gpt-4")
+	utils.AssertEqual(t, true, ok, "gpt-4 should be registered")
+	utils.AssertEqual(t, 8192, info.ContextWindow, "Context window should match")
+	utils.AssertEqual(t, 60.0, info.Pricing.CompletionPerMillion, "Pricing should match")
+
+	info, ok = registry.Get(model.Anthropic, "claude-3-sonnet-20240229")
+	utils.AssertEqual(t, true, ok, "Aliases should resolve")
+	utils.AssertEqual(t, "2030-01-01", info.DeprecatedAt, "Deprecation date should match")
+}
+
+func TestRegistry_ParseRejectsInvalidDeprecationDate(t *testing.T) {
+	_, err := outbound.ParseRegistry([]byte(`{"models": [{"id": "gpt-4", "provider": "openai", "deprecated_at": "soon"}]}`))
+
+	utils.AssertError(t, err, "Invalid deprecated_at should be rejected")
+}
+
+func TestRegistry_MatchUsesLongestPrefix(t *testing.T) {
+	// Setup
+	registry, err := outbound.NewRegistry([]model.ModelInfo{
+		{ID: "gpt-4", Provider: model.OpenAI, ContextWindow: 8192},
+		{ID: "gpt-4-turbo", Provider: model.OpenAI, ContextWindow: 128000},
+	})
+	utils.AssertNoError(t, err, "Failed to build registry")
+
+	// Execute
+	versioned, versionedOK := registry.Match(model.OpenAI, "gpt-4-0613")
+	turbo, turboOK := registry.Match(model.OpenAI, "gpt-4-turbo-2024-04-09")
+	_, unknownOK := registry.Match(model.OpenAI, "davinci")
+	_, getOK := registry.Get(model.OpenAI, "gpt-4-0613")
+
+	// Assert
+	utils.AssertEqual(t, true, versionedOK && turboOK, "Versioned IDs should match their base model")
+	utils.AssertEqual(t, 8192, versioned.ContextWindow, "gpt-4-0613 should use the gpt-4 window")
+	utils.AssertEqual(t, 128000, turbo.ContextWindow, "gpt-4-turbo should win over gpt-4")
+	utils.AssertEqual(t, false, unknownOK, "Unknown models should not match")
+	utils.AssertEqual(t, false, getOK, "Get should not match by prefix")
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	// Setup
+	registry, err := outbound.NewRegistry([]model.ModelInfo{
+		{ID: "gemini-1.5-flash", Provider: model.Gemini, MaxOutputTokens: 8192},
+		{ID: "gemini-pro-vision", Provider: model.Gemini, DeprecatedAt: "2024-07-12"},
+	})
+	utils.AssertNoError(t, err, "Failed to build registry")
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Execute
+	unknownErr := registry.Validate(model.Gemini, "gemini-ultra", 0, now)
+	deprecatedErr := registry.Validate(model.Gemini, "gemini-pro-vision", 0, now)
+	maxTokensErr := registry.Validate(model.Gemini, "gemini-1.5-flash", 10000, now)
+
+	// Assert
+	utils.AssertError(t, unknownErr, "Unknown model should be rejected")
+	utils.AssertEqual(t, true, strings.Contains(unknownErr.Error(), "gemini-1.5-flash"), "Error should list valid models")
+	utils.AssertEqual(t, false, strings.Contains(unknownErr.Error(), "gemini-pro-vision"), "Deprecated models should not be listed")
+	utils.AssertError(t, deprecatedErr, "Deprecated model should be rejected")
+	utils.AssertError(t, maxTokensErr, "max_tokens above the output limit should be rejected")
+	utils.AssertNoError(t, registry.Validate(model.Gemini, "gemini-pro-vision", 0, now.AddDate(-1, 0, 0)), "Model should be valid before its deprecation date")
+}
+
+func TestLoadRegistry_ProviderConfigOverridesBuiltin(t *testing.T) {
+	// Setup
+	config, _ := json.Marshal(map[string]interface{}{
+		"default_model": "gpt-4o",
+		"models": []map[string]interface{}{
+			{"id": "gpt-4o", "context_window": 128000, "max_output_tokens": 16384},
+		},
+	})
+	store := &fakeProviderConfigStore{records: []*model.ProviderRecord{
+		{Name: "openai", Config: config},
+		{Name: "gemini", Config: json.RawMessage(`{"default_model": "gemini-1.5-flash"}`)},
+	}}
+
+	// Execute
+	registry, err := outbound.LoadRegistry(utils.TestContext(t), "", store)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to load registry")
+	utils.AssertEqual(t, "gpt-4o", strings.Join(registry.ModelIDs(model.OpenAI, time.Now()), ","), "OpenAI models should come from providers.config")
+	info, _ := registry.Get(model.OpenAI, "gpt-4o")
+	utils.AssertEqual(t, model.OpenAI, info.Provider, "Provider should come from the row name")
+	_, ok := registry.Get(model.Gemini, "gemini-1.5-pro")
+	utils.AssertEqual(t, true, ok, "Providers without models in config should keep the built-in models")
+}
+
+func TestRegistry_WithDiscoveredMergesListedModels(t *testing.T) {
+	// Setup
+	registry, err := outbound.NewRegistry([]model.ModelInfo{
+		{ID: "gpt-4", Provider: model.OpenAI, ContextWindow: 8192, MaxOutputTokens: 4096},
+		{ID: "gpt-3.5-turbo", Provider: model.OpenAI, ContextWindow: 16385},
+		{ID: "claude-3-haiku", Provider: model.Anthropic},
+	})
+	utils.AssertNoError(t, err, "Failed to build registry")
+
+	// Execute
+	merged := registry.WithDiscovered(model.OpenAI, []model.ModelInfo{
+		{ID: "gpt-4o"},
+		{ID: "gpt-4-0613"},
+		{ID: "gpt-4"},
+	})
+
+	// Assert
+	utils.AssertEqual(t, "gpt-4,gpt-4-0613,gpt-4o", strings.Join(merged.ModelIDs(model.OpenAI, time.Now()), ","), "Only listed models should remain, known ones first")
+	versioned, _ := merged.Get(model.OpenAI, "gpt-4-0613")
+	utils.AssertEqual(t, 8192, versioned.ContextWindow, "Discovered models should inherit their base model's limits")
+	utils.AssertEqual(t, model.OpenAI, versioned.Provider, "Discovered models should belong to the provider")
+	_, ok := merged.Get(model.Anthropic, "claude-3-haiku")
+	utils.AssertEqual(t, true, ok, "Other providers should be unchanged")
+	_, ok = registry.Get(model.OpenAI, "gpt-4o")
+	utils.AssertEqual(t, false, ok, "The original registry should not be modified")
+}
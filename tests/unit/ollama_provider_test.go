@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+func TestOllamaProvider_GenerateUsesGenerateEndpoint(t *testing.T) {
+	// Setup
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.AssertEqual(t, "/api/generate", r.URL.Path, "Single prompts should use /api/generate")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"model": "llama3", "response": "Hello", "done": true, "prompt_eval_count": 8, "eval_count": 2}`))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewOllamaProvider(server.URL, "llama3")
+
+	// Execute
+	resp, err := provider.Generate(utils.TestContext(t), &model.GenerationRequest{
+		Provider:  model.Ollama,
+		Prompt:    "Say hello",
+		SystemMsg: "Be brief",
+		MaxTokens: 50,
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to generate content")
+	utils.AssertEqual(t, "Hello", resp.Content, "Content should match")
+	utils.AssertEqual(t, model.Ollama, resp.Provider, "Provider should be ollama")
+	utils.AssertEqual(t, 10, resp.TotalTokens, "Usage should come from the eval counts")
+	utils.AssertEqual(t, "llama3", received["model"], "Default model should be used")
+	utils.AssertEqual(t, "Be brief", received["system"], "System message should be sent")
+	utils.AssertEqual(t, false, received["stream"], "Streaming should be off")
+	options, _ := received["options"].(map[string]interface{})
+	utils.AssertEqual(t, 50.0, options["num_predict"], "max_tokens should map to num_predict")
+}
+
+func TestOllamaProvider_StreamsChat(t *testing.T) {
+	// Setup
+	var received struct {
+		Messages []model.Message `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.AssertEqual(t, "/api/chat", r.URL.Path, "Conversations should use /api/chat")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"message": {"role": "assistant", "content": "Hel"}, "done": false}
+{"message": {"role": "assistant", "content": "lo"}, "done": false}
+{"message": {"role": "assistant", "content": ""}, "done": true, "prompt_eval_count": 12, "eval_count": 2}
+`))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewOllamaProvider(server.URL, "llama3")
+	var chunks []string
+
+	// Execute
+	resp, err := provider.GenerateStream(utils.TestContext(t), &model.GenerationRequest{
+		Provider: model.Ollama,
+		Prompt:   "And again?",
+		Messages: []model.Message{
+			{Role: model.RoleUser, Content: "Say hello"},
+			{Role: model.RoleAssistant, Content: "Hello"},
+		},
+	}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to stream content")
+	utils.AssertEqual(t, "Hel|lo", strings.Join(chunks, "|"), "Chunks should be delivered in order")
+	utils.AssertEqual(t, "Hello", resp.Content, "Content should be assembled")
+	utils.AssertEqual(t, 14, resp.TotalTokens, "Usage should come from the final line")
+	utils.AssertEqual(t, 3, len(received.Messages), "Prior turns and the prompt should be sent")
+}
+
+func TestManager_DiscoversOllamaModels(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.AssertEqual(t, "/api/tags", r.URL.Path, "Models should be listed from /api/tags")
+		w.Write([]byte(`{"models": [{"name": "llama3:latest"}, {"name": "mistral:7b"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Ollama.BaseURL = server.URL
+	manager := outbound.NewManager(cfg)
+
+	// Execute
+	registry := manager.ModelCatalog(utils.TestContext(t))
+
+	// Assert
+	_, ok := registry.Get(model.Ollama, "mistral:7b")
+	utils.AssertEqual(t, true, ok, "Pulled models should be registered")
+	info, ok := registry.Get(model.Ollama, "llama3")
+	utils.AssertEqual(t, true, ok, "Untagged names should resolve to :latest")
+	utils.AssertEqual(t, "llama3:latest", info.ID, "ID should keep the tag")
+}
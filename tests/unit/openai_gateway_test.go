@@ -0,0 +1,238 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/controller"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/repository"
+	"ai-service/tests/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordingGenerationRepo keeps created generations in memory
+type recordingGenerationRepo struct {
+	repository.GenerationRepository
+	created []*model.GenerationHistory
+}
+
+func (r *recordingGenerationRepo) Create(ctx context.Context, generation *model.GenerationHistory) error {
+	r.created = append(r.created, generation)
+	return nil
+}
+
+// newGatewayRouter serves the OpenAI gateway in front of a mock Anthropic API
+func newGatewayRouter(t *testing.T, anthropic http.HandlerFunc) (*gin.Engine, *recordingGenerationRepo) {
+	server := httptest.NewServer(anthropic)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = server.URL
+
+	repo := &recordingGenerationRepo{}
+	gateway := controller.NewOpenAIController(outbound.NewManager(cfg), repo, nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/chat/completions", gateway.ChatCompletions)
+	router.GET("/v1/models", gateway.ListModels)
+	return router, repo
+}
+
+func postChatCompletion(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestOpenAIGateway_ChatCompletionRoutesByModel(t *testing.T) {
+	// Setup
+	var received struct {
+		Model    string          `json:"model"`
+		System   string          `json:"system"`
+		Messages []model.Message `json:"messages"`
+	}
+	router, repo := newGatewayRouter(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Paris"}], "usage": {"input_tokens": 12, "output_tokens": 3}}`))
+	})
+
+	// Execute
+	recorder := postChatCompletion(router, `{
+		"model": "claude-3-haiku",
+		"messages": [
+			{"role": "system", "content": "Be brief"},
+			{"role": "user", "content": "Hi"},
+			{"role": "assistant", "content": "Hello"},
+			{"role": "user", "content": [{"type": "text", "text": "Capital of France?"}]}
+		]
+	}`)
+
+	// Assert
+	utils.AssertEqual(t, 200, recorder.Code, "Request should succeed")
+	var resp struct {
+		Object  string `json:"object"`
+		Choices []struct {
+			Message struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	utils.AssertNoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp), "Response should be JSON")
+	utils.AssertEqual(t, "chat.completion", resp.Object, "Object should match")
+	utils.AssertEqual(t, "Paris", resp.Choices[0].Message.Content, "Content should match")
+	utils.AssertEqual(t, "assistant", resp.Choices[0].Message.Role, "Role should be assistant")
+	utils.AssertEqual(t, 15, resp.Usage.TotalTokens, "Usage should match")
+
+	utils.AssertEqual(t, "Be brief", received.System, "System message should be forwarded")
+	utils.AssertEqual(t, 3, len(received.Messages), "Prior turns and the prompt should be forwarded")
+	utils.AssertEqual(t, "Capital of France?", received.Messages[2].Content, "Last user message should be the prompt")
+
+	utils.AssertEqual(t, 1, len(repo.created), "Generation should be saved")
+	utils.AssertEqual(t, "anthropic", repo.created[0].Provider, "History should record the provider")
+}
+
+func TestOpenAIGateway_StreamsChunks(t *testing.T) {
+	// Setup
+	router, repo := newGatewayRouter(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: message_start\ndata: {\"type\": \"message_start\", \"message\": {\"usage\": {\"input_tokens\": 5}}}\n\n" +
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"delta\": {\"type\": \"text_delta\", \"text\": \"Hel\"}}\n\n" +
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"delta\": {\"type\": \"text_delta\", \"text\": \"lo\"}}\n\n" +
+			"event: message_delta\ndata: {\"type\": \"message_delta\", \"usage\": {\"output_tokens\": 2}}\n\n" +
+			"event: message_stop\ndata: {\"type\": \"message_stop\"}\n\n"))
+	})
+
+	// Execute
+	recorder := postChatCompletion(router, `{
+		"model": "anthropic/claude-3-haiku",
+		"stream": true,
+		"stream_options": {"include_usage": true},
+		"messages": [{"role": "user", "content": "Say hello"}]
+	}`)
+
+	// Assert
+	utils.AssertEqual(t, 200, recorder.Code, "Stream should succeed")
+	body := recorder.Body.String()
+	utils.AssertEqual(t, false, strings.Contains(body, "event:"), "Chunks should be plain data events")
+	utils.AssertEqual(t, true, strings.Contains(body, `"content":"Hel"`), "First chunk should be sent")
+	utils.AssertEqual(t, true, strings.Contains(body, `"finish_reason":"stop"`), "Final chunk should carry the finish reason")
+	utils.AssertEqual(t, true, strings.Contains(body, `"total_tokens":7`), "Usage chunk should be sent")
+	utils.AssertEqual(t, true, strings.HasSuffix(body, "data: [DONE]\n\n"), "Stream should end with [DONE]")
+	utils.AssertEqual(t, "Hello", repo.created[0].Response, "Assembled response should be saved")
+}
+
+func TestOpenAIGateway_ReportsTruncationAsLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected string
+	}{
+		{"provider stop reason", `{"content": [{"type": "text", "text": "Par"}], "stop_reason": "max_tokens", "usage": {"input_tokens": 12, "output_tokens": 2}}`, "length"},
+		{"completion reaches max_tokens", `{"content": [{"type": "text", "text": "Par"}], "usage": {"input_tokens": 12, "output_tokens": 5}}`, "length"},
+		{"natural end", `{"content": [{"type": "text", "text": "Paris"}], "stop_reason": "end_turn", "usage": {"input_tokens": 12, "output_tokens": 2}}`, "stop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			router, _ := newGatewayRouter(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.response))
+			})
+
+			// Execute
+			recorder := postChatCompletion(router, `{
+				"model": "claude-3-haiku",
+				"max_tokens": 5,
+				"messages": [{"role": "user", "content": "Capital of France?"}]
+			}`)
+
+			// Assert
+			utils.AssertEqual(t, 200, recorder.Code, "Request should succeed")
+			var resp struct {
+				Choices []struct {
+					FinishReason string `json:"finish_reason"`
+				} `json:"choices"`
+			}
+			utils.AssertNoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp), "Response should be JSON")
+			utils.AssertEqual(t, tt.expected, resp.Choices[0].FinishReason, "Finish reason should match")
+		})
+	}
+}
+
+func TestOpenAIGateway_StreamReportsTruncationAsLength(t *testing.T) {
+	// Setup
+	router, _ := newGatewayRouter(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: message_start\ndata: {\"type\": \"message_start\", \"message\": {\"usage\": {\"input_tokens\": 5}}}\n\n" +
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"delta\": {\"type\": \"text_delta\", \"text\": \"Hel\"}}\n\n" +
+			"event: message_delta\ndata: {\"type\": \"message_delta\", \"delta\": {\"stop_reason\": \"max_tokens\"}, \"usage\": {\"output_tokens\": 1}}\n\n" +
+			"event: message_stop\ndata: {\"type\": \"message_stop\"}\n\n"))
+	})
+
+	// Execute
+	recorder := postChatCompletion(router, `{
+		"model": "claude-3-haiku",
+		"stream": true,
+		"max_tokens": 1,
+		"messages": [{"role": "user", "content": "Say hello"}]
+	}`)
+
+	// Assert
+	utils.AssertEqual(t, 200, recorder.Code, "Stream should succeed")
+	utils.AssertEqual(t, true, strings.Contains(recorder.Body.String(), `"finish_reason":"length"`), "Final chunk should report truncation")
+}
+
+func TestOpenAIGateway_RejectsUnknownModel(t *testing.T) {
+	// Setup
+	router, _ := newGatewayRouter(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Provider should not be called")
+	})
+
+	// Execute
+	recorder := postChatCompletion(router, `{"model": "no-such-model", "messages": [{"role": "user", "content": "Hi"}]}`)
+
+	// Assert
+	utils.AssertEqual(t, 404, recorder.Code, "Unknown model should be rejected")
+	utils.AssertEqual(t, true, strings.Contains(recorder.Body.String(), `"code":"model_not_found"`), "Error should use the OpenAI schema")
+}
+
+func TestOpenAIGateway_ListModelsOnlyConfiguredProviders(t *testing.T) {
+	// Setup
+	router, _ := newGatewayRouter(t, func(w http.ResponseWriter, r *http.Request) {})
+	recorder := httptest.NewRecorder()
+
+	// Execute
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+
+	// Assert
+	var resp struct {
+		Data []struct {
+			ID      string `json:"id"`
+			OwnedBy string `json:"owned_by"`
+		} `json:"data"`
+	}
+	utils.AssertNoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp), "Response should be JSON")
+	utils.AssertEqual(t, true, len(resp.Data) > 0, "Anthropic models should be listed")
+	for _, entry := range resp.Data {
+		utils.AssertEqual(t, "anthropic", entry.OwnedBy, "Only configured providers should be listed")
+	}
+}
@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+// openAICompletion is a minimal chat completions response
+const openAICompletion = `{
+	"choices": [{"message": {"role": "assistant", "content": "Hello"}}],
+	"usage": {"prompt_tokens": 7, "completion_tokens": 2, "total_tokens": 9}
+}`
+
+func TestOpenAIProvider_UsesBaseURL(t *testing.T) {
+	// Setup
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.AssertEqual(t, "/v1/chat/completions", r.URL.Path, "Request should go to the base URL")
+		utils.AssertEqual(t, "Bearer test-openai-key", r.Header.Get("Authorization"), "Bearer token should be set")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(openAICompletion))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewOpenAIProvider("test-openai-key", server.URL+"/v1/")
+
+	// Execute
+	resp, err := provider.Generate(utils.TestContext(t), &model.GenerationRequest{
+		Provider: model.OpenAI,
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to generate content")
+	utils.AssertEqual(t, "Hello", resp.Content, "Content should match")
+	utils.AssertEqual(t, model.OpenAI, resp.Provider, "Provider should be openai")
+	utils.AssertEqual(t, 9, resp.TotalTokens, "Usage should match")
+	utils.AssertEqual(t, "gpt-3.5-turbo", received["model"], "Default model should be used")
+}
+
+func TestOpenAIProvider_AzureDeployment(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.AssertEqual(t, "/openai/deployments/gpt4-prod/chat/completions", r.URL.Path, "Model should be the deployment")
+		utils.AssertEqual(t, "2024-06-01", r.URL.Query().Get("api-version"), "API version should be set")
+		utils.AssertEqual(t, "test-azure-key", r.Header.Get("api-key"), "Azure key header should be set")
+		utils.AssertEqual(t, "", r.Header.Get("Authorization"), "Bearer token should not be sent")
+		w.Write([]byte(openAICompletion))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewOpenAICompatibleProvider(outbound.OpenAIOptions{
+		Provider:        model.OpenAI,
+		Name:            "Azure OpenAI",
+		APIKey:          "test-azure-key",
+		BaseURL:         server.URL,
+		AzureAPIVersion: "2024-06-01",
+	})
+
+	// Execute
+	_, err := provider.Generate(utils.TestContext(t), &model.GenerationRequest{
+		Provider: model.OpenAI,
+		Model:    "gpt4-prod",
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to generate content")
+}
+
+func TestManager_RegistersOpenAICompatibleBackend(t *testing.T) {
+	// Setup
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.AssertEqual(t, "", r.Header.Get("Authorization"), "Keyless backends should get no auth header")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(openAICompletion))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.AIProviders.OpenAICompatible = []config.OpenAICompatibleConfig{{
+		Name:         "vllm",
+		DisplayName:  "Local vLLM",
+		BaseURL:      server.URL,
+		DefaultModel: "llama-3-8b",
+		Models:       []model.ModelInfo{{ID: "llama-3-8b", ContextWindow: 8192}},
+	}}
+	manager := outbound.NewManager(cfg)
+
+	// Execute
+	resp, err := manager.Generate(utils.TestContext(t), &model.GenerationRequest{
+		Provider: "vllm",
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to generate content")
+	utils.AssertEqual(t, model.AIProvider("vllm"), resp.Provider, "Response should name the backend")
+	utils.AssertEqual(t, "llama-3-8b", received["model"], "Backend default model should be used")
+	utils.AssertEqual(t, "llama-3-8b", manager.DefaultModel("vllm"), "Default model should come from the backend config")
+	info, ok := manager.Registry().Get("vllm", "llama-3-8b")
+	utils.AssertEqual(t, true, ok, "Configured models should be registered")
+	utils.AssertEqual(t, 8192, info.ContextWindow, "Configured limits should be kept")
+}
+
+func TestConfig_RejectsDuplicateBackendName(t *testing.T) {
+	// Setup
+	cfg := &config.Config{}
+	cfg.Server.Port = "8080"
+	cfg.Database.Driver = "postgres"
+	cfg.Preflight.Mode = config.PreflightReject
+	cfg.AIProviders.OpenAICompatible = []config.OpenAICompatibleConfig{{Name: "anthropic", BaseURL: "http://localhost:8000/v1"}}
+
+	// Execute
+	err := cfg.Validate()
+
+	// Assert
+	utils.AssertError(t, err, "Backends should not reuse a built-in provider ID")
+	utils.AssertEqual(t, true, strings.Contains(err.Error(), "anthropic"), "Error should name the backend")
+}
+
+func TestConfig_LoadRejectsMalformedJSONSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"OpenAI-compatible backends", "OPENAI_COMPATIBLE_BACKENDS"},
+		{"failover model map", "FAILOVER_MODEL_MAP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			t.Setenv(tt.key, `[{"name": "vllm",`)
+
+			// Execute
+			_, err := config.Load()
+
+			// Assert
+			utils.AssertError(t, err, "Malformed JSON should fail to load")
+			utils.AssertEqual(t, true, strings.Contains(err.Error(), tt.key), "Error should name the variable")
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"ai-service/internal/model"
+	"ai-service/internal/service"
+	"ai-service/tests/utils"
+)
+
+func testPrices() []*model.ModelPrice {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	return []*model.ModelPrice{
+		{Provider: "openai", Model: "gpt-4", PromptPerMillion: 30, CompletionPerMillion: 60, EffectiveFrom: jan},
+		{Provider: "openai", Model: "gpt-4-turbo", PromptPerMillion: 10, CompletionPerMillion: 30, EffectiveFrom: jan},
+		{Provider: "anthropic", Model: "claude-3-sonnet", PromptPerMillion: 3, CompletionPerMillion: 15, EffectiveFrom: jan},
+		{Provider: "anthropic", Model: "claude-3-sonnet", PromptPerMillion: 2, CompletionPerMillion: 10, EffectiveFrom: jun},
+	}
+}
+
+func TestModelPrice_Cost(t *testing.T) {
+	// Setup
+	price := &model.ModelPrice{PromptPerMillion: 3, CompletionPerMillion: 15}
+
+	// Execute & Assert
+	utils.AssertEqual(t, 0.018, price.Cost(1000, 1000), "Cost should apply each rate to its tokens")
+	utils.AssertEqual(t, 0.018, price.BlendedCost(2000), "Blended cost should use the mean rate")
+}
+
+func TestFindPrice_MatchesLongestPrefix(t *testing.T) {
+	// Setup
+	prices := testPrices()
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Execute
+	turbo := service.FindPrice(prices, "openai", "gpt-4-turbo-2024-04-09", now)
+	dated := service.FindPrice(prices, "anthropic", "claude-3-sonnet-20240229", now)
+	unknown := service.FindPrice(prices, "openai", "gpt-3.5-turbo", now)
+
+	// Assert
+	utils.AssertEqual(t, "gpt-4-turbo", turbo.Model, "Longest matching entry should win")
+	utils.AssertEqual(t, "claude-3-sonnet", dated.Model, "Dated model IDs should match their family")
+	utils.AssertEqual(t, true, unknown == nil, "Models without a price should have none")
+}
+
+func TestFindPrice_UsesVersionInEffect(t *testing.T) {
+	// Setup
+	prices := testPrices()
+
+	// Execute
+	before := service.FindPrice(prices, "anthropic", "claude-3-sonnet", time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC))
+	after := service.FindPrice(prices, "anthropic", "claude-3-sonnet", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC))
+	tooEarly := service.FindPrice(prices, "anthropic", "claude-3-sonnet", time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC))
+
+	// Assert
+	utils.AssertEqual(t, 3.0, before.PromptPerMillion, "Older version should apply before the new one starts")
+	utils.AssertEqual(t, 2.0, after.PromptPerMillion, "Newer version should apply once in effect")
+	utils.AssertEqual(t, true, tooEarly == nil, "No price applies before the first version")
+}
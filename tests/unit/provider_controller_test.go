@@ -0,0 +1,320 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/controller"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/repository"
+	"ai-service/internal/service"
+	"ai-service/internal/util/encryption"
+	"ai-service/internal/util/exceptioncode"
+	"ai-service/tests/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryProviderRepo keeps provider rows, stored keys and key audit in memory
+type memoryProviderRepo struct {
+	repository.AIProviderRepository
+	records map[string]*model.ProviderRecord
+	keys    map[string]*model.ProviderKey
+	audit   []*model.ProviderKeyAudit
+}
+
+func newMemoryProviderRepo() *memoryProviderRepo {
+	return &memoryProviderRepo{
+		records: map[string]*model.ProviderRecord{},
+		keys:    map[string]*model.ProviderKey{},
+	}
+}
+
+func (r *memoryProviderRepo) List(ctx context.Context) ([]*model.ProviderRecord, error) {
+	records := make([]*model.ProviderRecord, 0, len(r.records))
+	for _, record := range r.records {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (r *memoryProviderRepo) row(name string) *model.ProviderRecord {
+	if r.records[name] == nil {
+		r.records[name] = &model.ProviderRecord{Name: name, IsActive: true}
+	}
+	return r.records[name]
+}
+
+func (r *memoryProviderRepo) SetActive(ctx context.Context, name string, active bool) (*model.ProviderRecord, error) {
+	record := r.row(name)
+	record.IsActive = active
+	return record, nil
+}
+
+func (r *memoryProviderRepo) UpdateConfig(ctx context.Context, name string, patch json.RawMessage) (*model.ProviderRecord, error) {
+	record := r.row(name)
+	config := map[string]interface{}{}
+	if len(record.Config) > 0 {
+		json.Unmarshal(record.Config, &config)
+	}
+	var values map[string]interface{}
+	json.Unmarshal(patch, &values)
+	for key, value := range values {
+		if value == nil {
+			delete(config, key)
+			continue
+		}
+		config[key] = value
+	}
+	record.Config, _ = json.Marshal(config)
+	return record, nil
+}
+
+func (r *memoryProviderRepo) GetByName(ctx context.Context, name string) (*model.ProviderRecord, error) {
+	if record, ok := r.records[name]; ok {
+		return record, nil
+	}
+	return nil, exceptioncode.ErrEmptyResult
+}
+
+func (r *memoryProviderRepo) ListKeys(ctx context.Context) ([]*model.ProviderKey, error) {
+	keys := make([]*model.ProviderKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (r *memoryProviderRepo) GetKey(ctx context.Context, name string) (*model.ProviderKey, error) {
+	if key, ok := r.keys[name]; ok {
+		return key, nil
+	}
+	return nil, exceptioncode.ErrEmptyResult
+}
+
+func (r *memoryProviderRepo) SaveKey(ctx context.Context, key *model.ProviderKey, audit *model.ProviderKeyAudit) error {
+	r.keys[key.Provider] = key
+	r.row(key.Provider).HasAPIKey = true
+	r.audit = append(r.audit, audit)
+	return nil
+}
+
+func (r *memoryProviderRepo) DeleteKey(ctx context.Context, name string, audit *model.ProviderKeyAudit) error {
+	if _, ok := r.keys[name]; !ok {
+		return exceptioncode.ErrEmptyResult
+	}
+	delete(r.keys, name)
+	r.row(name).HasAPIKey = false
+	r.audit = append(r.audit, audit)
+	return nil
+}
+
+func (r *memoryProviderRepo) ListKeyAudit(ctx context.Context, name string, limit int) ([]*model.ProviderKeyAudit, error) {
+	entries := []*model.ProviderKeyAudit{}
+	for i := len(r.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		if r.audit[i].Provider == name {
+			entries = append(entries, r.audit[i])
+		}
+	}
+	return entries, nil
+}
+
+// newProviderAdminRouter serves the provider admin endpoints for a manager
+// with Anthropic configured against a stub API
+func newProviderAdminRouter(t *testing.T) (*gin.Engine, *outbound.Manager) {
+	router, manager, _ := newProviderKeyRouter(t, newAnthropicServer(t, "Hello"))
+	return router, manager
+}
+
+// newProviderKeyRouter serves the provider admin endpoints, storing keys with
+// a random master key, for a manager with Anthropic configured against server
+func newProviderKeyRouter(t *testing.T, server *httptest.Server) (*gin.Engine, *outbound.Manager, *memoryProviderRepo) {
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = server.URL
+
+	masterKey := make([]byte, encryption.KeySize)
+	rand.Read(masterKey)
+	cipher, err := encryption.NewCipher(masterKey)
+	utils.AssertNoError(t, err, "Failed to create cipher")
+
+	repo := newMemoryProviderRepo()
+	keyService := service.NewProviderKeyService(repo, cipher)
+	manager := outbound.NewManager(cfg)
+	manager.SetProviderStore(repo)
+	manager.SetKeySource(keyService)
+	providers := controller.NewProviderController(manager, repo, keyService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/admin/providers", providers.ListProviders)
+	router.POST("/api/admin/providers/:name/enable", providers.EnableProvider)
+	router.POST("/api/admin/providers/:name/disable", providers.DisableProvider)
+	router.PATCH("/api/admin/providers/:name/config", providers.UpdateProviderConfig)
+	router.PUT("/api/admin/providers/:name/key", providers.SetProviderKey)
+	router.POST("/api/admin/providers/:name/key/rotate", providers.RotateProviderKey)
+	router.DELETE("/api/admin/providers/:name/key", providers.DeleteProviderKey)
+	router.GET("/api/admin/providers/:name/key/audit", providers.GetProviderKeyAudit)
+	return router, manager, repo
+}
+
+func serveProviderAdmin(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestProviderController_DisableAndEnableProvider(t *testing.T) {
+	// Setup
+	router, manager := newProviderAdminRouter(t)
+	ctx := utils.TestContext(t)
+	req := &model.GenerationRequest{Provider: model.Anthropic, Model: "claude-3-haiku", Prompt: "Hi"}
+
+	// Execute
+	disabled := serveProviderAdmin(router, http.MethodPost, "/api/admin/providers/anthropic/disable", "")
+	_, disabledErr := manager.Generate(ctx, req)
+	enabled := serveProviderAdmin(router, http.MethodPost, "/api/admin/providers/anthropic/enable", "")
+	resp, enabledErr := manager.Generate(ctx, req)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusOK, disabled.Code, "Disable should succeed")
+	utils.AssertError(t, disabledErr, "Disabled provider should refuse requests")
+	utils.AssertEqual(t, true, strings.Contains(disabledErr.Error(), "disabled"), "Error should say the provider is disabled")
+	utils.AssertEqual(t, http.StatusOK, enabled.Code, "Enable should succeed")
+	utils.AssertNoError(t, enabledErr, "Enabled provider should serve requests")
+	utils.AssertEqual(t, "Hello", resp.Content, "Response should come from the provider")
+
+	list := serveProviderAdmin(router, http.MethodGet, "/api/admin/providers", "")
+	utils.AssertEqual(t, http.StatusOK, list.Code, "List should succeed")
+	utils.AssertEqual(t, true, strings.Contains(list.Body.String(), `"config_schema"`), "List should include each provider's schema")
+}
+
+func TestProviderController_UpdateConfigEnforcesMaxTokens(t *testing.T) {
+	// Setup
+	router, manager := newProviderAdminRouter(t)
+
+	// Execute
+	recorder := serveProviderAdmin(router, http.MethodPatch, "/api/admin/providers/anthropic/config", `{"max_tokens": 100, "timeout": "30s"}`)
+	_, err := manager.Generate(utils.TestContext(t), &model.GenerationRequest{
+		Provider:  model.Anthropic,
+		Model:     "claude-3-haiku",
+		Prompt:    "Hi",
+		MaxTokens: 500,
+	})
+
+	// Assert
+	utils.AssertEqual(t, http.StatusOK, recorder.Code, "Config update should succeed")
+	utils.AssertError(t, err, "Requests over the max_tokens setting should be rejected")
+	utils.AssertEqual(t, true, strings.Contains(err.Error(), "exceeds the limit of 100"), "Error should name the limit")
+}
+
+func TestProviderController_UpdateConfigRejectsInvalidSettings(t *testing.T) {
+	// Setup
+	router, _ := newProviderAdminRouter(t)
+
+	cases := map[string]string{
+		"secret":        `{"api_key": "sk-new-key"}`,
+		"unknown":       `{"temperature": 0.5}`,
+		"timeout":       `{"timeout": "soon"}`,
+		"max tokens":    `{"max_tokens": -1}`,
+		"models format": `{"models": [{"name": "missing id"}]}`,
+	}
+
+	for name, body := range cases {
+		// Execute
+		recorder := serveProviderAdmin(router, http.MethodPatch, "/api/admin/providers/anthropic/config", body)
+
+		// Assert
+		utils.AssertEqual(t, http.StatusBadRequest, recorder.Code, name+" should be rejected")
+	}
+
+	recorder := serveProviderAdmin(router, http.MethodPatch, "/api/admin/providers/unknown/config", `{"timeout": "30s"}`)
+	utils.AssertEqual(t, http.StatusNotFound, recorder.Code, "Unregistered providers should not be found")
+}
+
+func TestProviderController_StoredKeyOverridesEnvironment(t *testing.T) {
+	// Setup
+	var usedKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usedKeys = append(usedKeys, r.Header.Get("x-api-key"))
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Hello"}], "usage": {"input_tokens": 1, "output_tokens": 1}}`))
+	}))
+	t.Cleanup(server.Close)
+	router, manager, repo := newProviderKeyRouter(t, server)
+	ctx := utils.TestContext(t)
+	req := &model.GenerationRequest{Provider: model.Anthropic, Model: "claude-3-haiku", Prompt: "Hi"}
+
+	// Execute
+	stored := serveProviderAdmin(router, http.MethodPut, "/api/admin/providers/anthropic/key", `{"api_key": "sk-ant-stored-key"}`)
+	_, storedErr := manager.Generate(ctx, req)
+	rotated := serveProviderAdmin(router, http.MethodPost, "/api/admin/providers/anthropic/key/rotate", "")
+	_, rotatedErr := manager.Generate(ctx, req)
+	deleted := serveProviderAdmin(router, http.MethodDelete, "/api/admin/providers/anthropic/key", "")
+	_, deletedErr := manager.Generate(ctx, req)
+	audit := serveProviderAdmin(router, http.MethodGet, "/api/admin/providers/anthropic/key/audit", "")
+
+	// Assert
+	utils.AssertEqual(t, http.StatusOK, stored.Code, "Storing a key should succeed")
+	utils.AssertEqual(t, http.StatusOK, rotated.Code, "Rotating a key should succeed")
+	utils.AssertEqual(t, http.StatusOK, deleted.Code, "Deleting a key should succeed")
+	utils.AssertNoError(t, storedErr, "Generate should work with the stored key")
+	utils.AssertNoError(t, rotatedErr, "Generate should work after rotation")
+	utils.AssertNoError(t, deletedErr, "Generate should work with the environment key")
+	utils.AssertEqual(t, "sk-ant-stored-key,sk-ant-stored-key,test-anthropic-key", strings.Join(usedKeys, ","), "Stored key should override the environment until deleted")
+
+	for _, recorder := range []*httptest.ResponseRecorder{stored, rotated, deleted, audit} {
+		utils.AssertEqual(t, false, strings.Contains(recorder.Body.String(), "sk-ant-stored-key"), "Responses should never include the key")
+	}
+	var body struct {
+		Audit []model.ProviderKeyAudit `json:"audit"`
+	}
+	utils.AssertNoError(t, json.Unmarshal(audit.Body.Bytes(), &body), "Audit should be JSON")
+	utils.AssertEqual(t, 3, len(body.Audit), "Every change should be audited")
+	utils.AssertEqual(t, model.ProviderKeyDeleted, body.Audit[0].Action, "Audit should be newest first")
+	utils.AssertEqual(t, "anonymous", body.Audit[0].Actor, "Audit should record the caller")
+	utils.AssertEqual(t, model.ProviderKeySet, body.Audit[2].Action, "First change should be the key being set")
+	utils.AssertEqual(t, 0, len(repo.keys), "Deleted key should not be stored")
+}
+
+func TestProviderController_StoresKeyEncrypted(t *testing.T) {
+	// Setup
+	router, _, repo := newProviderKeyRouter(t, newAnthropicServer(t, "Hello"))
+
+	// Execute
+	recorder := serveProviderAdmin(router, http.MethodPut, "/api/admin/providers/anthropic/key", `{"api_key": "sk-ant-stored-key"}`)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusOK, recorder.Code, "Storing a key should succeed")
+	key := repo.keys["anthropic"]
+	utils.AssertEqual(t, true, key != nil, "Key should be stored")
+	utils.AssertEqual(t, false, bytes.Contains(key.EncryptedAPIKey, []byte("sk-ant-stored-key")), "Key should be encrypted at rest")
+	utils.AssertEqual(t, true, len(key.EncryptedDataKey) > 0, "Data key should be stored wrapped")
+
+	recorder = serveProviderAdmin(router, http.MethodPost, "/api/admin/providers/gemini/key/rotate", "")
+	utils.AssertEqual(t, http.StatusNotFound, recorder.Code, "Rotating a missing key should not be found")
+}
+
+func TestProviderController_KeyStorageRequiresMasterKey(t *testing.T) {
+	// Setup
+	repo := newMemoryProviderRepo()
+	providers := controller.NewProviderController(outbound.NewManager(&config.Config{}), repo, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/api/admin/providers/:name/key", providers.SetProviderKey)
+
+	// Execute
+	recorder := serveProviderAdmin(router, http.MethodPut, "/api/admin/providers/openai/key", `{"api_key": "sk-stored-key"}`)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusServiceUnavailable, recorder.Code, "Keys cannot be stored without a master key")
+}
@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+const echoProviderID model.AIProvider = "test-echo"
+
+var registerEchoProvider sync.Once
+
+// echoProvider answers every prompt with the prompt and its greeting setting
+type echoProvider struct {
+	greeting string
+}
+
+func (p *echoProvider) Generate(ctx context.Context, req *model.GenerationRequest) (*model.GenerationResponse, error) {
+	return &model.GenerationResponse{
+		Provider: echoProviderID,
+		Model:    req.Model,
+		Content:  fmt.Sprintf("%s %s", p.greeting, req.Prompt),
+	}, nil
+}
+
+func (p *echoProvider) GetName() string                                    { return "Echo" }
+func (p *echoProvider) IsAvailable() bool                                  { return true }
+func (p *echoProvider) ValidateRequest(req *model.GenerationRequest) error { return nil }
+
+// useEchoProvider registers a provider configured only through its schema's
+// environment variables
+func useEchoProvider() {
+	registerEchoProvider.Do(func() {
+		outbound.RegisterProvider(outbound.ProviderDefinition{
+			ID:          echoProviderID,
+			Name:        "Echo",
+			Description: "Repeats the prompt",
+			ConfigSchema: []outbound.ConfigField{
+				{Name: "greeting", Env: "TEST_ECHO_GREETING", Required: true},
+				{Name: outbound.SettingDefaultModel, Env: "TEST_ECHO_DEFAULT_MODEL", Default: "echo-1"},
+			},
+			New: func(settings outbound.ProviderSettings, retryPolicy outbound.RetryPolicy) (outbound.Provider, error) {
+				return &echoProvider{greeting: settings["greeting"]}, nil
+			},
+		})
+	})
+}
+
+func TestManager_BuildsRegisteredProvider(t *testing.T) {
+	// Setup
+	useEchoProvider()
+	t.Setenv("TEST_ECHO_GREETING", "Hello")
+	manager := outbound.NewManager(&config.Config{})
+
+	// Execute
+	resp, err := manager.Generate(utils.TestContext(t), &model.GenerationRequest{
+		Provider: echoProviderID,
+		Prompt:   "world",
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Registered provider should be built from its environment")
+	utils.AssertEqual(t, "Hello world", resp.Content, "Settings should reach the factory")
+	utils.AssertEqual(t, "echo-1", manager.DefaultModel(echoProviderID), "Unset settings should use the schema default")
+	definition, ok := manager.ProviderDefinition(echoProviderID)
+	utils.AssertEqual(t, true, ok, "Definition should be listed")
+	utils.AssertEqual(t, "Echo", definition.Name, "Display name should match")
+	utils.AssertEqual(t, true, manager.GetAvailableProviders()[string(echoProviderID)].Available, "Comparison should report the provider available")
+}
+
+func TestManager_SkipsUnconfiguredProvider(t *testing.T) {
+	// Setup
+	useEchoProvider()
+	t.Setenv("TEST_ECHO_GREETING", "")
+	cfg := &config.Config{}
+	cfg.AIProviders.Gemini.APIKey = "your_gemini_api_key_here"
+
+	// Execute
+	manager := outbound.NewManager(cfg)
+
+	// Assert
+	_, err := manager.GetProvider(echoProviderID)
+	utils.AssertError(t, err, "Provider missing a required setting should not be built")
+	_, err = manager.GetProvider(model.Gemini)
+	utils.AssertError(t, err, "Placeholder secrets should not count as configured")
+	_, ok := manager.ProviderDefinition(echoProviderID)
+	utils.AssertEqual(t, true, ok, "Unconfigured providers should still be listed")
+}
+
+func TestManager_ListsOpenAICompatibleBackends(t *testing.T) {
+	// Setup
+	cfg := &config.Config{}
+	cfg.AIProviders.OpenAICompatible = []config.OpenAICompatibleConfig{
+		{Name: "vllm", DisplayName: "vLLM", BaseURL: "http://localhost:8000/v1", DefaultModel: "llama-3-8b"},
+	}
+
+	// Execute
+	manager := outbound.NewManager(cfg)
+
+	// Assert
+	definition, ok := manager.ProviderDefinition("vllm")
+	utils.AssertEqual(t, true, ok, "Backend should be listed as a provider")
+	utils.AssertEqual(t, "vLLM", definition.Name, "Display name should come from the backend")
+	utils.AssertEqual(t, "llama-3-8b", manager.DefaultModel("vllm"), "Default model should come from the backend")
+	_, err := manager.GetProvider("vllm")
+	utils.AssertNoError(t, err, "Keyless backend should be built")
+}
+
+func TestRegisterProvider_RejectsDuplicateID(t *testing.T) {
+	defer func() {
+		utils.AssertEqual(t, true, recover() != nil, "Registering a built-in ID again should panic")
+	}()
+
+	outbound.RegisterProvider(outbound.ProviderDefinition{
+		ID: model.OpenAI,
+		New: func(settings outbound.ProviderSettings, retryPolicy outbound.RetryPolicy) (outbound.Provider, error) {
+			return nil, nil
+		},
+	})
+}
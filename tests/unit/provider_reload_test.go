@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+// newAnthropicServer returns an Anthropic API stub answering with text
+func newAnthropicServer(t *testing.T, text string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": text}},
+			"usage":   map[string]int{"input_tokens": 1, "output_tokens": 1},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestManager_ReloadSwapsProviderFromConfigFile(t *testing.T) {
+	// Setup
+	before := newAnthropicServer(t, "before")
+	after := newAnthropicServer(t, "after")
+
+	file := filepath.Join(t.TempDir(), "providers.yaml")
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = before.URL
+	cfg.AIProviders.ProviderConfigFile = file
+	utils.AssertNoError(t, os.WriteFile(file, []byte("{}"), 0o600), "Failed to write provider config")
+
+	manager := outbound.NewManager(cfg)
+	ctx := utils.TestContext(t)
+	inFlight, err := manager.GetProvider(model.Anthropic)
+	utils.AssertNoError(t, err, "Anthropic should be configured")
+
+	utils.AssertNoError(t, os.WriteFile(file, []byte("anthropic:\n  base_url: "+after.URL+"\n"), 0o600), "Failed to write provider config")
+
+	// Execute
+	reload, err := manager.Reload(ctx)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to reload")
+	utils.AssertEqual(t, 1, len(reload.Changes), "Only Anthropic should change")
+	utils.AssertEqual(t, outbound.ProviderUpdated, reload.Changes[0].Change, "Anthropic should be updated")
+	utils.AssertEqual(t, "base_url", strings.Join(reload.Changes[0].Settings, ","), "Changed settings should be named")
+
+	req := &model.GenerationRequest{Provider: model.Anthropic, Model: "claude-3-haiku", Prompt: "Hi"}
+	resp, err := manager.Generate(ctx, req)
+	utils.AssertNoError(t, err, "Failed to generate after reload")
+	utils.AssertEqual(t, "after", resp.Content, "New requests should use the reloaded provider")
+
+	resp, err = inFlight.Generate(ctx, req)
+	utils.AssertNoError(t, err, "Instance held by a running request should keep working")
+	utils.AssertEqual(t, "before", resp.Content, "Running requests should keep their instance")
+
+	reload, err = manager.Reload(ctx)
+	utils.AssertNoError(t, err, "Failed to reload")
+	utils.AssertEqual(t, 0, len(reload.Changes), "Reloading unchanged settings should change nothing")
+}
+
+func TestManager_ReloadAddsProviderFromProvidersTable(t *testing.T) {
+	// Setup
+	manager := outbound.NewManager(&config.Config{})
+	manager.SetProviderStore(&fakeProviderConfigStore{records: []*model.ProviderRecord{
+		{Name: "gemini", IsActive: true, Config: json.RawMessage(`{"api_key": "test-gemini-key-123", "default_model": "gemini-1.5-pro", "max_tokens": 8192}`)},
+	}})
+
+	// Execute
+	reload, err := manager.Reload(utils.TestContext(t))
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to reload")
+	utils.AssertEqual(t, 1, len(reload.Changes), "Gemini should be the only change")
+	utils.AssertEqual(t, outbound.ProviderAdded, reload.Changes[0].Change, "Gemini should be added")
+	utils.AssertEqual(t, model.Gemini, reload.Changes[0].Provider, "Change should name Gemini")
+	utils.AssertEqual(t, "gemini-1.5-pro", manager.DefaultModel(model.Gemini), "Default model should come from providers.config")
+	_, err = manager.GetProvider(model.Gemini)
+	utils.AssertNoError(t, err, "Gemini should be configured")
+}
+
+func TestManager_ReloadKeepsProvidersOnInvalidConfigFile(t *testing.T) {
+	// Setup
+	file := filepath.Join(t.TempDir(), "providers.yaml")
+	utils.AssertNoError(t, os.WriteFile(file, []byte("anthropic: [not, a, map]"), 0o600), "Failed to write provider config")
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.ProviderConfigFile = file
+	manager := outbound.NewManager(cfg)
+
+	// Execute
+	_, err := manager.Reload(utils.TestContext(t))
+
+	// Assert
+	utils.AssertError(t, err, "Invalid provider config should fail the reload")
+	_, err = manager.GetProvider(model.Anthropic)
+	utils.AssertNoError(t, err, "Providers should be unchanged after a failed reload")
+}
+
+func TestGeminiProvider_SharesClientAcrossConcurrentRequestsAndCloses(t *testing.T) {
+	// Setup
+	provider := outbound.NewGeminiProvider("test-gemini-key-123", "")
+	ctx, cancel := context.WithCancel(utils.TestContext(t))
+	cancel()
+
+	// Execute
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = provider.Generate(ctx, &model.GenerationRequest{Prompt: "Say hello"})
+		}(i)
+	}
+	wg.Wait()
+	closeErr := provider.Close()
+	_, afterClose := provider.Generate(ctx, &model.GenerationRequest{Prompt: "Say hello"})
+
+	// Assert
+	for _, err := range errs {
+		utils.AssertError(t, err, "A cancelled request should fail")
+	}
+	utils.AssertNoError(t, closeErr, "Close should release the shared client")
+	utils.AssertError(t, afterClose, "A cancelled request should fail")
+	utils.AssertNoError(t, provider.Close(), "Close should be safe to repeat")
+}
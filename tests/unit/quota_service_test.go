@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/service"
+	"ai-service/tests/utils"
+)
+
+// fakeUsageReader returns fixed usage for every window
+type fakeUsageReader struct {
+	users     map[string]model.Usage
+	providers map[string]model.Usage
+}
+
+func (r *fakeUsageReader) GetUserUsage(ctx context.Context, userID string, since time.Time) (*model.Usage, error) {
+	usage := r.users[userID]
+	return &usage, nil
+}
+
+func (r *fakeUsageReader) GetProviderUsage(ctx context.Context, provider string, since time.Time) (*model.Usage, error) {
+	usage := r.providers[provider]
+	return &usage, nil
+}
+
+func exceededQuota(statuses []model.QuotaStatus) *model.QuotaStatus {
+	for i := range statuses {
+		if statuses[i].Exceeded {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+func TestQuotaService_DisabledSkipsChecks(t *testing.T) {
+	// Setup
+	quotas := service.NewQuotaService(config.QuotaConfig{Enabled: false}, &fakeUsageReader{})
+
+	// Execute
+	statuses, err := quotas.Check(utils.TestContext(t), "user-1", nil, []model.AIProvider{model.OpenAI}, 100)
+
+	// Assert
+	utils.AssertNoError(t, err, "Check should not fail")
+	utils.AssertEqual(t, 0, len(statuses), "Disabled quotas should report nothing")
+}
+
+func TestQuotaService_RejectsRequestOverCallerBudget(t *testing.T) {
+	// Setup
+	cfg := config.QuotaConfig{
+		Enabled:          true,
+		Caller:           model.QuotaLimits{MonthlyTokens: 1000},
+		SoftLimitPercent: 80,
+	}
+	usage := &fakeUsageReader{users: map[string]model.Usage{"user-1": {Tokens: 900, Requests: 9}}}
+	quotas := service.NewQuotaService(cfg, usage)
+	ctx := utils.TestContext(t)
+
+	// Execute
+	within, err := quotas.Check(ctx, "user-1", nil, []model.AIProvider{model.OpenAI}, 50)
+	utils.AssertNoError(t, err, "Check should not fail")
+	over, err := quotas.Check(ctx, "user-1", nil, []model.AIProvider{model.OpenAI}, 200)
+	utils.AssertNoError(t, err, "Check should not fail")
+
+	// Assert
+	utils.AssertEqual(t, 1, len(within), "Only the monthly token limit is configured")
+	utils.AssertEqual(t, true, exceededQuota(within) == nil, "Request within budget should be allowed")
+	utils.AssertEqual(t, true, within[0].Warning, "Usage past the soft limit should warn")
+	utils.AssertEqual(t, 100, within[0].Remaining, "Remaining tokens should be reported")
+	utils.AssertEqual(t, true, exceededQuota(over) != nil, "Request over budget should be rejected")
+}
+
+func TestQuotaService_APIKeyQuotaOverridesDefault(t *testing.T) {
+	// Setup
+	cfg := config.QuotaConfig{
+		Enabled: true,
+		Caller:  model.QuotaLimits{DailyRequests: 1000},
+	}
+	usage := &fakeUsageReader{users: map[string]model.Usage{"api_key:key-1": {Requests: 10}}}
+	quotas := service.NewQuotaService(cfg, usage)
+	apiKey := &model.APIKey{ID: "key-1", Quota: &model.QuotaLimits{DailyRequests: 10}}
+
+	// Execute
+	statuses, err := quotas.Check(utils.TestContext(t), "api_key:key-1", apiKey, []model.AIProvider{model.Anthropic}, 0)
+
+	// Assert
+	utils.AssertNoError(t, err, "Check should not fail")
+	exceeded := exceededQuota(statuses)
+	utils.AssertEqual(t, true, exceeded != nil, "Key's own request limit should apply")
+	utils.AssertEqual(t, service.QuotaScopeAPIKey, exceeded.Scope, "Status should name the key scope")
+	utils.AssertEqual(t, service.QuotaWindowDaily, exceeded.Window, "Status should name the daily window")
+}
+
+func TestQuotaService_ProviderBudgetAppliesToAnonymousCallers(t *testing.T) {
+	// Setup
+	cfg := config.QuotaConfig{
+		Enabled:   true,
+		Providers: map[string]model.QuotaLimits{"openai": {DailyTokens: 500}},
+	}
+	usage := &fakeUsageReader{providers: map[string]model.Usage{"openai": {Tokens: 500}}}
+	quotas := service.NewQuotaService(cfg, usage)
+	ctx := utils.TestContext(t)
+
+	// Execute
+	openai, err := quotas.Check(ctx, "", nil, []model.AIProvider{model.OpenAI}, 0)
+	utils.AssertNoError(t, err, "Check should not fail")
+	gemini, err := quotas.Check(ctx, "", nil, []model.AIProvider{model.Gemini}, 0)
+	utils.AssertNoError(t, err, "Check should not fail")
+
+	// Assert
+	utils.AssertEqual(t, true, exceededQuota(openai) != nil, "Exhausted provider budget should reject")
+	utils.AssertEqual(t, 0, len(gemini), "Providers without a budget are unlimited")
+}
+
+func TestConfig_LoadRejectsInvalidProviderQuotas(t *testing.T) {
+	// Setup
+	t.Setenv("QUOTA_PROVIDER_LIMITS", `{"openai": {"monthly_tokens": "lots"}}`)
+
+	// Execute
+	_, err := config.Load()
+
+	// Assert
+	utils.AssertError(t, err, "Invalid provider quotas should fail to load")
+	utils.AssertEqual(t, true, strings.Contains(err.Error(), "QUOTA_PROVIDER_LIMITS"), "Error should name the variable")
+}
@@ -0,0 +1,154 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/app/middleware"
+	"ai-service/internal/model"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"ai-service/tests/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRateLimitedRouter(cfg config.RateLimitConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RateLimiter(cfg, middleware.NewMemoryRateLimitStore()))
+	router.GET("/ping", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	return router
+}
+
+func TestRateLimiter_RejectsAfterBurst(t *testing.T) {
+	// Setup
+	router := newRateLimitedRouter(config.RateLimitConfig{
+		RequestsPerMinute: 60,
+		BurstSize:         2,
+		WindowSize:        time.Minute,
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Execute
+	first := send()
+	second := send()
+	third := send()
+
+	// Assert
+	utils.AssertEqual(t, http.StatusOK, first.Code, "First request should pass")
+	utils.AssertEqual(t, "2", first.Header().Get("RateLimit-Limit"), "Limit header should report the burst size")
+	utils.AssertEqual(t, "1", first.Header().Get("RateLimit-Remaining"), "Remaining should count down")
+	utils.AssertEqual(t, http.StatusOK, second.Code, "Second request should pass")
+	utils.AssertEqual(t, http.StatusTooManyRequests, third.Code, "Third request should be limited")
+	utils.AssertEqual(t, "1", third.Header().Get("Retry-After"), "Retry-After should report when a token is available")
+
+	var body map[string]interface{}
+	utils.AssertNoError(t, json.Unmarshal(third.Body.Bytes(), &body), "Failed to decode error body")
+	utils.AssertEqual(t, exceptioncode.CodeRequestTooFast, body["code"], "Error code should be REQUEST_TOO_FAST")
+}
+
+func TestRateLimiter_KeysByValidatedAPIKey(t *testing.T) {
+	// Setup
+	store := &fakeAPIKeyStore{
+		keys: map[string]*model.APIKey{
+			authentication.HashAPIKey("ais_one"): {ID: "key-1", Name: "one", Role: authentication.RoleUser},
+			authentication.HashAPIKey("ais_two"): {ID: "key-2", Name: "two", Role: authentication.RoleUser},
+		},
+		usage: map[string]int{},
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Authenticate(false, store), middleware.RateLimiter(config.RateLimitConfig{
+		RequestsPerMinute: 60,
+		BurstSize:         1,
+		WindowSize:        time.Minute,
+	}, middleware.NewMemoryRateLimitStore()))
+	router.GET("/ping", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
+	send := func(apiKey string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-API-Key", apiKey)
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Execute & Assert
+	utils.AssertEqual(t, http.StatusOK, send("ais_one"), "First key should pass")
+	utils.AssertEqual(t, http.StatusOK, send("ais_two"), "Second key from the same IP should have its own bucket")
+	utils.AssertEqual(t, http.StatusTooManyRequests, send("ais_one"), "First key should be limited")
+}
+
+func TestRateLimiter_UnvalidatedKeysShareIPBucket(t *testing.T) {
+	// Setup
+	router := newRateLimitedRouter(config.RateLimitConfig{
+		RequestsPerMinute: 60,
+		BurstSize:         1,
+		WindowSize:        time.Minute,
+	})
+
+	send := func(apiKey string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-API-Key", apiKey)
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Execute & Assert
+	utils.AssertEqual(t, http.StatusOK, send("ais_random-one"), "First request should pass")
+	utils.AssertEqual(t, http.StatusTooManyRequests, send("ais_random-two"), "A new unvalidated key should not get a fresh bucket")
+}
+
+func TestFailedAuthLimiter_ThrottlesRejectedCredentials(t *testing.T) {
+	// Setup
+	store := &fakeAPIKeyStore{
+		keys: map[string]*model.APIKey{
+			authentication.HashAPIKey("ais_valid"): {ID: "key-1", Name: "valid", Role: authentication.RoleUser},
+		},
+		usage: map[string]int{},
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.FailedAuthLimiter(config.RateLimitConfig{
+		RequestsPerMinute: 60,
+		BurstSize:         1,
+		WindowSize:        time.Minute,
+	}, middleware.NewMemoryRateLimitStore()), middleware.Authenticate(true, store))
+	router.GET("/ping", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
+	send := func(remoteAddr, apiKey string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-API-Key", apiKey)
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Execute & Assert
+	utils.AssertEqual(t, http.StatusUnauthorized, send("10.0.0.1:1234", "ais_guess-one"), "Unknown key should be rejected")
+	utils.AssertEqual(t, http.StatusTooManyRequests, send("10.0.0.1:1234", "ais_guess-two"), "Further guesses from the IP should be throttled")
+	utils.AssertEqual(t, http.StatusOK, send("10.0.0.2:1234", "ais_valid"), "Other IPs should not be affected")
+	utils.AssertEqual(t, http.StatusOK, send("10.0.0.2:1234", "ais_valid"), "Accepted credentials should not use up the failure bucket")
+}
@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository"
+	"ai-service/internal/util/authentication"
+	"ai-service/internal/util/exceptioncode"
+	"ai-service/tests/utils"
+)
+
+func TestAPIKeyRepository_CreateAndGetActiveByHash(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewAPIKeyRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	_, prefix, hash, err := authentication.GenerateAPIKey()
+	utils.AssertNoError(t, err, "Failed to generate API key")
+
+	key := &model.APIKey{
+		Name:             "billing-service",
+		KeyPrefix:        prefix,
+		KeyHash:          hash,
+		Role:             authentication.RoleUser,
+		AllowedProviders: []string{"anthropic"},
+	}
+
+	// Execute
+	err = repo.Create(ctx, key)
+	utils.AssertNoError(t, err, "Failed to create API key")
+
+	err = repo.RecordUsage(ctx, key.ID)
+	utils.AssertNoError(t, err, "Failed to record usage")
+
+	loaded, err := repo.GetActiveByHash(ctx, hash)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to get API key by hash")
+	utils.AssertEqual(t, "billing-service", loaded.Name, "Name should match")
+	utils.AssertEqual(t, 1, len(loaded.AllowedProviders), "Allowed providers should be stored")
+	utils.AssertEqual(t, 0, len(loaded.AllowedModels), "Allowed models should be empty")
+	utils.AssertEqual(t, int64(1), loaded.UsageCount, "Usage should be counted")
+	utils.AssertEqual(t, true, loaded.LastUsedAt != nil, "Last used time should be set")
+}
+
+func TestAPIKeyRepository_RotateAndRevoke(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewAPIKeyRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	_, prefix, hash, err := authentication.GenerateAPIKey()
+	utils.AssertNoError(t, err, "Failed to generate API key")
+
+	key := &model.APIKey{Name: "reports", KeyPrefix: prefix, KeyHash: hash, Role: authentication.RoleUser}
+	err = repo.Create(ctx, key)
+	utils.AssertNoError(t, err, "Failed to create API key")
+
+	_, newPrefix, newHash, err := authentication.GenerateAPIKey()
+	utils.AssertNoError(t, err, "Failed to generate API key")
+
+	// Execute
+	err = repo.Rotate(ctx, key.ID, newHash, newPrefix)
+	utils.AssertNoError(t, err, "Failed to rotate API key")
+
+	_, oldErr := repo.GetActiveByHash(ctx, hash)
+	_, newErr := repo.GetActiveByHash(ctx, newHash)
+
+	err = repo.Revoke(ctx, key.ID)
+	utils.AssertNoError(t, err, "Failed to revoke API key")
+
+	_, revokedErr := repo.GetActiveByHash(ctx, newHash)
+	revoked, err := repo.GetByID(ctx, key.ID)
+
+	// Assert
+	utils.AssertEqual(t, true, errors.Is(oldErr, exceptioncode.ErrEmptyResult), "Old secret should stop working")
+	utils.AssertNoError(t, newErr, "New secret should work")
+	utils.AssertEqual(t, true, errors.Is(revokedErr, exceptioncode.ErrEmptyResult), "Revoked key should stop working")
+	utils.AssertNoError(t, err, "Revoked key should still be readable by ID")
+	utils.AssertEqual(t, false, revoked.IsActive, "Revoked key should be inactive")
+	utils.AssertEqual(t, true, revoked.RevokedAt != nil, "Revoked time should be set")
+}
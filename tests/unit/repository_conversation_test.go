@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"testing"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository"
+	"ai-service/tests/utils"
+)
+
+func TestConversationRepository_CreateAndGetByID(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewConversationRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	conversation := &model.Conversation{
+		Title:     "Go questions",
+		Provider:  "openai",
+		Model:     "gpt-3.5-turbo",
+		SystemMsg: "You are a Go expert",
+	}
+
+	// Execute
+	err := repo.Create(ctx, conversation)
+	utils.AssertNoError(t, err, "Failed to create conversation")
+
+	loaded, err := repo.GetByID(ctx, conversation.ID)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to get conversation by ID")
+	utils.AssertEqual(t, "Go questions", loaded.Title, "Title should match")
+	utils.AssertEqual(t, "You are a Go expert", loaded.SystemMsg, "System message should match")
+	utils.AssertEqual(t, 0, loaded.MessageCount, "New conversation should have no messages")
+}
+
+func TestConversationRepository_AddMessages(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewConversationRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	conversation := &model.Conversation{Provider: "openai", Model: "gpt-3.5-turbo"}
+	utils.AssertNoError(t, repo.Create(ctx, conversation), "Failed to create conversation")
+
+	// Execute
+	err := repo.AddMessages(ctx, conversation.ID,
+		&model.ConversationMessage{Role: model.RoleUser, Content: "Hi"},
+		&model.ConversationMessage{Role: model.RoleAssistant, Content: "Hello!", TokensUsed: 12},
+	)
+	utils.AssertNoError(t, err, "Failed to add messages")
+
+	messages, err := repo.GetMessages(ctx, conversation.ID)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to get messages")
+	utils.AssertEqual(t, 2, len(messages), "Should return 2 messages")
+	utils.AssertEqual(t, model.RoleUser, messages[0].Role, "First message should be the user turn")
+	utils.AssertEqual(t, model.RoleAssistant, messages[1].Role, "Second message should be the assistant turn")
+	utils.AssertEqual(t, 12, messages[1].TokensUsed, "Tokens should match")
+}
+
+func TestConversationRepository_Delete(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewConversationRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	conversation := &model.Conversation{Provider: "gemini", Model: "gemini-1.5-flash"}
+	utils.AssertNoError(t, repo.Create(ctx, conversation), "Failed to create conversation")
+	utils.AssertNoError(t, repo.AddMessages(ctx, conversation.ID,
+		&model.ConversationMessage{Role: model.RoleUser, Content: "Hi"},
+	), "Failed to add messages")
+
+	// Execute
+	err := repo.Delete(ctx, conversation.ID)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to delete conversation")
+
+	_, err = repo.GetByID(ctx, conversation.ID)
+	utils.AssertError(t, err, "Conversation should not exist after deletion")
+
+	messages, err := repo.GetMessages(ctx, conversation.ID)
+	utils.AssertNoError(t, err, "Failed to get messages")
+	utils.AssertEqual(t, 0, len(messages), "Messages should be deleted with the conversation")
+}
@@ -6,6 +6,7 @@ import (
 
 	"ai-service/internal/model"
 	"ai-service/internal/repository"
+	"ai-service/internal/util/exceptioncode"
 	"ai-service/tests/utils"
 )
 
@@ -41,6 +42,42 @@ func TestGenerationRepository_Create(t *testing.T) {
 	utils.AssertNotNil(t, generation.UpdatedAt, "UpdatedAt should not be nil")
 }
 
+func TestGenerationRepository_CreateWithTokenUsage(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewGenerationRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	generation := &model.GenerationHistory{
+		Provider:   "gemini",
+		Model:      "gemini-1.5-flash",
+		Prompt:     "Test prompt",
+		Response:   "Test response",
+		TokensUsed: 30,
+		Duration:   1000,
+		Status:     "success",
+		TokenUsage: model.NewTokenUsage(20, 10, true),
+	}
+
+	// Execute
+	err := repo.Create(ctx, generation)
+	utils.AssertNoError(t, err, "Failed to create generation")
+
+	saved, err := repo.GetByID(ctx, generation.ID)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to get generation by ID")
+	utils.AssertEqual(t, 20, saved.PromptTokens, "Prompt tokens should match")
+	utils.AssertEqual(t, 10, saved.CompletionTokens, "Completion tokens should match")
+	utils.AssertEqual(t, 30, saved.TotalTokens, "Total tokens should match")
+	utils.AssertEqual(t, true, saved.Estimated, "Estimated flag should match")
+}
+
 func TestGenerationRepository_GetByID(t *testing.T) {
 	// Setup
 	testDB := utils.NewTestDB(t)
@@ -66,6 +103,24 @@ func TestGenerationRepository_GetByID(t *testing.T) {
 	utils.AssertEqual(t, "gpt-3.5-turbo", generation.Model, "Model should match")
 }
 
+func TestGenerationRepository_GetByMalformedID(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewGenerationRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	// Execute
+	_, err := repo.GetByID(ctx, "not-a-uuid")
+
+	// Assert
+	utils.AssertEqual(t, exceptioncode.ErrEmptyResult, err, "Malformed ID should not be found")
+}
+
 func TestGenerationRepository_GetByProvider(t *testing.T) {
 	// Setup
 	testDB := utils.NewTestDB(t)
@@ -162,6 +217,66 @@ func TestGenerationRepository_GetStats(t *testing.T) {
 	utils.AssertEqual(t, 2, openaiStats.TotalGenerations, "OpenAI should have 2 generations")
 }
 
+func TestGenerationRepository_GetProviderStatsWithoutGenerations(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewGenerationRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	// Execute
+	stats, err := repo.GetProviderStats(ctx, "openai", time.Now().AddDate(0, 0, -1), time.Now())
+
+	// Assert
+	utils.AssertNoError(t, err, "Stats of an idle provider should not fail")
+	utils.AssertEqual(t, 0, stats.TotalGenerations, "Idle provider should have no generations")
+	utils.AssertEqual(t, 0, stats.TotalTokens, "Idle provider should have no tokens")
+}
+
+func TestGenerationRepository_GetSpend(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewGenerationRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	costs := []float64{0.25, 0.5}
+	for i, cost := range costs {
+		generation := &model.GenerationHistory{
+			Provider:   "anthropic",
+			Model:      "claude-3-haiku",
+			Prompt:     "Test prompt",
+			Response:   "Test response",
+			TokensUsed: 100 * (i + 1),
+			Status:     "success",
+			UserID:     "user-1",
+			CostUSD:    &cost,
+		}
+		utils.AssertNoError(t, repo.Create(ctx, generation), "Failed to create generation")
+	}
+
+	// Execute
+	startDate := time.Now().AddDate(0, 0, -1)
+	endDate := time.Now().AddDate(0, 0, 1)
+	spend, err := repo.GetSpend(ctx, startDate, endDate)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to get spend")
+	utils.AssertEqual(t, 0.75, spend.TotalCostUSD, "Total cost should sum every generation")
+	utils.AssertEqual(t, 1, len(spend.ByModel), "Should return spend for 1 model")
+	utils.AssertEqual(t, 300, spend.ByModel[0].Tokens, "Model tokens should be summed")
+	utils.AssertEqual(t, "user-1", spend.ByUser[0].Key, "Spend should be grouped by user")
+	utils.AssertEqual(t, 1, len(spend.ByDay), "Should return spend for 1 day")
+}
+
 func TestGenerationRepository_UpdateStatus(t *testing.T) {
 	// Setup
 	testDB := utils.NewTestDB(t)
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"ai-service/internal/model"
+	"ai-service/internal/repository"
+	"ai-service/internal/util/exceptioncode"
+	"ai-service/tests/utils"
+)
+
+func TestAIProviderRepository_List(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewAIProviderRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	utils.CreateTestProvider(t, testDB.DB, "openai", true)
+	utils.CreateTestProvider(t, testDB.DB, "anthropic", false)
+
+	// Execute
+	providers, err := repo.List(ctx)
+
+	// Assert
+	utils.AssertNoError(t, err, "Failed to list providers")
+	utils.AssertEqual(t, 2, len(providers), "Should return every provider")
+	utils.AssertEqual(t, "anthropic", providers[0].Name, "Providers should be ordered by name")
+	utils.AssertEqual(t, false, providers[0].IsActive, "Active flag should match")
+	utils.AssertEqual(t, true, len(providers[1].Config) > 0, "Config should be loaded")
+}
+
+func TestAIProviderRepository_SetActiveAndUpdateConfig(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewAIProviderRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	utils.CreateTestProvider(t, testDB.DB, "openai", true)
+
+	// Execute
+	disabled, err := repo.SetActive(ctx, "openai", false)
+	utils.AssertNoError(t, err, "Failed to disable provider")
+	added, err := repo.SetActive(ctx, "gemini", true)
+	utils.AssertNoError(t, err, "Failed to enable a provider without a row")
+	updated, err := repo.UpdateConfig(ctx, "openai", json.RawMessage(`{"timeout": "30s", "max_tokens": null}`))
+	utils.AssertNoError(t, err, "Failed to update provider config")
+
+	// Assert
+	utils.AssertEqual(t, false, disabled.IsActive, "Provider should be disabled")
+	utils.AssertEqual(t, true, added.IsActive, "Missing row should be created active")
+
+	var config map[string]interface{}
+	utils.AssertNoError(t, json.Unmarshal(updated.Config, &config), "Config should be JSON")
+	utils.AssertEqual(t, "30s", config["timeout"], "New settings should be merged")
+	utils.AssertEqual(t, "test-model", config["default_model"], "Other settings should be kept")
+	_, hasMaxTokens := config["max_tokens"]
+	utils.AssertEqual(t, false, hasMaxTokens, "Null should remove a setting")
+
+	stored, err := repo.GetByName(ctx, "openai")
+	utils.AssertNoError(t, err, "Failed to get provider")
+	utils.AssertEqual(t, false, stored.IsActive, "Config updates should keep the active flag")
+}
+
+func TestAIProviderRepository_SaveAndDeleteKey(t *testing.T) {
+	// Setup
+	testDB := utils.NewTestDB(t)
+	defer testDB.Close()
+
+	testDB.SetupTestDatabase(t)
+	defer testDB.CleanupTestDatabase(t)
+
+	repo := repository.NewAIProviderRepository(testDB.DB)
+	ctx := utils.TestContext(t)
+
+	key := &model.ProviderKey{Provider: "openai", EncryptedAPIKey: []byte{1, 2, 3}, EncryptedDataKey: []byte{4, 5, 6}}
+
+	// Execute
+	err := repo.SaveKey(ctx, key, &model.ProviderKeyAudit{Provider: "openai", Action: model.ProviderKeySet, Actor: "admin"})
+	utils.AssertNoError(t, err, "Failed to save key")
+	keys, err := repo.ListKeys(ctx)
+	utils.AssertNoError(t, err, "Failed to list keys")
+	record, err := repo.GetByName(ctx, "openai")
+	utils.AssertNoError(t, err, "Failed to get provider")
+	err = repo.DeleteKey(ctx, "openai", &model.ProviderKeyAudit{Provider: "openai", Action: model.ProviderKeyDeleted, Actor: "admin"})
+	utils.AssertNoError(t, err, "Failed to delete key")
+	audit, err := repo.ListKeyAudit(ctx, "openai", 10)
+	utils.AssertNoError(t, err, "Failed to list audit")
+
+	// Assert
+	utils.AssertEqual(t, 1, len(keys), "Saved key should be listed")
+	utils.AssertEqual(t, string([]byte{4, 5, 6}), string(keys[0].EncryptedDataKey), "Wrapped data key should be stored")
+	utils.AssertEqual(t, true, record.HasAPIKey, "Provider should report a stored key")
+	utils.AssertEqual(t, true, record.IsActive, "Row created for a key should be active")
+	utils.AssertEqual(t, 2, len(audit), "Both changes should be audited")
+	utils.AssertEqual(t, model.ProviderKeyDeleted, audit[0].Action, "Audit should be newest first")
+
+	_, err = repo.GetKey(ctx, "openai")
+	utils.AssertEqual(t, true, errors.Is(err, exceptioncode.ErrEmptyResult), "Deleted key should not be found")
+}
@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/tests/utils"
+)
+
+func TestRetryTransport_RetriesRateLimitedRequest(t *testing.T) {
+	// Setup
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"type": "error", "error": {"type": "rate_limit_error", "message": "slow down"}}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Hello"}], "usage": {"input_tokens": 3, "output_tokens": 1}}`))
+	}))
+	defer server.Close()
+
+	provider := outbound.NewAnthropicProvider("test-anthropic-key", server.URL, "")
+	provider.SetRetryPolicy(outbound.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Second})
+	ctx := utils.TestContext(t)
+
+	// Execute
+	resp, err := provider.Generate(ctx, &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertNoError(t, err, "Generate should succeed after a retry")
+	utils.AssertEqual(t, 2, requests, "Request should be sent twice")
+	utils.AssertEqual(t, 1, resp.Retries, "Response should report one retry")
+	utils.AssertEqual(t, "Hello", resp.Content, "Content should match")
+}
+
+func TestRetryTransport_GivesUpAfterMaxAttempts(t *testing.T) {
+	// Setup
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider := outbound.NewAnthropicProvider("test-anthropic-key", server.URL, "")
+	provider.SetRetryPolicy(outbound.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Second})
+	ctx := utils.TestContext(t)
+
+	// Execute
+	_, err := provider.Generate(ctx, &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertError(t, err, "Generate should fail once attempts are exhausted")
+	utils.AssertEqual(t, 3, requests, "Request should be sent MaxAttempts times")
+}
+
+func TestRetryTransport_DoesNotRetryClientErrors(t *testing.T) {
+	// Setup
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	provider := outbound.NewAnthropicProvider("test-anthropic-key", server.URL, "")
+	provider.SetRetryPolicy(outbound.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Second})
+	ctx := utils.TestContext(t)
+
+	// Execute
+	_, err := provider.Generate(ctx, &model.GenerationRequest{
+		Provider: model.Anthropic,
+		Prompt:   "Say hello",
+	})
+
+	// Assert
+	utils.AssertError(t, err, "Generate should fail on 401")
+	utils.AssertEqual(t, 1, requests, "Client errors should not be retried")
+}
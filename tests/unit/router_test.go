@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/routes"
+	"ai-service/internal/util/authentication"
+	"ai-service/tests/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRouter_AdminRoutesRequireAuthWhenAuthDisabled(t *testing.T) {
+	// Setup
+	cfg := &config.Config{}
+	cfg.Security.AuthEnabled = false
+	router := routes.NewRouters(cfg, nil, nil, nil, nil, nil, nil, nil)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/providers/openai/key", strings.NewReader(`{"api_key": "sk-test"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	router.ServeHTTP(recorder, req)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusUnauthorized, recorder.Code, "Anonymous admin request should be rejected")
+}
+
+func TestRouter_AdminRoutesRejectTokenSignedWithPlaceholderSecret(t *testing.T) {
+	// Setup
+	authentication.Init("")
+	defer authentication.Init("test-secret")
+	cfg := &config.Config{}
+	cfg.Security.AuthEnabled = false
+	router := routes.NewRouters(cfg, nil, nil, nil, nil, nil, nil, nil)
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, authentication.JWTClaim{
+		UserID: "attacker",
+		Role:   authentication.RoleAdmin,
+	}).SignedString([]byte("your-secret-key"))
+	utils.AssertNoError(t, err, "Failed to sign token")
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/providers/openai/key", strings.NewReader(`{"api_key": "sk-test"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+forged)
+
+	// Execute
+	router.ServeHTTP(recorder, req)
+
+	// Assert
+	utils.AssertEqual(t, http.StatusUnauthorized, recorder.Code, "Forged admin token should be rejected")
+}
+
+func TestRouter_RateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	// Setup
+	cfg := &config.Config{}
+	cfg.RateLimit.RequestsPerMinute = 60
+	cfg.RateLimit.BurstSize = 1
+	router := routes.NewRouters(cfg, nil, nil, nil, nil, nil, nil, nil)
+
+	send := func(forwardedFor string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/token", strings.NewReader(`{"client_id": "admin", "client_secret": "guess"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Execute & Assert
+	utils.AssertEqual(t, http.StatusUnauthorized, send("203.0.113.1"), "Unknown client should be rejected")
+	utils.AssertEqual(t, http.StatusTooManyRequests, send("203.0.113.2"), "A spoofed X-Forwarded-For should not get a fresh bucket")
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-service/cmd/config"
+	"ai-service/internal/model"
+	"ai-service/internal/outbound"
+	"ai-service/internal/util/tokenizer"
+	"ai-service/tests/utils"
+)
+
+func TestTokenizer_Count(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		text     string
+		expected int
+	}{
+		{"empty", "gpt-4", "", 0},
+		{"short words", "gpt-4", "Say hello world", 3},
+		{"punctuation", "gpt-4", "Hello, world!", 4},
+		{"long word is split", "gpt-4", "internationalization", 5},
+		{"digits in groups of three", "gpt-4", "1234567", 3},
+		{"claude splits words finer", "claude-3-haiku", "internationalization", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utils.AssertEqual(t, tt.expected, tokenizer.Count(tt.model, tt.text), "Token count should match")
+		})
+	}
+}
+
+func TestTokenizer_TruncateFitsBudget(t *testing.T) {
+	// Setup
+	text := strings.Repeat("hello world. ", 50)
+
+	// Execute
+	truncated := tokenizer.Truncate("gpt-4", text, 20)
+
+	// Assert
+	utils.AssertEqual(t, true, tokenizer.Count("gpt-4", truncated) <= 20, "Truncated text should fit the budget")
+	utils.AssertEqual(t, true, strings.HasPrefix(text, truncated), "Truncation should keep the start of the text")
+	utils.AssertEqual(t, text[:10], tokenizer.Truncate("gpt-4", text[:10], 20), "Text within budget should be unchanged")
+}
+
+func TestManager_PreflightRejectsOversizedPrompt(t *testing.T) {
+	// Setup
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Hi"}], "usage": {"input_tokens": 1, "output_tokens": 1}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = server.URL
+	cfg.Preflight.Mode = config.PreflightReject
+
+	manager := outbound.NewManager(cfg)
+	manager.SetRegistry(smallWindowRegistry(t))
+	ctx := utils.TestContext(t)
+
+	// Execute
+	_, err := manager.Generate(ctx, &model.GenerationRequest{
+		Provider:  model.Anthropic,
+		Model:     "claude-3-haiku",
+		Prompt:    strings.Repeat("hello, ", 600),
+		MaxTokens: 100,
+	})
+
+	// Assert
+	var windowErr *outbound.ContextWindowError
+	utils.AssertEqual(t, true, errors.As(err, &windowErr), "Oversized prompt should be rejected by the preflight")
+	utils.AssertEqual(t, 1000, windowErr.ContextWindow, "Error should report the model's window")
+	utils.AssertEqual(t, 0, requests, "Provider should not be called")
+}
+
+func TestManager_PreflightTruncatesOversizedRequest(t *testing.T) {
+	// Setup
+	var received struct {
+		Messages []model.Message `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Hi"}], "usage": {"input_tokens": 1, "output_tokens": 1}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.AIProviders.Anthropic.APIKey = "test-anthropic-key"
+	cfg.AIProviders.Anthropic.BaseURL = server.URL
+	cfg.Preflight.Mode = config.PreflightTruncate
+
+	manager := outbound.NewManager(cfg)
+	manager.SetRegistry(smallWindowRegistry(t))
+	ctx := utils.TestContext(t)
+
+	history := strings.Repeat("hello, ", 300)
+	req := &model.GenerationRequest{
+		Provider:  model.Anthropic,
+		Model:     "claude-3-haiku",
+		Prompt:    "Summarize our chat",
+		MaxTokens: 100,
+		Messages: []model.Message{
+			{Role: model.RoleUser, Content: history},
+			{Role: model.RoleAssistant, Content: history},
+			{Role: model.RoleUser, Content: "Short question"},
+			{Role: model.RoleAssistant, Content: "Short answer"},
+		},
+	}
+
+	// Execute
+	resp, err := manager.Generate(ctx, req)
+
+	// Assert
+	utils.AssertNoError(t, err, "Oversized request should be truncated, not rejected")
+	utils.AssertEqual(t, true, resp.Truncated, "Response should be flagged as truncated")
+	utils.AssertEqual(t, 3, len(received.Messages), "Oldest turn pair should be dropped")
+	utils.AssertEqual(t, "Short question", received.Messages[0].Content, "Recent turns should be kept")
+	utils.AssertEqual(t, 4, len(req.Messages), "Caller's request should not be modified")
+}
+
+// smallWindowRegistry returns a registry whose claude-3-haiku has a 1000
+// token context window, so oversized requests stay small
+func smallWindowRegistry(t *testing.T) *outbound.Registry {
+	registry, err := outbound.NewRegistry([]model.ModelInfo{
+		{ID: "claude-3-haiku", Provider: model.Anthropic, ContextWindow: 1000, MaxOutputTokens: 500},
+	})
+	utils.AssertNoError(t, err, "Failed to build registry")
+	return registry
+}
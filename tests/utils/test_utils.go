@@ -9,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"ai-service/internal/app/database"
 	"ai-service/internal/util/exception"
+	"ai-service/scripts/migrations"
 
 	_ "github.com/lib/pq"
 )
@@ -70,83 +72,26 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
-// SetupTestDatabase sets up test database with migrations
+// SetupTestDatabase applies the migrations to the test database and removes
+// the rows they seed, so each test starts from empty tables
 func (tdb *TestDB) SetupTestDatabase(t *testing.T) {
-	// PostgreSQL-compatible schema
-	schema := `
-	-- Enable UUID extension
-	CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-	
-	-- Create generations table
-	CREATE TABLE IF NOT EXISTS generations (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		provider VARCHAR(50) NOT NULL,
-		model VARCHAR(100) NOT NULL,
-		prompt TEXT NOT NULL,
-		response TEXT NOT NULL,
-		tokens_used INTEGER NOT NULL DEFAULT 0,
-		duration_ms INTEGER NOT NULL DEFAULT 0,
-		status VARCHAR(20) NOT NULL DEFAULT 'success',
-		error_message TEXT,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-
-	-- Create providers table
-	CREATE TABLE IF NOT EXISTS providers (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		name VARCHAR(50) UNIQUE NOT NULL,
-		api_key_hash VARCHAR(255),
-		is_active BOOLEAN DEFAULT true,
-		config JSONB,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-
-	-- Create stats table for aggregated metrics
-	CREATE TABLE IF NOT EXISTS stats (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		provider VARCHAR(50) NOT NULL,
-		date DATE NOT NULL,
-		total_generations INTEGER DEFAULT 0,
-		total_tokens INTEGER DEFAULT 0,
-		avg_duration_ms INTEGER DEFAULT 0,
-		error_count INTEGER DEFAULT 0,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		UNIQUE(provider, date)
-	);
-
-	-- Create api_keys table for secure key management
-	CREATE TABLE IF NOT EXISTS api_keys (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		provider VARCHAR(50) NOT NULL,
-		key_hash VARCHAR(255) NOT NULL,
-		is_active BOOLEAN DEFAULT true,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-
-	-- Create indexes for better performance
-	CREATE INDEX IF NOT EXISTS idx_generations_provider ON generations(provider);
-	CREATE INDEX IF NOT EXISTS idx_generations_created_at ON generations(created_at);
-	CREATE INDEX IF NOT EXISTS idx_generations_status ON generations(status);
-	CREATE INDEX IF NOT EXISTS idx_stats_provider_date ON stats(provider, date);
-	CREATE INDEX IF NOT EXISTS idx_api_keys_provider ON api_keys(provider);
-	`
+	migrator, err := database.NewMigrator(tdb.DB, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
 
 	ctx := context.Background()
-	_, err := tdb.Exec(schema)
-	if err != nil {
-		t.Fatalf("Failed to setup test database schema: %v", exception.TranslateDatabaseError(ctx, err))
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", exception.TranslateDatabaseError(ctx, err))
 	}
+	tdb.CleanupTestDatabase(t)
 
 	log.Println("Test database setup completed")
 }
 
 // CleanupTestDatabase cleans up test data
 func (tdb *TestDB) CleanupTestDatabase(t *testing.T) {
-	tables := []string{"generations", "providers", "stats", "api_keys"}
+	tables := []string{"messages", "conversations", "generations", "providers", "stats", "api_keys", "model_pricing", "provider_key_audit"}
 
 	for _, table := range tables {
 		_, err := tdb.Exec(fmt.Sprintf("DELETE FROM %s", table))